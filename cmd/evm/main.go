@@ -22,8 +22,10 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"math/big"
 	"os"
@@ -33,10 +35,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 var (
@@ -47,6 +50,19 @@ var (
 	value    = flag.String("value", "0", "tx value")
 	dump     = flag.Bool("dump", false, "dump state after run")
 	data     = flag.String("data", "", "data")
+
+	prestate = flag.String("prestate", "", "genesis style JSON file with the accounts to run against")
+	sender   = flag.String("sender", "", "address of the caller (default: \"sender\")")
+	receiver = flag.String("receiver", "", "address of the called contract (default: \"receiver\")")
+	create   = flag.Bool("create", false, "run the code as init code of a contract creation")
+	jsonOut  = flag.Bool("json", false, "print the result as JSON")
+	profile  = flag.Bool("profile", false, "profile gas usage per opcode and instruction")
+
+	number     = flag.String("number", "0", "block number")
+	timestamp  = flag.Int64("timestamp", 0, "block timestamp (default: now)")
+	coinbase   = flag.String("coinbase", "", "block coinbase (default: the sender)")
+	difficulty = flag.String("difficulty", "1", "block difficulty")
+	gaslimit   = flag.String("gaslimit", "1000000000", "block gas limit")
 )
 
 func perr(v ...interface{}) {
@@ -54,6 +70,62 @@ func perr(v ...interface{}) {
 	//os.Exit(1)
 }
 
+// account is the prestate of a single account, in the genesis format
+// extended with nonce and storage.
+type account struct {
+	Balance string
+	Code    string
+	Nonce   uint64
+	Storage map[string]string
+}
+
+func loadPrestate(statedb *state.StateDB, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var accounts map[string]account
+	if err := json.Unmarshal(content, &accounts); err != nil {
+		return fmt.Errorf("invalid prestate %s: %v", path, err)
+	}
+	for addr, account := range accounts {
+		obj := statedb.CreateAccount(common.HexToAddress(addr))
+		obj.SetBalance(common.Big(account.Balance))
+		obj.SetCode(common.FromHex(account.Code))
+		obj.SetNonce(account.Nonce)
+		for key, value := range account.Storage {
+			obj.SetState(common.HexToHash(key), common.NewValue(common.FromHex(value)))
+		}
+		statedb.UpdateStateObject(obj)
+	}
+	return nil
+}
+
+// address parses an address flag, falling back to the padded string def.
+func address(flag, def string) common.Address {
+	if flag == "" {
+		return common.StringToAddress(def)
+	}
+	return common.HexToAddress(flag)
+}
+
+type logJSON struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+type resultJSON struct {
+	Output  string           `json:"output"`
+	GasUsed string           `json:"gasUsed"`
+	Address string           `json:"address,omitempty"` // created contract
+	Logs    []logJSON        `json:"logs"`
+	Error   string           `json:"error,omitempty"`
+	Post    *json.RawMessage `json:"post,omitempty"`
+
+	Profile *core.ProfileReport `json:"profile,omitempty"`
+}
+
 func main() {
 	flag.Parse()
 
@@ -61,22 +133,93 @@ func main() {
 
 	db, _ := ethdb.NewMemDatabase()
 	statedb := state.New(common.Hash{}, db)
-	sender := statedb.CreateAccount(common.StringToAddress("sender"))
-	receiver := statedb.CreateAccount(common.StringToAddress("receiver"))
-	receiver.SetCode(common.Hex2Bytes(*code))
+	if *prestate != "" {
+		if err := loadPrestate(statedb, *prestate); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	from := statedb.GetOrNewStateObject(address(*sender, "sender"))
+	to := address(*receiver, "receiver")
+	if *code != "" && !*create {
+		statedb.GetOrNewStateObject(to).SetCode(common.Hex2Bytes(*code))
+	}
 
-	vmenv := NewEnv(statedb, common.StringToAddress("evmuser"), common.Big(*value))
+	vmenv := NewEnv(statedb, from.Address(), common.Big(*value))
+	vmenv.number = common.Big(*number)
+	vmenv.difficulty = common.Big(*difficulty)
+	vmenv.gasLimit = common.Big(*gaslimit)
+	if *timestamp != 0 {
+		vmenv.time = *timestamp
+	}
+	if *coinbase != "" {
+		vmenv.coinbase = common.HexToAddress(*coinbase)
+	}
+	if *profile {
+		vmenv.profiler = vm.NewProfiler()
+	}
 
 	tstart := time.Now()
 
-	ret, e := vmenv.Call(sender, receiver.Address(), common.Hex2Bytes(*data), common.Big(*gas), common.Big(*price), common.Big(*value))
+	var (
+		ret     []byte
+		e       error
+		created vm.ContextRef
+		gasLeft = common.Big(*gas)
+	)
+	if *create {
+		ret, e, created = vmenv.Create(from, common.Hex2Bytes(*code), gasLeft, common.Big(*price), common.Big(*value))
+		if e == nil {
+			// Deposit the returned code like a creation transaction does
+			dataGas := new(big.Int).Mul(big.NewInt(int64(len(ret))), params.CreateDataGas)
+			if vm.UseGas(gasLeft, dataGas) {
+				created.SetCode(ret)
+			}
+		}
+	} else {
+		ret, e = vmenv.Call(from, to, common.Hex2Bytes(*data), gasLeft, common.Big(*price), common.Big(*value))
+	}
+	gasUsed := new(big.Int).Sub(common.Big(*gas), gasLeft)
 
 	logger.Flush()
+	if *jsonOut {
+		result := resultJSON{
+			Output:  common.ToHex(ret),
+			GasUsed: gasUsed.String(),
+			Logs:    []logJSON{},
+		}
+		if created != nil && e == nil {
+			result.Address = common.ToHex(created.Address().Bytes())
+		}
+		if e != nil {
+			result.Error = e.Error()
+		}
+		for _, log := range statedb.Logs() {
+			l := logJSON{Address: common.ToHex(log.Address.Bytes()), Data: common.ToHex(log.Data), Topics: []string{}}
+			for _, topic := range log.Topics {
+				l.Topics = append(l.Topics, common.ToHex(topic.Bytes()))
+			}
+			result.Logs = append(result.Logs, l)
+		}
+		if *dump {
+			statedb.Update()
+			post := json.RawMessage(statedb.Dump())
+			result.Post = &post
+		}
+		if vmenv.profiler != nil {
+			result.Profile = core.NewProfileReport(vmenv.profiler)
+		}
+		out, _ := json.MarshalIndent(result, "", "    ")
+		fmt.Println(string(out))
+		return
+	}
+
 	if e != nil {
 		perr(e)
 	}
 
 	if *dump {
+		statedb.Update()
 		fmt.Println(string(statedb.Dump()))
 	}
 
@@ -91,19 +234,31 @@ heap objs:  %d
 num gc:     %d
 `, mem.Alloc, mem.TotalAlloc, mem.Mallocs, mem.HeapAlloc, mem.HeapObjects, mem.NumGC)
 
+	if vmenv.profiler != nil {
+		core.NewProfileReport(vmenv.profiler).WriteText(os.Stdout)
+	}
+	if created != nil && e == nil {
+		fmt.Printf("created %x\n", created.Address())
+	}
+	fmt.Printf("gas used %v\n", gasUsed)
 	fmt.Printf("%x\n", ret)
 }
 
 type VMEnv struct {
 	state *state.StateDB
-	block *types.Block
 
 	transactor *common.Address
 	value      *big.Int
 
-	depth int
-	Gas   *big.Int
-	time  int64
+	number     *big.Int
+	coinbase   common.Address
+	difficulty *big.Int
+	gasLimit   *big.Int
+
+	depth    int
+	Gas      *big.Int
+	time     int64
+	profiler *vm.Profiler
 }
 
 func NewEnv(state *state.StateDB, transactor common.Address, value *big.Int) *VMEnv {
@@ -111,27 +266,34 @@ func NewEnv(state *state.StateDB, transactor common.Address, value *big.Int) *VM
 		state:      state,
 		transactor: &transactor,
 		value:      value,
+		number:     common.Big0,
+		coinbase:   transactor,
+		difficulty: common.Big1,
+		gasLimit:   big.NewInt(1000000000),
 		time:       time.Now().Unix(),
 	}
 }
 
 func (self *VMEnv) State() *state.StateDB    { return self.state }
 func (self *VMEnv) Origin() common.Address   { return *self.transactor }
-func (self *VMEnv) BlockNumber() *big.Int    { return common.Big0 }
-func (self *VMEnv) Coinbase() common.Address { return *self.transactor }
+func (self *VMEnv) BlockNumber() *big.Int    { return self.number }
+func (self *VMEnv) Coinbase() common.Address { return self.coinbase }
 func (self *VMEnv) Time() int64              { return self.time }
-func (self *VMEnv) Difficulty() *big.Int     { return common.Big1 }
+func (self *VMEnv) Difficulty() *big.Int     { return self.difficulty }
 func (self *VMEnv) BlockHash() []byte        { return make([]byte, 32) }
 func (self *VMEnv) Value() *big.Int          { return self.value }
-func (self *VMEnv) GasLimit() *big.Int       { return big.NewInt(1000000000) }
+func (self *VMEnv) GasLimit() *big.Int       { return self.gasLimit }
 func (self *VMEnv) VmType() vm.Type          { return vm.StdVmTy }
-func (self *VMEnv) Depth() int               { return 0 }
+func (self *VMEnv) Profiler() *vm.Profiler   { return self.profiler }
+func (self *VMEnv) Depth() int               { return self.depth }
 func (self *VMEnv) SetDepth(i int)           { self.depth = i }
+func (self *VMEnv) Precompiled() *vm.PrecompiledRegistry {
+	return nil
+}
+
+// GetHash returns a fake hash of block n, as the state tests do.
 func (self *VMEnv) GetHash(n uint64) common.Hash {
-	if self.block.Number().Cmp(big.NewInt(int64(n))) == 0 {
-		return self.block.Hash()
-	}
-	return common.Hash{}
+	return common.BytesToHash(crypto.Sha3([]byte(big.NewInt(int64(n)).String())))
 }
 func (self *VMEnv) AddLog(log *state.Log) {
 	self.state.AddLog(log)
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newEnv(code string) (*VMEnv, *state.StateObject, common.Address) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb := state.New(common.Hash{}, db)
+
+	from := statedb.GetOrNewStateObject(address("", "sender"))
+	to := address("", "receiver")
+	statedb.GetOrNewStateObject(to).SetCode(common.Hex2Bytes(code))
+
+	return NewEnv(statedb, from.Address(), common.Big0), from, to
+}
+
+func TestLoadPrestate(t *testing.T) {
+	f, err := ioutil.TempFile("", "evm-prestate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{"0x0000000000000000000000000000000000000001": {
+		"balance": "100", "code": "0x6001", "nonce": 3,
+		"storage": {"0x01": "0x02"}
+	}}`)
+	f.Close()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb := state.New(common.Hash{}, db)
+	if err := loadPrestate(statedb, f.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := common.HexToAddress("0x01")
+	if balance := statedb.GetBalance(addr); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("balance mismatch: have %v, want 100", balance)
+	}
+	if code := statedb.GetCode(addr); !bytes.Equal(code, []byte{0x60, 0x01}) {
+		t.Errorf("code mismatch: have %x, want 6001", code)
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 3 {
+		t.Errorf("nonce mismatch: have %d, want 3", nonce)
+	}
+	if value := statedb.GetState(addr, common.HexToHash("0x01")); !bytes.Equal(common.NewValueFromBytes(value).Bytes(), []byte{0x02}) {
+		t.Errorf("storage mismatch: have %x, want 02", value)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	if addr := address("", "sender"); addr != common.StringToAddress("sender") {
+		t.Errorf("default address mismatch: have %x", addr)
+	}
+	if addr := address("0x01", "sender"); addr != common.HexToAddress("0x01") {
+		t.Errorf("address mismatch: have %x", addr)
+	}
+}
+
+func TestBlockHash(t *testing.T) {
+	// PUSH1 4 BLOCKHASH PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	const code = "60044060005260206000f3"
+
+	env, from, to := newEnv(code)
+	env.number = big.NewInt(5)
+	ret, err := env.Call(from, to, nil, big.NewInt(100000), common.Big0, common.Big0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := crypto.Sha3([]byte("4")); !bytes.Equal(ret, want) {
+		t.Errorf("hash mismatch: have %x, want %x", ret, want)
+	}
+
+	// Blocks at or above the current number have no hash
+	env, from, to = newEnv(code)
+	env.number = big.NewInt(4)
+	ret, err = env.Call(from, to, nil, big.NewInt(100000), common.Big0, common.Big0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ret, make([]byte, 32)) {
+		t.Errorf("expected empty hash, got %x", ret)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	env, from, to := newEnv("00")
+	if _, err := env.Call(from, to, nil, big.NewInt(100000), common.Big0, common.Big0); err != nil {
+		t.Fatal(err)
+	}
+	if env.Depth() != 0 {
+		t.Errorf("depth not restored after call: have %d, want 0", env.Depth())
+	}
+
+	env.SetDepth(int(params.CallCreateDepth.Int64()) + 1)
+	if _, err := env.Call(from, to, nil, big.NewInt(100000), common.Big0, common.Big0); !vm.IsDepthErr(err) {
+		t.Errorf("expected depth error, got %v", err)
+	}
+}
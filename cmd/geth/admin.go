@@ -82,6 +82,9 @@ func (js *jsre) adminBindings() {
 	debug.Set("getBlockRlp", js.getBlockRlp)
 	debug.Set("setHead", js.setHead)
 	debug.Set("processBlock", js.debugBlock)
+	debug.Set("chainForks", js.chainForks)
+	debug.Set("blocksAt", js.blocksAt)
+	debug.Set("commonAncestor", js.commonAncestor)
 	// undocumented temporary
 	debug.Set("waitForBlocks", js.waitForBlocks)
 }
@@ -366,21 +369,10 @@ func (js *jsre) unlock(call otto.FunctionCall) otto.Value {
 		fmt.Println(err)
 		return otto.FalseValue()
 	}
-	arg := call.Argument(1)
-	var passphrase string
-	if arg.IsUndefined() {
-		fmt.Println("Please enter a passphrase now.")
-		passphrase, err = readPassword("Passphrase: ", true)
-		if err != nil {
-			fmt.Println(err)
-			return otto.FalseValue()
-		}
-	} else {
-		passphrase, err = arg.ToString()
-		if err != nil {
-			fmt.Println(err)
-			return otto.FalseValue()
-		}
+	passphrase, err := unlockPassphrase(call.Argument(1))
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
 	}
 	am := js.ethereum.AccountManager()
 	err = am.TimedUnlock(common.FromHex(addr), passphrase, time.Duration(seconds)*time.Second)
@@ -392,33 +384,10 @@ func (js *jsre) unlock(call otto.FunctionCall) otto.Value {
 }
 
 func (js *jsre) newAccount(call otto.FunctionCall) otto.Value {
-	arg := call.Argument(0)
-	var passphrase string
-	if arg.IsUndefined() {
-		fmt.Println("The new account will be encrypted with a passphrase.")
-		fmt.Println("Please enter a passphrase now.")
-		auth, err := readPassword("Passphrase: ", true)
-		if err != nil {
-			fmt.Println(err)
-			return otto.FalseValue()
-		}
-		confirm, err := readPassword("Repeat Passphrase: ", false)
-		if err != nil {
-			fmt.Println(err)
-			return otto.FalseValue()
-		}
-		if auth != confirm {
-			fmt.Println("Passphrases did not match.")
-			return otto.FalseValue()
-		}
-		passphrase = auth
-	} else {
-		var err error
-		passphrase, err = arg.ToString()
-		if err != nil {
-			fmt.Println(err)
-			return otto.FalseValue()
-		}
+	passphrase, err := newPassphrase(call.Argument(0))
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
 	}
 	acct, err := js.ethereum.AccountManager().NewAccount(passphrase)
 	if err != nil {
@@ -428,6 +397,36 @@ func (js *jsre) newAccount(call otto.FunctionCall) otto.Value {
 	return js.re.ToVal(common.ToHex(acct.Address))
 }
 
+// unlockPassphrase returns the passphrase given as arg or prompts for it.
+func unlockPassphrase(arg otto.Value) (string, error) {
+	if !arg.IsUndefined() {
+		return arg.ToString()
+	}
+	fmt.Println("Please enter a passphrase now.")
+	return readPassword("Passphrase: ", true)
+}
+
+// newPassphrase returns the passphrase given as arg or prompts for a new one.
+func newPassphrase(arg otto.Value) (string, error) {
+	if !arg.IsUndefined() {
+		return arg.ToString()
+	}
+	fmt.Println("The new account will be encrypted with a passphrase.")
+	fmt.Println("Please enter a passphrase now.")
+	auth, err := readPassword("Passphrase: ", true)
+	if err != nil {
+		return "", err
+	}
+	confirm, err := readPassword("Repeat Passphrase: ", false)
+	if err != nil {
+		return "", err
+	}
+	if auth != confirm {
+		return "", errors.New("Passphrases did not match.")
+	}
+	return auth, nil
+}
+
 func (js *jsre) nodeInfo(call otto.FunctionCall) otto.Value {
 	return js.re.ToVal(js.ethereum.NodeInfo())
 }
@@ -438,15 +437,19 @@ func (js *jsre) peers(call otto.FunctionCall) otto.Value {
 
 func (js *jsre) importChain(call otto.FunctionCall) otto.Value {
 	if len(call.ArgumentList) == 0 {
-		fmt.Println("require file name. admin.importChain(filename)")
+		fmt.Println("require file name. admin.import(filename, ...)")
 		return otto.FalseValue()
 	}
-	fn, err := call.Argument(0).ToString()
-	if err != nil {
-		fmt.Println(err)
-		return otto.FalseValue()
+	fns := make([]string, len(call.ArgumentList))
+	for i, arg := range call.ArgumentList {
+		fn, err := arg.ToString()
+		if err != nil {
+			fmt.Println(err)
+			return otto.FalseValue()
+		}
+		fns[i] = fn
 	}
-	if err := utils.ImportChain(js.ethereum.ChainManager(), fn); err != nil {
+	if err := utils.ImportChain(js.ethereum.ChainManager(), fns...); err != nil {
 		fmt.Println("Import error: ", err)
 		return otto.FalseValue()
 	}
@@ -454,8 +457,8 @@ func (js *jsre) importChain(call otto.FunctionCall) otto.Value {
 }
 
 func (js *jsre) exportChain(call otto.FunctionCall) otto.Value {
-	if len(call.ArgumentList) == 0 {
-		fmt.Println("require file name: admin.exportChain(filename)")
+	if len(call.ArgumentList) != 1 && len(call.ArgumentList) != 3 {
+		fmt.Println("require file name: admin.export(filename, [first, last])")
 		return otto.FalseValue()
 	}
 
@@ -464,7 +467,18 @@ func (js *jsre) exportChain(call otto.FunctionCall) otto.Value {
 		fmt.Println(err)
 		return otto.FalseValue()
 	}
-	if err := utils.ExportChain(js.ethereum.ChainManager(), fn); err != nil {
+	if len(call.ArgumentList) == 3 {
+		first, ferr := call.Argument(1).ToInteger()
+		last, lerr := call.Argument(2).ToInteger()
+		if ferr != nil || lerr != nil || first < 0 || last < 0 {
+			fmt.Println("invalid block range")
+			return otto.FalseValue()
+		}
+		err = utils.ExportChainN(js.ethereum.ChainManager(), fn, uint64(first), uint64(last))
+	} else {
+		err = utils.ExportChain(js.ethereum.ChainManager(), fn)
+	}
+	if err != nil {
 		fmt.Println(err)
 		return otto.FalseValue()
 	}
@@ -495,6 +509,58 @@ func (js *jsre) dumpBlock(call otto.FunctionCall) otto.Value {
 	return js.re.ToVal(dump)
 }
 
+func (js *jsre) chainForks(call otto.FunctionCall) otto.Value {
+	depth := int64(16)
+	if len(call.ArgumentList) > 0 {
+		var err error
+		if depth, err = call.Argument(0).ToInteger(); err != nil || depth < 0 {
+			fmt.Println("requires a non-negative depth: admin.debug.chainForks(depth)")
+			return otto.UndefinedValue()
+		}
+	}
+	return toJSON(call, js.ethereum.ChainManager().ForkTree(uint64(depth)))
+}
+
+func (js *jsre) blocksAt(call otto.FunctionCall) otto.Value {
+	num, err := call.Argument(0).ToInteger()
+	if err != nil || num < 0 {
+		fmt.Println("requires a block number: admin.debug.blocksAt(number)")
+		return otto.UndefinedValue()
+	}
+	return toJSON(call, js.ethereum.ChainManager().BlocksAt(uint64(num)))
+}
+
+func (js *jsre) commonAncestor(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 2 {
+		fmt.Println("requires 2 arguments: admin.debug.commonAncestor(hash, hash)")
+		return otto.UndefinedValue()
+	}
+	a, _ := call.Argument(0).ToString()
+	b, _ := call.Argument(1).ToString()
+
+	block := js.ethereum.ChainManager().CommonAncestor(common.HexToHash(a), common.HexToHash(b))
+	if block == nil {
+		return otto.NullValue()
+	}
+	return js.re.ToVal(block.Hash().Hex())
+}
+
+// toJSON converts v into a plain javascript value by way of its JSON encoding,
+// so that the field names match the ones of the RPC API.
+func toJSON(call otto.FunctionCall, v interface{}) otto.Value {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Println(err)
+		return otto.UndefinedValue()
+	}
+	obj, err := call.Otto.Run("(" + string(data) + ")")
+	if err != nil {
+		fmt.Println(err)
+		return otto.UndefinedValue()
+	}
+	return obj
+}
+
 func (js *jsre) waitForBlocks(call otto.FunctionCall) otto.Value {
 	if len(call.ArgumentList) > 2 {
 		fmt.Println("requires 0, 1 or 2 arguments: admin.debug.waitForBlock(minHeight, timeout)")
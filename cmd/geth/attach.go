@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/robertkrimen/otto"
+)
+
+// remoteAdminBindings sets up the admin object of an attached console, which
+// operates the node through the admin, miner and debug methods of its RPC
+// API. Chain import and export read and write files of the local process and
+// are only available in the console started by geth itself.
+func (js *jsre) remoteAdminBindings() {
+	js.re.Set("admin", struct{}{})
+	t, _ := js.re.Get("admin")
+	admin := t.Object()
+	admin.Set("addPeer", js.remote("admin_addPeer"))
+	admin.Set("startRPC", js.remote("admin_startRPC"))
+	admin.Set("stopRPC", js.remote("admin_stopRPC"))
+	admin.Set("nodeInfo", js.remote("admin_nodeInfo"))
+	admin.Set("peers", js.remote("admin_peers"))
+	admin.Set("newAccount", js.remoteNewAccount)
+	admin.Set("unlock", js.remoteUnlock)
+	admin.Set("verbosity", js.remote("admin_verbosity"))
+	admin.Set("progress", js.remote("admin_progress"))
+
+	admin.Set("miner", struct{}{})
+	t, _ = admin.Get("miner")
+	miner := t.Object()
+	miner.Set("start", js.remote("miner_start"))
+	miner.Set("stop", js.remote("miner_stop"))
+	miner.Set("hashrate", js.remote("miner_hashrate"))
+	miner.Set("setExtra", js.remote("miner_setExtra"))
+	miner.Set("setGasPrice", js.remote("miner_setGasPrice"))
+
+	admin.Set("debug", struct{}{})
+	t, _ = admin.Get("debug")
+	debug := t.Object()
+	debug.Set("chainForks", js.remote("debug_chainForks"))
+	debug.Set("blocksAt", js.remote("debug_blocksAtHeight"))
+	debug.Set("commonAncestor", js.remote("debug_commonAncestor"))
+}
+
+// remote returns a binding which calls method with the arguments it is
+// given.
+func (js *jsre) remote(method string) func(call otto.FunctionCall) otto.Value {
+	return func(call otto.FunctionCall) otto.Value {
+		params := make([]interface{}, len(call.ArgumentList))
+		for i, arg := range call.ArgumentList {
+			v, err := arg.Export()
+			if err != nil {
+				fmt.Println(err)
+				return otto.FalseValue()
+			}
+			params[i] = v
+		}
+		return js.call(method, params...)
+	}
+}
+
+func (js *jsre) call(method string, params ...interface{}) otto.Value {
+	var result interface{}
+	if err := rpc.Call(js.client, method, &result, params...); err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
+	}
+	return js.re.ToVal(result)
+}
+
+func (js *jsre) remoteUnlock(call otto.FunctionCall) otto.Value {
+	addr, err := call.Argument(0).ToString()
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
+	}
+	seconds, err := call.Argument(2).ToInteger()
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
+	}
+	passphrase, err := unlockPassphrase(call.Argument(1))
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
+	}
+	return js.call("admin_unlock", addr, passphrase, seconds)
+}
+
+func (js *jsre) remoteNewAccount(call otto.FunctionCall) otto.Value {
+	passphrase, err := newPassphrase(call.Argument(0))
+	if err != nil {
+		fmt.Println(err)
+		return otto.FalseValue()
+	}
+	return js.call("admin_newAccount", passphrase)
+}
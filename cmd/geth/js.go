@@ -63,15 +63,17 @@ type jsre struct {
 	re         *re.JSRE
 	ethereum   *eth.Ethereum
 	xeth       *xeth.XEth
+	client     rpc.Client // connection to the node of an attached console
 	wait       chan *big.Int
 	ps1        string
+	datadir    string
 	atexit     func()
 	corsDomain string
 	prompter
 }
 
 func newJSRE(ethereum *eth.Ethereum, libPath, solcPath, corsDomain string, interactive bool, f xeth.Frontend) *jsre {
-	js := &jsre{ethereum: ethereum, ps1: "> "}
+	js := &jsre{ethereum: ethereum, ps1: "> ", datadir: ethereum.DataDir}
 	// set default cors domain used by startRpc from CLI flag
 	js.corsDomain = corsDomain
 	if f == nil {
@@ -85,6 +87,28 @@ func newJSRE(ethereum *eth.Ethereum, libPath, solcPath, corsDomain string, inter
 	js.apiBindings(f)
 	js.adminBindings()
 
+	js.initPrompter(interactive)
+	if exit := js.atexit; exit != nil {
+		js.atexit = func() {
+			exit()
+			close(js.wait)
+		}
+	}
+	return js
+}
+
+// newRemoteJSRE returns a console operating the node client is connected to.
+func newRemoteJSRE(client rpc.Client, datadir, libPath string, interactive bool) *jsre {
+	js := &jsre{client: client, ps1: "> ", datadir: datadir}
+	js.re = re.New(libPath)
+	js.web3Bindings(rpc.NewRemoteJeth(client, js.re))
+	js.remoteAdminBindings()
+
+	js.initPrompter(interactive)
+	return js
+}
+
+func (js *jsre) initPrompter(interactive bool) {
 	if !liner.TerminalSupported() || !interactive {
 		js.prompter = dumbterm{bufio.NewReader(os.Stdin)}
 	} else {
@@ -95,17 +119,18 @@ func newJSRE(ethereum *eth.Ethereum, libPath, solcPath, corsDomain string, inter
 		js.atexit = func() {
 			js.withHistory(func(hist *os.File) { hist.Truncate(0); lr.WriteHistory(hist) })
 			lr.Close()
-			close(js.wait)
 		}
 	}
-	return js
 }
 
 func (js *jsre) apiBindings(f xeth.Frontend) {
 	xe := xeth.New(js.ethereum, f)
 	ethApi := rpc.NewEthereumApi(xe)
-	jeth := rpc.NewJeth(ethApi, js.re.ToVal, js.re)
+	js.web3Bindings(rpc.NewJeth(ethApi, js.re.ToVal, js.re))
+}
 
+// web3Bindings loads web3 using jeth as provider.
+func (js *jsre) web3Bindings(jeth *rpc.Jeth) {
 	js.re.Set("jeth", struct{}{})
 	t, _ := js.re.Get("jeth")
 	jethObj := t.Object()
@@ -209,7 +234,7 @@ func (self *jsre) interactive() {
 }
 
 func (self *jsre) withHistory(op func(*os.File)) {
-	hist, err := os.OpenFile(path.Join(self.ethereum.DataDir, "history"), os.O_RDWR|os.O_CREATE, os.ModePerm)
+	hist, err := os.OpenFile(path.Join(self.datadir, "history"), os.O_RDWR|os.O_CREATE, os.ModePerm)
 	if err != nil {
 		fmt.Printf("unable to open history file: %v\n", err)
 		return
@@ -21,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -124,6 +125,36 @@ func TestNodeInfo(t *testing.T) {
 	checkEvalJSON(t, repl, `admin.nodeInfo()`, want)
 }
 
+func TestAttach(t *testing.T) {
+	tmp, _, ethereum := testJEthRE(t)
+	if err := ethereum.Start(); err != nil {
+		t.Fatalf("error starting ethereum: %v", err)
+	}
+	defer ethereum.Stop()
+	defer os.RemoveAll(tmp)
+
+	endpoint := filepath.Join(tmp, "geth.ipc")
+	if err := rpc.StartIPC(ethereum, rpc.IpcConfig{Endpoint: endpoint}); err != nil {
+		t.Fatal(err)
+	}
+	defer rpc.StopIPC()
+
+	client, err := rpc.NewClient(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	repl := &testjethre{jsre: newRemoteJSRE(client, tmp, "", false)}
+
+	checkEvalJSON(t, repl, `eth.accounts`, `["`+testAddress+`"]`)
+	checkEvalJSON(t, repl, `admin.progress()`, `"0/0"`)
+	checkEvalJSON(t, repl, `admin.miner.hashrate()`, `0`)
+	checkEvalJSON(t, repl, `admin.unlock("`+testAddress+`", "", 0)`, `true`)
+	checkEvalJSON(t, repl, `admin.unlock("`+testAddress+`", "wrong", 0)`, `false`)
+	checkEvalJSON(t, repl, `admin.miner.setExtra("extra")`, `true`)
+	checkEvalJSON(t, repl, `admin.debug.blocksAt(0)[0].canonical`, `true`)
+}
+
 func TestAccounts(t *testing.T) {
 	tmp, repl, ethereum := testJEthRE(t)
 	if err := ethereum.Start(); err != nil {
@@ -182,6 +213,21 @@ func TestBlockChain(t *testing.T) {
 	checkEvalJSON(t, repl, `admin.debug.dumpBlock()`, beforeExport)
 }
 
+func TestChainForks(t *testing.T) {
+	tmp, repl, ethereum := testJEthRE(t)
+	if err := ethereum.Start(); err != nil {
+		t.Fatalf("error starting ethereum: %v", err)
+	}
+	defer ethereum.Stop()
+	defer os.RemoveAll(tmp)
+
+	genesis := ethereum.ChainManager().Genesis()
+	hash := strconv.Quote(genesis.Hash().Hex())
+	checkEvalJSON(t, repl, `admin.debug.chainForks(0)[0].hash`, hash)
+	checkEvalJSON(t, repl, `admin.debug.blocksAt(0)[0].canonical`, `true`)
+	checkEvalJSON(t, repl, `admin.debug.commonAncestor(`+hash+`, `+hash+`)`, hash)
+}
+
 func TestMining(t *testing.T) {
 	tmp, repl, ethereum := testJEthRE(t)
 	if err := ethereum.Start(); err != nil {
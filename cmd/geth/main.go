@@ -22,6 +22,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -43,6 +44,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/peterh/liner"
@@ -195,6 +199,68 @@ nodes.
 			Description: `
 The arguments are interpreted as block numbers or hashes.
 Use "ethereum dump 0" to dump the genesis block.
+
+With --diff, exactly two blocks must be given and only the accounts
+and storage slots which differ between their states are printed.
+`,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "diff",
+					Usage: "print the state difference between two blocks",
+				},
+			},
+		},
+		{
+			Action: badBlocksList,
+			Name:   "badblocks",
+			Usage:  "list or export recently rejected blocks",
+			Description: `
+Blocks rejected during import are kept along with the validation error and
+the peer they came from. Without a subcommand they are listed.
+`,
+			Subcommands: []cli.Command{
+				{
+					Action: badBlocksExport,
+					Name:   "export",
+					Usage:  "export rejected blocks to a directory",
+					Description: `
+For every rejected block two files are written to the given directory:
+<number>-<hash>.rlp holds the RLP encoded block, which can be passed to
+"geth replay", <number>-<hash>.json holds the hex encoded block, as taken by
+debug_replayBlock, along with the error, peer and time of rejection.
+`,
+				},
+			},
+		},
+		{
+			Name:  "index",
+			Usage: "manage the address index",
+			Subcommands: []cli.Command{
+				{
+					Action: indexRebuild,
+					Name:   "rebuild",
+					Usage:  "index the transactions of each address from scratch",
+					Description: `
+Drops the address index and builds it again from the canonical chain. Run it
+after enabling --addrindex on an existing chain, blocks imported before are
+not indexed otherwise. The internal value transfers of blocks processed
+before are found by executing them again.
+`,
+				},
+			},
+		},
+		{
+			Action: replay,
+			Name:   "replay",
+			Usage:  `re-execute a block and report where it diverges`,
+			Description: `
+The argument is interpreted as a block number or hash, or as the name of a
+file holding an RLP encoded block.
+
+The block is executed on top of its parent's state and the receipt of each
+transaction is compared with the block's. The first diverging transaction is
+reported along with the state changes it made. Nothing is written to the
+database.
 `,
 		},
 		{
@@ -205,6 +271,22 @@ Use "ethereum dump 0" to dump the genesis block.
 The Geth console is an interactive shell for the JavaScript runtime environment
 which exposes a node admin interface as well as the Ðapp JavaScript API.
 See https://github.com/ethereum/go-ethereum/wiki/Javascipt-Console
+`,
+		},
+		{
+			Action: attach,
+			Name:   "attach",
+			Usage:  `Geth Console: interactive JavaScript environment (connect to node)`,
+			Description: `
+The Geth console is an interactive shell for the JavaScript runtime environment
+which exposes a node admin interface as well as the Ðapp JavaScript API.
+This command opens a console on a running geth node, reached through the
+endpoint given as argument: the path of its IPC socket or the URL of its
+HTTP RPC server. The endpoint defaults to the IPC socket of the data directory.
+admin.import and admin.export are only available in the console of the node
+process itself.
+
+  geth attach [endpoint]
 `,
 		},
 		{
@@ -219,12 +301,25 @@ JavaScript API. See https://github.com/ethereum/go-ethereum/wiki/Javascipt-Conso
 		{
 			Action: importchain,
 			Name:   "import",
-			Usage:  `import a blockchain file`,
+			Usage:  `import blockchain files`,
+			Description: `
+    geth import <file> [<file> ...]
+
+Imports the blocks of the given files in order, files ending in .gz are
+decompressed. Blocks which are already known are skipped, an interrupted
+import is resumed by running the same command again.
+`,
 		},
 		{
 			Action: exportchain,
 			Name:   "export",
 			Usage:  `export blockchain into file`,
+			Description: `
+    geth export <file> [<first> <last>]
+
+Exports the canonical blocks first to last, or the whole chain if no range
+is given. The file is gzipped if its name ends in .gz.
+`,
 		},
 		{
 			Action: upgradeDb,
@@ -256,9 +351,17 @@ JavaScript API. See https://github.com/ethereum/go-ethereum/wiki/Javascipt-Conso
 		utils.RPCPortFlag,
 		utils.WhisperEnabledFlag,
 		utils.VMDebugFlag,
+		utils.PreimagesFlag,
+		utils.AddrIndexFlag,
 		utils.ProtocolVersionFlag,
 		utils.NetworkIdFlag,
 		utils.RPCCORSDomainFlag,
+		utils.IPCDisabledFlag,
+		utils.IPCPathFlag,
+		utils.WSEnabledFlag,
+		utils.WSListenAddrFlag,
+		utils.WSPortFlag,
+		utils.WSOriginsFlag,
 		utils.VerbosityFlag,
 		utils.BacktraceAtFlag,
 		utils.LogToStdErrFlag,
@@ -337,6 +440,34 @@ func console(ctx *cli.Context) {
 	ethereum.WaitForShutdown()
 }
 
+func attach(ctx *cli.Context) {
+	// Wrap the standard output with a colorified stream (windows)
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		if pr, pw, err := os.Pipe(); err == nil {
+			go io.Copy(colorable.NewColorableStdout(), pr)
+			os.Stdout = pw
+		}
+	}
+
+	endpoint := ctx.Args().First()
+	if endpoint == "" {
+		endpoint = utils.IPCSocketPath(ctx)
+	}
+	client, err := rpc.NewClient(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to geth node at %s: %v", endpoint, err)
+	}
+	defer client.Close()
+
+	repl := newRemoteJSRE(
+		client,
+		ctx.GlobalString(utils.DataDirFlag.Name),
+		ctx.String(utils.JSpathFlag.Name),
+		true,
+	)
+	repl.interactive()
+}
+
 func execJSFiles(ctx *cli.Context) {
 	cfg := utils.MakeEthConfig(ClientIdentifier, nodeNameVersion, ctx)
 	ethereum, err := eth.New(cfg)
@@ -395,11 +526,21 @@ func startEth(ctx *cli.Context, eth *eth.Ethereum) {
 		unlockAccount(ctx, am, account)
 	}
 	// Start auxiliary services if enabled.
+	if !ctx.GlobalBool(utils.IPCDisabledFlag.Name) {
+		if err := utils.StartIPC(eth, ctx); err != nil {
+			glog.V(logger.Error).Infof("Error starting IPC: %v\n", err)
+		}
+	}
 	if ctx.GlobalBool(utils.RPCEnabledFlag.Name) {
 		if err := utils.StartRPC(eth, ctx); err != nil {
 			utils.Fatalf("Error starting RPC: %v", err)
 		}
 	}
+	if ctx.GlobalBool(utils.WSEnabledFlag.Name) {
+		if err := utils.StartWS(eth, ctx); err != nil {
+			utils.Fatalf("Error starting WebSocket RPC: %v", err)
+		}
+	}
 	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) {
 		if err := eth.StartMining(); err != nil {
 			utils.Fatalf("%v", err)
@@ -494,7 +635,7 @@ func accountImport(ctx *cli.Context) {
 }
 
 func importchain(ctx *cli.Context) {
-	if len(ctx.Args()) != 1 {
+	if len(ctx.Args()) == 0 {
 		utils.Fatalf("This command requires an argument.")
 	}
 
@@ -508,7 +649,7 @@ func importchain(ctx *cli.Context) {
 
 	chainmgr := ethereum.ChainManager()
 	start := time.Now()
-	err = utils.ImportChain(chainmgr, ctx.Args().First())
+	err = utils.ImportChain(chainmgr, ctx.Args()...)
 	if err != nil {
 		utils.Fatalf("Import error: %v\n", err)
 	}
@@ -524,8 +665,9 @@ func importchain(ctx *cli.Context) {
 }
 
 func exportchain(ctx *cli.Context) {
-	if len(ctx.Args()) != 1 {
-		utils.Fatalf("This command requires an argument.")
+	args := ctx.Args()
+	if len(args) != 1 && len(args) != 3 {
+		utils.Fatalf("Usage: geth export <file> [<first> <last>]")
 	}
 
 	cfg := utils.MakeEthConfig(ClientIdentifier, nodeNameVersion, ctx)
@@ -538,7 +680,16 @@ func exportchain(ctx *cli.Context) {
 
 	chainmgr := ethereum.ChainManager()
 	start := time.Now()
-	err = utils.ExportChain(chainmgr, ctx.Args().First())
+	if len(args) == 3 {
+		first, ferr := strconv.ParseUint(args[1], 10, 64)
+		last, lerr := strconv.ParseUint(args[2], 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("Invalid block range %s-%s\n", args[1], args[2])
+		}
+		err = utils.ExportChainN(chainmgr, args[0], first, last)
+	} else {
+		err = utils.ExportChain(chainmgr, args[0])
+	}
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
@@ -602,22 +753,148 @@ func upgradeDb(ctx *cli.Context) {
 
 func dump(ctx *cli.Context) {
 	chainmgr, _, stateDb := utils.GetChain(ctx)
+	if ctx.Bool("diff") {
+		if len(ctx.Args()) != 2 {
+			utils.Fatalf("Usage: geth dump --diff <block> <block>")
+		}
+		from := state.New(dumpBlock(chainmgr, ctx.Args()[0]).Root(), stateDb)
+		to := state.New(dumpBlock(chainmgr, ctx.Args()[1]).Root(), stateDb)
+		fmt.Printf("%s\n", from.Diff(to))
+		return
+	}
 	for _, arg := range ctx.Args() {
-		var block *types.Block
-		if hashish(arg) {
-			block = chainmgr.GetBlock(common.HexToHash(arg))
-		} else {
-			num, _ := strconv.Atoi(arg)
-			block = chainmgr.GetBlockByNumber(uint64(num))
+		statedb := state.New(dumpBlock(chainmgr, arg).Root(), stateDb)
+		fmt.Printf("%s\n", statedb.Dump())
+	}
+}
+
+func replay(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("Usage: geth replay <block number, hash or RLP file>")
+	}
+
+	// Only load blocks from the chain if the argument isn't a file, so that
+	// a file which can't be read doesn't silently end up as block lookup.
+	arg := ctx.Args().First()
+	var block *types.Block
+	if _, err := os.Stat(arg); err == nil {
+		data, err := ioutil.ReadFile(arg)
+		if err != nil {
+			utils.Fatalf("Could not read block file: %v", err)
 		}
-		if block == nil {
-			fmt.Println("{}")
-			utils.Fatalf("block not found")
-		} else {
-			statedb := state.New(block.Root(), stateDb)
-			fmt.Printf("%s\n", statedb.Dump())
+		block = new(types.Block)
+		if err := rlp.DecodeBytes(data, block); err != nil {
+			utils.Fatalf("Invalid block RLP: %v", err)
 		}
+	} else if _, err := strconv.ParseUint(arg, 10, 64); err != nil && !(common.IsHex(arg) && len(arg) == 2+2*len(common.Hash{})) {
+		utils.Fatalf("%s is neither a block file, hash nor number", arg)
+	}
+
+	cfg := utils.MakeEthConfig(ClientIdentifier, nodeNameVersion, ctx)
+	cfg.SkipBcVersionCheck = true
+
+	ethereum, err := eth.New(cfg)
+	if err != nil {
+		utils.Fatalf("%v\n", err)
+	}
+	if block == nil {
+		block = dumpBlock(ethereum.ChainManager(), arg)
+	}
+
+	report, err := ethereum.BlockProcessor().ReplayBlock(block)
+	if err != nil {
+		utils.Fatalf("Replay error: %v", err)
+	}
+	out, _ := json.MarshalIndent(report, "", "    ")
+	fmt.Printf("%s\n", out)
+}
+
+func indexRebuild(ctx *cli.Context) {
+	cfg := utils.MakeEthConfig(ClientIdentifier, nodeNameVersion, ctx)
+	cfg.AddressIndex = true
+
+	ethereum, err := eth.New(cfg)
+	if err != nil {
+		utils.Fatalf("%v\n", err)
+	}
+
+	var (
+		chainmgr = ethereum.ChainManager()
+		head     = chainmgr.CurrentBlock().NumberU64()
+		start    = time.Now()
+		reported = start
+	)
+	err = chainmgr.RebuildAddressIndex(func(number uint64) {
+		if time.Since(reported) >= 5*time.Second {
+			reported = time.Now()
+			fmt.Printf("indexed %d/%d blocks\n", number, head)
+		}
+	})
+	if err != nil {
+		utils.Fatalf("Index error: %v\n", err)
+	}
+
+	// force database flush
+	ethereum.BlockDb().Close()
+	ethereum.StateDb().Close()
+	ethereum.ExtraDb().Close()
+
+	fmt.Printf("Indexed %d blocks in %v\n", head+1, time.Since(start))
+}
+
+func badBlocksList(ctx *cli.Context) {
+	chainmgr, _, _ := utils.GetChain(ctx)
+	for _, bad := range chainmgr.BadBlocks() {
+		fmt.Printf("#%v %x %v peer=%q: %s\n", bad.Number, bad.Hash, bad.Time.Format(time.RFC3339), bad.Peer, bad.Error)
+	}
+}
+
+func badBlocksExport(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("Usage: geth badblocks export <dir>")
+	}
+	dir := ctx.Args().First()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Fatalf("%v", err)
+	}
+
+	chainmgr, _, _ := utils.GetChain(ctx)
+	bad := chainmgr.BadBlocks()
+	for _, block := range bad {
+		name := filepath.Join(dir, fmt.Sprintf("%v-%x", block.Number, block.Hash))
+		if err := ioutil.WriteFile(name+".rlp", block.RLP, 0644); err != nil {
+			utils.Fatalf("%v", err)
+		}
+		info, _ := json.MarshalIndent(map[string]interface{}{
+			"hash":   block.Hash.Hex(),
+			"number": block.Number,
+			"rlp":    common.ToHex(block.RLP),
+			"error":  block.Error,
+			"peer":   block.Peer,
+			"time":   block.Time.Unix(),
+		}, "", "    ")
+		if err := ioutil.WriteFile(name+".json", info, 0644); err != nil {
+			utils.Fatalf("%v", err)
+		}
+	}
+	fmt.Printf("Exported %d bad blocks to %s\n", len(bad), dir)
+}
+
+// dumpBlock retrieves the block given by number or hash, exiting if the
+// block does not exist.
+func dumpBlock(chainmgr *core.ChainManager, arg string) *types.Block {
+	var block *types.Block
+	if hashish(arg) {
+		block = chainmgr.GetBlock(common.HexToHash(arg))
+	} else {
+		num, _ := strconv.Atoi(arg)
+		block = chainmgr.GetBlockByNumber(uint64(num))
+	}
+	if block == nil {
+		fmt.Println("{}")
+		utils.Fatalf("block not found")
 	}
+	return block
 }
 
 func makedag(ctx *cli.Context) {
@@ -22,11 +22,14 @@
 package utils
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -136,61 +139,222 @@ func FormatTransactionData(data string) []byte {
 	return d
 }
 
-func ImportChain(chainmgr *core.ChainManager, fn string) error {
-	fmt.Printf("importing blockchain '%s'\n", fn)
-	fh, err := os.OpenFile(fn, os.O_RDONLY, os.ModePerm)
-	if err != nil {
-		return err
+// importBatchSize is the number of blocks inserted into the chain at once.
+const importBatchSize = 2500
+
+// progressInterval is the time between two progress reports.
+var progressInterval = 5 * time.Second
+
+// ImportChain inserts the blocks stored in the given files, in order. Files
+// whose name ends in ".gz" are decompressed. Blocks which are already known
+// are skipped, so an interrupted import is resumed by running it again.
+func ImportChain(chainmgr *core.ChainManager, fns ...string) error {
+	var (
+		sizes = make([]int64, len(fns))
+		total int64
+	)
+	for i, fn := range fns {
+		fi, err := os.Stat(fn)
+		if err != nil {
+			return err
+		}
+		sizes[i] = fi.Size()
+		total += sizes[i]
 	}
-	defer fh.Close()
 
-	chainmgr.Reset()
-	stream := rlp.NewStream(fh, 0)
-	var i, n int
+	var (
+		read     int64 // bytes of the finished files
+		progress = newProgress("imported")
+	)
+	for i, fn := range fns {
+		fmt.Printf("importing blockchain '%s'\n", fn)
 
-	batchSize := 2500
-	blocks := make(types.Blocks, batchSize)
+		fh, err := os.Open(fn)
+		if err != nil {
+			return err
+		}
+		counter := &countingReader{r: fh}
+		progress.frac = func() float64 {
+			if total == 0 {
+				return 1
+			}
+			return float64(read+counter.n) / float64(total)
+		}
+		err = importFile(chainmgr, fn, counter, progress)
+		fh.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		read += sizes[i]
+	}
+	progress.finish()
+	return nil
+}
+
+func importFile(chainmgr *core.ChainManager, fn string, r io.Reader, progress *progress) error {
+	if strings.HasSuffix(fn, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
 
-	for ; ; i++ {
+	stream := rlp.NewStream(r, 0)
+	blocks := make(types.Blocks, 0, importBatchSize)
+	for i := 0; ; i++ {
 		var b types.Block
-		if err := stream.Decode(&b); err == io.EOF {
-			break
-		} else if err != nil {
+		err := stream.Decode(&b)
+		if err != nil && err != io.EOF {
 			return fmt.Errorf("at block %d: %v", i, err)
 		}
-
-		blocks[n] = &b
-		n++
-
-		if n == batchSize {
-			if _, err := chainmgr.InsertChain(blocks); err != nil {
-				return fmt.Errorf("invalid block %v", err)
+		if err == nil {
+			blocks = append(blocks, &b)
+		}
+		if len(blocks) == importBatchSize || (err == io.EOF && len(blocks) > 0) {
+			if err := insertMissing(chainmgr, blocks, progress); err != nil {
+				return err
 			}
-			n = 0
-			blocks = make(types.Blocks, batchSize)
+			blocks = blocks[:0]
+		}
+		if err == io.EOF {
+			return nil
 		}
 	}
+}
 
-	if n > 0 {
-		if _, err := chainmgr.InsertChain(blocks[:n]); err != nil {
+// insertMissing inserts the blocks of the batch which aren't in the chain yet.
+func insertMissing(chainmgr *core.ChainManager, blocks types.Blocks, progress *progress) error {
+	missing := make(types.Blocks, 0, len(blocks))
+	for _, block := range blocks {
+		if !chainmgr.HasBlock(block.Hash()) {
+			missing = append(missing, block)
+		}
+	}
+	if len(missing) > 0 {
+		if _, err := chainmgr.InsertChain(missing); err != nil {
 			return fmt.Errorf("invalid block %v", err)
 		}
 	}
-
-	fmt.Printf("imported %d blocks\n", i)
+	progress.add(len(missing), len(blocks)-len(missing))
 	return nil
 }
 
+// ExportChain writes the whole canonical chain to the given file.
 func ExportChain(chainmgr *core.ChainManager, fn string) error {
+	return ExportChainN(chainmgr, fn, 0, chainmgr.CurrentBlock().NumberU64())
+}
+
+// ExportChainN writes the canonical blocks first to last, both inclusive, to
+// the given file, gzipped if its name ends in ".gz".
+func ExportChainN(chainmgr *core.ChainManager, fn string, first, last uint64) error {
+	if first > last {
+		return fmt.Errorf("invalid range: first (%d) is greater than last (%d)", first, last)
+	}
+	if head := chainmgr.CurrentBlock().NumberU64(); last > head {
+		return fmt.Errorf("invalid range: last (%d) is beyond the head (%d)", last, head)
+	}
 	fmt.Printf("exporting blockchain '%s'\n", fn)
 	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
 	if err != nil {
 		return err
 	}
-	defer fh.Close()
-	if err := chainmgr.Export(fh); err != nil {
-		return err
+	if strings.HasSuffix(fn, ".gz") {
+		gz := gzip.NewWriter(fh)
+		err = exportBlocks(chainmgr, gz, first, last)
+		// closing writes the last block and the trailer of the archive
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+	} else {
+		err = exportBlocks(chainmgr, fh, first, last)
 	}
-	fmt.Printf("exported blockchain\n")
+	if closeErr := fh.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// exportBlocks writes the canonical blocks first to last in batches,
+// reporting progress as it goes.
+func exportBlocks(chainmgr *core.ChainManager, w io.Writer, first, last uint64) error {
+	progress := newProgress("exported")
+	progress.frac = func() float64 {
+		return float64(progress.done) / float64(last-first+1)
+	}
+	for from := first; from <= last; from += importBatchSize {
+		to := from + importBatchSize - 1
+		if to > last || to < from {
+			to = last
+		}
+		if err := chainmgr.ExportN(w, from, to); err != nil {
+			return err
+		}
+		progress.add(int(to-from+1), 0)
+		if to == last {
+			break
+		}
+	}
+	progress.finish()
 	return nil
 }
+
+// progress reports the speed of an import or export and the time it will
+// take to complete.
+type progress struct {
+	action  string
+	frac    func() float64 // share of the work done
+	done    int
+	skipped int
+
+	start, reported time.Time
+}
+
+func newProgress(action string) *progress {
+	now := time.Now()
+	return &progress{action: action, start: now, reported: now}
+}
+
+func (self *progress) add(done, skipped int) {
+	self.done += done
+	self.skipped += skipped
+	if time.Since(self.reported) >= progressInterval {
+		self.report()
+	}
+}
+
+func (self *progress) report() {
+	self.reported = time.Now()
+	elapsed := self.reported.Sub(self.start)
+
+	msg := fmt.Sprintf("%s %d blocks", self.action, self.done)
+	if self.skipped > 0 {
+		msg += fmt.Sprintf(" (%d known skipped)", self.skipped)
+	}
+	if secs := elapsed.Seconds(); secs > 0 {
+		msg += fmt.Sprintf(", %.1f blocks/s", float64(self.done+self.skipped)/secs)
+	}
+	if frac := self.frac(); frac > 0 && frac < 1 {
+		eta := time.Duration(float64(elapsed) * (1 - frac) / frac)
+		msg += fmt.Sprintf(", %.1f%% done, ETA %v", 100*frac, eta-eta%time.Second)
+	}
+	fmt.Println(msg)
+}
+
+func (self *progress) finish() {
+	self.frac = func() float64 { return 1 }
+	self.report()
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (self *countingReader) Read(p []byte) (int, error) {
+	n, err := self.r.Read(p)
+	self.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+func newTestChain(t testing.TB, n int) *core.ChainManager {
+	db, _ := ethdb.NewMemDatabase()
+	mux := new(event.TypeMux)
+	chainmgr := core.NewChainMan(nil, mux, db)
+	proc := core.NewBlockProc(db, chainmgr, mux)
+	chainmgr.SetProcessor(proc)
+	if n > 0 {
+		chain := core.MakeChain(proc, chainmgr.CurrentBlock(), n, db, core.CanonicalSeed)
+		if _, err := chainmgr.InsertChain(chain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chainmgr
+}
+
+func TestImportExportChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geth-chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newTestChain(t, 10)
+	first, second := filepath.Join(dir, "first.rlp"), filepath.Join(dir, "second.rlp.gz")
+	if err := ExportChainN(src, first, 0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportChainN(src, second, 4, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// the files overlap, the second one is resumed after the known blocks
+	dst := newTestChain(t, 0)
+	if err := ImportChain(dst, first, second); err != nil {
+		t.Fatal(err)
+	}
+	if head := dst.CurrentBlock(); head.Hash() != src.CurrentBlock().Hash() {
+		t.Fatalf("head mismatch: have #%v, want #%v", head.Number(), src.CurrentBlock().Number())
+	}
+	// importing again is a no-op
+	if err := ImportChain(dst, second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExportChainN(src, first, 5, 4); err == nil {
+		t.Errorf("expected error for reversed range")
+	}
+	// a range beyond the head leaves an existing file alone
+	if err := ExportChainN(src, first, 0, 11); err == nil {
+		t.Errorf("expected error for range beyond the head")
+	}
+	if fi, err := os.Stat(first); err != nil || fi.Size() == 0 {
+		t.Errorf("existing export was truncated: %v", err)
+	}
+}
+
+func BenchmarkImportChain(b *testing.B) {
+	dir, err := ioutil.TempDir("", "geth-chain")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newTestChain(b, 1000)
+	fn := filepath.Join(dir, "chain.rlp")
+	if err := ExportChain(src, fn); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// Verify seals at about the cost of ethash
+		db, _ := ethdb.NewMemDatabase()
+		mux := new(event.TypeMux)
+		dst := core.NewChainMan(nil, mux, db)
+		proc := core.NewBlockProc(db, dst, mux)
+		proc.Pow = core.SlowPow{Rounds: core.SlowPowRounds}
+		dst.SetProcessor(proc)
+		b.StartTimer()
+
+		if err := ImportChain(dst, fn); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 
 	"github.com/codegangsta/cli"
@@ -23,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/logger/glog"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/xeth"
 )
 
@@ -162,6 +164,14 @@ var (
 		Name:  "vmdebug",
 		Usage: "Virtual Machine debug output",
 	}
+	PreimagesFlag = cli.BoolFlag{
+		Name:  "preimages",
+		Usage: "Record account and storage key preimages in the state database (used by dump and debugging APIs)",
+	}
+	AddrIndexFlag = cli.BoolFlag{
+		Name:  "addrindex",
+		Usage: "Index the transactions of each address (used by eth_getTransactionsByAddress, see geth index rebuild)",
+	}
 	BacktraceAtFlag = cli.GenericFlag{
 		Name:  "backtrace_at",
 		Usage: "If set to a file and line number (e.g., \"block.go:271\") holding a logging statement, a stack trace will be logged",
@@ -197,6 +207,34 @@ var (
 		Usage: "Domain on which to send Access-Control-Allow-Origin header",
 		Value: "",
 	}
+	IPCDisabledFlag = cli.BoolFlag{
+		Name:  "ipcdisable",
+		Usage: "Disable the IPC-RPC server",
+	}
+	IPCPathFlag = cli.StringFlag{
+		Name:  "ipcpath",
+		Usage: "Filename for the IPC socket, relative to the data directory unless absolute",
+		Value: "geth.ipc",
+	}
+	WSEnabledFlag = cli.BoolFlag{
+		Name:  "ws",
+		Usage: "Enable the WebSocket JSON-RPC server",
+	}
+	WSListenAddrFlag = cli.StringFlag{
+		Name:  "wsaddr",
+		Usage: "Listening address for the WebSocket JSON-RPC server",
+		Value: "127.0.0.1",
+	}
+	WSPortFlag = cli.IntFlag{
+		Name:  "wsport",
+		Usage: "Port on which the WebSocket JSON-RPC server should listen",
+		Value: 8546,
+	}
+	WSOriginsFlag = cli.StringFlag{
+		Name:  "wsorigins",
+		Usage: "Comma separated origins from which to accept websocket connections (* for any)",
+		Value: "",
+	}
 	// Network Settings
 	MaxPeersFlag = cli.IntFlag{
 		Name:  "maxpeers",
@@ -302,6 +340,8 @@ func MakeEthConfig(clientID, version string, ctx *cli.Context) *eth.Config {
 		MinerThreads:       ctx.GlobalInt(MinerThreadsFlag.Name),
 		AccountManager:     GetAccountManager(ctx),
 		VmDebug:            ctx.GlobalBool(VMDebugFlag.Name),
+		Preimages:          ctx.GlobalBool(PreimagesFlag.Name),
+		AddressIndex:       ctx.GlobalBool(AddrIndexFlag.Name),
 		MaxPeers:           ctx.GlobalInt(MaxPeersFlag.Name),
 		MaxPendingPeers:    ctx.GlobalInt(MaxPendingPeersFlag.Name),
 		Port:               ctx.GlobalString(ListenPortFlag.Name),
@@ -324,10 +364,13 @@ func GetChain(ctx *cli.Context) (*core.ChainManager, common.Database, common.Dat
 		Fatalf("Could not open database: %v", err)
 	}
 
-	stateDb, err := ethdb.NewLDBDatabase(path.Join(dataDir, "state"))
-	if err != nil {
+	var stateDb common.Database
+	if stateDb, err = ethdb.NewLDBDatabase(path.Join(dataDir, "state")); err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	if ctx.GlobalBool(PreimagesFlag.Name) {
+		stateDb = trie.NewPreimageDatabase(stateDb)
+	}
 
 	extraDb, err := ethdb.NewLDBDatabase(path.Join(dataDir, "extra"))
 	if err != nil {
@@ -361,6 +404,37 @@ func StartRPC(eth *eth.Ethereum, ctx *cli.Context) error {
 	return rpc.Start(xeth, config)
 }
 
+// IPCSocketPath returns the path of the IPC socket.
+func IPCSocketPath(ctx *cli.Context) string {
+	endpoint := ctx.GlobalString(IPCPathFlag.Name)
+	if filepath.IsAbs(endpoint) {
+		return endpoint
+	}
+	return filepath.Join(ctx.GlobalString(DataDirFlag.Name), endpoint)
+}
+
+func StartIPC(eth *eth.Ethereum, ctx *cli.Context) error {
+	config := rpc.IpcConfig{
+		Endpoint: IPCSocketPath(ctx),
+	}
+	if err := rpc.StartIPC(eth, config); err != nil {
+		return err
+	}
+	RegisterInterrupt(func(os.Signal) { rpc.StopIPC() })
+	return nil
+}
+
+func StartWS(eth *eth.Ethereum, ctx *cli.Context) error {
+	config := rpc.WSConfig{
+		ListenAddress: ctx.GlobalString(WSListenAddrFlag.Name),
+		ListenPort:    uint(ctx.GlobalInt(WSPortFlag.Name)),
+		Origins:       ctx.GlobalString(WSOriginsFlag.Name),
+	}
+
+	xeth := xeth.New(eth, nil)
+	return rpc.StartWS(xeth, config)
+}
+
 func StartPProf(ctx *cli.Context) {
 	address := fmt.Sprintf("localhost:%d", ctx.GlobalInt(PProfPortFlag.Name))
 	go func() {
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	addrTxPre      = []byte("addr-tx-")    // address + sequence number -> AddressTx
+	addrTxCountPre = []byte("addr-txs-")   // address -> number of entries
+	addrBlockPre   = []byte("addr-block-") // block number -> addresses indexed for the block
+	addrNextKey    = []byte("addr-index-next")
+)
+
+// AddressTx locates a transaction of the canonical chain.
+type AddressTx struct {
+	Number uint64 // block number
+	Index  uint64 // transaction index within the block
+}
+
+// AddressIndex maps addresses to the canonical transactions sending from or
+// to them, directly or by an internal value transfer. Blocks are indexed in
+// order as they become canonical, the entries of an address are kept oldest
+// first.
+type AddressIndex struct {
+	db common.Database
+	mu sync.Mutex
+}
+
+func NewAddressIndex(db common.Database) *AddressIndex {
+	return &AddressIndex{db: db}
+}
+
+// Count returns the number of transactions indexed for addr.
+func (self *AddressIndex) Count(addr common.Address) uint64 {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.count(addr)
+}
+
+// Transactions returns at most limit transactions of addr, most recent
+// first, after skipping the offset most recent ones.
+func (self *AddressIndex) Transactions(addr common.Address, offset, limit uint64) []AddressTx {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	txs := []AddressTx{}
+	count := self.count(addr)
+	if offset >= count {
+		return txs
+	}
+	for seq := count - offset; seq > 0 && uint64(len(txs)) < limit; seq-- {
+		if entry, ok := self.entry(addr, seq-1); ok {
+			txs = append(txs, entry)
+		}
+	}
+	return txs
+}
+
+// Next returns the number of the block following the last indexed one.
+func (self *AddressIndex) Next() uint64 {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.next()
+}
+
+// add indexes the transactions of block and the value transfers they made.
+// The entries of blocks with the same or a higher number are dropped first.
+func (self *AddressIndex) add(block *types.Block, transfers []*state.Transfer) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	number := block.NumberU64()
+	self.unwind(number)
+
+	var (
+		addrs   []common.Address
+		entries = make(map[common.Address][]AddressTx)
+	)
+	add := func(addr common.Address, index uint64) {
+		known := entries[addr]
+		if len(known) > 0 && known[len(known)-1].Index == index {
+			return
+		}
+		if len(known) == 0 {
+			addrs = append(addrs, addr)
+		}
+		entries[addr] = append(known, AddressTx{number, index})
+	}
+	for i, tx := range block.Transactions() {
+		from, err := tx.From()
+		if err != nil {
+			glog.V(logger.Debug).Infof("address index: no sender for tx %x: %v\n", tx.Hash(), err)
+			continue
+		}
+		add(from, uint64(i))
+		if to := tx.To(); to != nil {
+			add(*to, uint64(i))
+		} else {
+			add(crypto.CreateAddress(from, tx.Nonce()), uint64(i))
+		}
+		for _, transfer := range transfers {
+			if transfer.TxIndex == uint(i) {
+				add(transfer.From, uint64(i))
+				add(transfer.To, uint64(i))
+			}
+		}
+	}
+
+	for _, addr := range addrs {
+		count := self.count(addr)
+		for _, entry := range entries[addr] {
+			enc, _ := rlp.EncodeToBytes(entry)
+			self.db.Put(addrTxKey(addr, count), enc)
+			count++
+		}
+		self.db.Put(addrCountKey(addr), encodeUint64(count))
+	}
+	enc, _ := rlp.EncodeToBytes(addrs)
+	self.db.Put(addrBlockKey(number), enc)
+	self.db.Put(addrNextKey, encodeUint64(number+1))
+}
+
+// Unwind drops the entries of the blocks numbered from and above.
+func (self *AddressIndex) Unwind(from uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.unwind(from)
+}
+
+func (self *AddressIndex) unwind(from uint64) {
+	next := self.next()
+	if next <= from {
+		return
+	}
+	for number := next; number > from; number-- {
+		key := addrBlockKey(number - 1)
+		data, _ := self.db.Get(key)
+		if len(data) == 0 {
+			continue
+		}
+		var addrs []common.Address
+		if err := rlp.DecodeBytes(data, &addrs); err != nil {
+			glog.V(logger.Error).Infof("address index: invalid entry of block #%d: %v\n", number-1, err)
+		}
+		for _, addr := range addrs {
+			count := self.count(addr)
+			for count > 0 {
+				entry, ok := self.entry(addr, count-1)
+				if ok && entry.Number < number-1 {
+					break
+				}
+				count--
+				self.db.Delete(addrTxKey(addr, count))
+			}
+			self.db.Put(addrCountKey(addr), encodeUint64(count))
+		}
+		self.db.Delete(key)
+	}
+	self.db.Put(addrNextKey, encodeUint64(from))
+}
+
+func (self *AddressIndex) next() uint64 {
+	data, _ := self.db.Get(addrNextKey)
+	return decodeUint64(data)
+}
+
+func (self *AddressIndex) count(addr common.Address) uint64 {
+	data, _ := self.db.Get(addrCountKey(addr))
+	return decodeUint64(data)
+}
+
+func (self *AddressIndex) entry(addr common.Address, seq uint64) (AddressTx, bool) {
+	var entry AddressTx
+	data, _ := self.db.Get(addrTxKey(addr, seq))
+	if len(data) == 0 || rlp.DecodeBytes(data, &entry) != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+func addrTxKey(addr common.Address, seq uint64) []byte {
+	return append(append(append([]byte{}, addrTxPre...), addr.Bytes()...), encodeUint64(seq)...)
+}
+
+func addrCountKey(addr common.Address) []byte {
+	return append(append([]byte{}, addrTxCountPre...), addr.Bytes()...)
+}
+
+func addrBlockKey(number uint64) []byte {
+	return append(append([]byte{}, addrBlockPre...), encodeUint64(number)...)
+}
+
+func encodeUint64(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+func decodeUint64(data []byte) uint64 {
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// SetAddressIndex enables indexing the transactions of canonical blocks by
+// address. Blocks which are canonical already are only indexed by
+// RebuildAddressIndex.
+func (self *ChainManager) SetAddressIndex(index *AddressIndex) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.addrIndex = index
+}
+
+// AddressIndex returns the address index, nil if it isn't enabled.
+func (self *ChainManager) AddressIndex() *AddressIndex {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return self.addrIndex
+}
+
+// RebuildAddressIndex drops the address index and indexes all canonical
+// blocks again. progress, if not nil, is called with the number of each
+// indexed block.
+func (self *ChainManager) RebuildAddressIndex(progress func(uint64)) error {
+	index := self.AddressIndex()
+	if index == nil {
+		return fmt.Errorf("address index not enabled")
+	}
+	index.Unwind(0)
+
+	head := self.CurrentBlock().NumberU64()
+	for number := uint64(0); number <= head; number++ {
+		block := self.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		if err := self.indexAddresses(index, block); err != nil {
+			return fmt.Errorf("block #%d: %v", number, err)
+		}
+		if progress != nil {
+			progress(number)
+		}
+	}
+	return nil
+}
+
+// recordedTransfers returns the internal transfers recorded when block was
+// processed. Unlike indexAddresses it never executes the block and may be
+// called with the chain lock held.
+func (self *ChainManager) recordedTransfers(block *types.Block) []*state.Transfer {
+	if proc, ok := self.processor.(transferProcessor); ok && len(block.Transactions()) > 0 {
+		if transfers, ok := proc.RecordedTransfers(block); ok {
+			return transfers
+		}
+		glog.V(logger.Debug).Infof("address index: no transfers recorded for block #%v\n", block.Number())
+	}
+	return nil
+}
+
+// indexAddresses adds block to the address index. It must not be called with
+// the chain lock held as recovering internal transfers may execute the block.
+func (self *ChainManager) indexAddresses(index *AddressIndex, block *types.Block) error {
+	var transfers []*state.Transfer
+	if len(block.Transactions()) > 0 {
+		if proc, ok := self.processor.(transferProcessor); ok {
+			var err error
+			if transfers, err = proc.Transfers(block); err != nil {
+				return err
+			}
+		}
+	}
+	index.add(block, transfers)
+	return nil
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestAddressIndex(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+	chain := makeTxChain(bp, db, 3, 2)
+	fork := makeChain(bp, bp.bc.Genesis(), 4, db, ForkSeed)
+
+	db, _ = ethdb.NewMemDatabase()
+	bp, _ = newCanonical(0, db)
+	index := NewAddressIndex(db)
+	bp.bc.SetAddressIndex(index)
+	if _, err := bp.bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.BytesToAddress([]byte{0xaa})
+	if n := index.Count(to); n != 6 {
+		t.Fatalf("count mismatch: have %d, want 6", n)
+	}
+	from, _ := chain[0].Transactions()[0].From()
+	if n := index.Count(from); n != 6 {
+		t.Errorf("sender count mismatch: have %d, want 6", n)
+	}
+	if txs, want := index.Transactions(to, 0, 2), []AddressTx{{3, 1}, {3, 0}}; !reflect.DeepEqual(txs, want) {
+		t.Errorf("first page mismatch: have %v, want %v", txs, want)
+	}
+	if txs, want := index.Transactions(to, 5, 2), []AddressTx{{1, 0}}; !reflect.DeepEqual(txs, want) {
+		t.Errorf("last page mismatch: have %v, want %v", txs, want)
+	}
+	if txs := index.Transactions(to, 6, 2); len(txs) != 0 {
+		t.Errorf("expected empty page past the end, got %v", txs)
+	}
+
+	// rebuilding gives the same index, blocks without recorded transfers
+	// are executed again
+	db.Delete(append(blockTransfersPre, chain[0].Hash().Bytes()...))
+	if err := bp.bc.RebuildAddressIndex(nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := index.Count(to); n != 6 {
+		t.Errorf("count after rebuild mismatch: have %d, want 6", n)
+	}
+	if next := index.Next(); next != 4 {
+		t.Errorf("next indexed block after rebuild mismatch: have %d, want 4", next)
+	}
+
+	// the longer fork has no transactions, all entries are unwound
+	if _, err := bp.bc.InsertChain(fork); err != nil {
+		t.Fatal(err)
+	}
+	if head := bp.bc.CurrentBlock(); head.Hash() != fork[3].Hash() {
+		t.Fatalf("fork not canonical, head #%v", head.Number())
+	}
+	if n := index.Count(to); n != 0 {
+		t.Errorf("count after reorg mismatch: have %d, want 0", n)
+	}
+	if next := index.Next(); next != 5 {
+		t.Errorf("next indexed block mismatch: have %d, want 5", next)
+	}
+}
+
+func TestTransfersNotRecordedWithoutIndex(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+	chain := makeTxChain(bp, db, 1, 1)
+
+	db, _ = ethdb.NewMemDatabase()
+	bp, _ = newCanonical(0, db)
+	if _, err := bp.bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bp.RecordedTransfers(chain[0]); ok {
+		t.Errorf("transfers recorded with the address index disabled")
+	}
+}
+
+func TestInternalTransfers(t *testing.T) {
+	var (
+		contract = common.BytesToAddress([]byte{0xaa})
+		receiver = common.BytesToAddress([]byte{0xbb})
+		// call(100, 0xbb, 1, 0, 0, 0, 0)
+		call = "6000600060006000600160bb6064f1"
+	)
+	tests := []struct {
+		code      string
+		transfers int
+	}{
+		{call + "00", 1},
+		{call + "600056", 0}, // jump to an invalid destination reverts the call
+	}
+	for i, test := range tests {
+		db, _ := ethdb.NewMemDatabase()
+		statedb := state.New(common.Hash{}, db)
+		statedb.RecordTransfers()
+		statedb.GetOrNewStateObject(contract).SetCode(common.Hex2Bytes(test.code))
+		sender := statedb.GetOrNewStateObject(common.BytesToAddress([]byte{0xcc}))
+		sender.AddBalance(big.NewInt(10))
+		statedb.StartRecord(common.Hash{1}, common.Hash{}, 0)
+
+		block := types.NewBlock(common.Hash{}, common.Address{}, common.Hash{}, common.Big1, 0, nil)
+		tx := types.NewTransactionMessage(contract, big.NewInt(5), big.NewInt(100000), common.Big0, nil)
+		env := NewEnv(statedb, nil, tx, block)
+		env.Call(sender, contract, nil, big.NewInt(100000), common.Big0, big.NewInt(5))
+
+		transfers := statedb.GetTransfers(common.Hash{1})
+		if len(transfers) != test.transfers {
+			t.Errorf("test %d: transfer count mismatch: have %d, want %d", i, len(transfers), test.transfers)
+			continue
+		}
+		if test.transfers > 0 {
+			if tr := transfers[0]; tr.From != contract || tr.To != receiver || tr.Value.Cmp(common.Big1) != 0 {
+				t.Errorf("test %d: transfer mismatch: %+v", i, tr)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	badBlocksKey = []byte("bad-blocks") // hashes of the stored bad blocks, oldest first
+	badBlockPre  = []byte("bad-block-")
+)
+
+// badBlockLimit is the number of rejected blocks kept, older ones are
+// dropped first.
+const badBlockLimit = 64
+
+// BadBlock is a block which was rejected by InsertChain.
+type BadBlock struct {
+	Hash   common.Hash
+	Number *big.Int
+	RLP    []byte // network encoding of the block
+	Error  string
+	Peer   string // id of the peer the block came from, empty for local blocks
+	Time   time.Time
+}
+
+// badBlockEntry is the stored encoding of a BadBlock.
+type badBlockEntry struct {
+	RLP   []byte
+	Error string
+	Peer  string
+	Time  uint64
+}
+
+// Block decodes the rejected block.
+func (self *BadBlock) Block() (*types.Block, error) {
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(self.RLP, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// BadBlocks returns the recently rejected blocks, most recent first.
+func (self *ChainManager) BadBlocks() []*BadBlock {
+	self.badMu.Lock()
+	defer self.badMu.Unlock()
+
+	hashes := self.badBlockHashes()
+	blocks := make([]*BadBlock, 0, len(hashes))
+	for i := len(hashes) - 1; i >= 0; i-- {
+		data, _ := self.blockDb.Get(append(badBlockPre, hashes[i][:]...))
+		if len(data) == 0 {
+			continue
+		}
+		var entry badBlockEntry
+		if err := rlp.DecodeBytes(data, &entry); err != nil {
+			glog.V(logger.Error).Infoln("invalid bad block entry:", err)
+			continue
+		}
+		var block types.Block
+		if err := rlp.DecodeBytes(entry.RLP, &block); err != nil {
+			glog.V(logger.Error).Infoln("invalid bad block RLP:", err)
+			continue
+		}
+		blocks = append(blocks, &BadBlock{
+			Hash:   block.Hash(),
+			Number: block.Number(),
+			RLP:    entry.RLP,
+			Error:  entry.Error,
+			Peer:   entry.Peer,
+			Time:   time.Unix(int64(entry.Time), 0),
+		})
+	}
+	return blocks
+}
+
+// addBadBlock stores block along with the error it was rejected with. A
+// block rejected again replaces its earlier entry. Blocks failing the proof
+// of work aren't stored, they cost nothing to produce, and neither are blocks
+// with an unknown parent, they aren't invalid and can't be replayed.
+func (self *ChainManager) addBadBlock(block *types.Block, err error) {
+	if IsPowErr(err) || IsParentErr(err) {
+		return
+	}
+	data, encErr := rlp.EncodeToBytes(block)
+	if encErr != nil {
+		glog.V(logger.Error).Infoln("failed to encode bad block:", encErr)
+		return
+	}
+	entry, encErr := rlp.EncodeToBytes(badBlockEntry{
+		RLP:   data,
+		Error: err.Error(),
+		Peer:  block.ReceivedFrom,
+		Time:  uint64(time.Now().Unix()),
+	})
+	if encErr != nil {
+		glog.V(logger.Error).Infoln("failed to encode bad block entry:", encErr)
+		return
+	}
+
+	self.badMu.Lock()
+	defer self.badMu.Unlock()
+
+	hash := block.Hash()
+	hashes := self.badBlockHashes()
+	for i := 0; i < len(hashes); i++ {
+		if hashes[i] == hash {
+			hashes = append(hashes[:i], hashes[i+1:]...)
+			i--
+		}
+	}
+	hashes = append(hashes, hash)
+	if len(hashes) > badBlockLimit {
+		for _, dropped := range hashes[:len(hashes)-badBlockLimit] {
+			self.blockDb.Delete(append(badBlockPre, dropped[:]...))
+		}
+		hashes = hashes[len(hashes)-badBlockLimit:]
+	}
+
+	index, encErr := rlp.EncodeToBytes(hashes)
+	if encErr != nil {
+		glog.V(logger.Error).Infoln("failed to encode bad block index:", encErr)
+		return
+	}
+	self.blockDb.Put(append(badBlockPre, hash[:]...), entry)
+	self.blockDb.Put(badBlocksKey, index)
+}
+
+// badBlockHashes loads the index of the stored bad blocks, oldest first.
+func (self *ChainManager) badBlockHashes() []common.Hash {
+	data, _ := self.blockDb.Get(badBlocksKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(data, &hashes); err != nil {
+		glog.V(logger.Error).Infoln("invalid bad block index:", err)
+		return nil
+	}
+	return hashes
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestBadBlockStore(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := bp.bc
+
+	block := newBlockFromParent(common.Address{0x01}, chain.Genesis())
+	block.SetRoot(common.Hash{0x01}) // bogus state root
+	block.ReceivedFrom = "peer"
+	if _, err := chain.InsertChain(types.Blocks{block}); err == nil {
+		t.Fatal("expected insertion to fail")
+	}
+	bad := chain.BadBlocks()
+	if len(bad) != 1 {
+		t.Fatalf("bad block count mismatch: have %d, want 1", len(bad))
+	}
+	if bad[0].Hash != block.Hash() || bad[0].Peer != "peer" || bad[0].Error == "" {
+		t.Errorf("bad block mismatch: %+v", bad[0])
+	}
+	decoded, err := bad[0].Block()
+	if err != nil || decoded.Hash() != block.Hash() {
+		t.Errorf("stored RLP doesn't decode to the block: %v", err)
+	}
+
+	// rejecting the same block again doesn't duplicate it
+	chain.InsertChain(types.Blocks{block})
+	if len(chain.BadBlocks()) != 1 {
+		t.Errorf("bad block stored twice")
+	}
+}
+
+func TestBadBlockLimit(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := bp.bc
+
+	var first, last *types.Block
+	for i := 0; i < badBlockLimit+10; i++ {
+		last = newBlockFromParent(common.Address{byte(i)}, chain.Genesis())
+		chain.addBadBlock(last, ValidationError("test"))
+		if first == nil {
+			first = last
+		}
+	}
+	bad := chain.BadBlocks()
+	if len(bad) != badBlockLimit {
+		t.Fatalf("bad block count mismatch: have %d, want %d", len(bad), badBlockLimit)
+	}
+	if bad[0].Hash != last.Hash() {
+		t.Errorf("most recent bad block not first")
+	}
+	if data, _ := db.Get(append(badBlockPre, first.Hash().Bytes()...)); data != nil {
+		t.Errorf("dropped bad block still stored")
+	}
+}
+
+func TestBadBlockPow(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := bp.bc
+
+	block := newBlockFromParent(common.Address{0x01}, chain.Genesis())
+	chain.addBadBlock(block, PowError(block.Header().Nonce))
+	if len(chain.BadBlocks()) != 0 {
+		t.Errorf("block failing the proof of work was stored")
+	}
+}
+
+func TestBadBlockOrphan(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := bp.bc
+
+	parent := newBlockFromParent(common.Address{0x01}, chain.Genesis())
+	orphan := newBlockFromParent(common.Address{0x02}, parent)
+	if _, err := chain.InsertChain(types.Blocks{orphan}); !IsParentErr(err) {
+		t.Fatalf("expected parent error, got %v", err)
+	}
+	if bad := chain.BadBlocks(); len(bad) != 0 {
+		t.Errorf("orphan block was stored: %+v", bad[0])
+	}
+}
@@ -37,6 +37,12 @@ type BlockProcessor struct {
 	mem map[string]*big.Int
 	// Proof of work used for validating
 	Pow pow.PoW
+	// Hashes of the blocks whose seal was checked by VerifyBlocks
+	verified   map[common.Hash]bool
+	verifiedMu sync.Mutex
+	// Storage writes of the processed blocks
+	storageStats StorageStats
+	statsMu      sync.Mutex
 
 	txpool *TxPool
 
@@ -56,6 +62,7 @@ func NewBlockProcessor(db, extra common.Database, pow pow.PoW, txpool *TxPool, c
 		extraDb:  extra,
 		mem:      make(map[string]*big.Int),
 		Pow:      pow,
+		verified: make(map[common.Hash]bool),
 		bc:       chainManager,
 		eventMux: eventMux,
 		txpool:   txpool,
@@ -81,9 +88,11 @@ func (self *BlockProcessor) ApplyTransaction(coinbase *state.StateObject, stated
 	// If we are mining this block and validating we want to set the logs back to 0
 	//statedb.EmptyLogs()
 
+	snapshot := statedb.Snapshot()
 	cb := statedb.GetStateObject(coinbase.Address())
 	_, gas, err := ApplyMessage(NewEnv(statedb, self.bc, tx, block), tx, cb)
 	if err != nil && (IsNonceErr(err) || state.IsGasLimitErr(err) || IsInvalidTxErr(err)) {
+		statedb.RevertToSnapshot(snapshot)
 		// If the account is managed, remove the invalid nonce.
 		//from, _ := tx.From()
 		//self.bc.TxState().RemoveNonce(from, tx.Nonce())
@@ -190,8 +199,14 @@ func (sm *BlockProcessor) processWithParent(block, parent *types.Block) (logs st
 	// Create a new state based on the parent's root (e.g., create copy)
 	state := state.New(parent.Root(), sm.db)
 
-	// Block validation
-	if err = sm.ValidateHeader(block.Header(), parent.Header()); err != nil {
+	// Internal value transfers are only needed by the address index
+	indexed := sm.bc.AddressIndex() != nil
+	if indexed {
+		state.RecordTransfers()
+	}
+
+	// Block validation, the seal may have been verified ahead by VerifyBlocks
+	if err = sm.validateHeader(block.Header(), parent.Header(), !sm.sealVerified(block.Hash())); err != nil {
 		return
 	}
 
@@ -257,6 +272,12 @@ func (sm *BlockProcessor) processWithParent(block, parent *types.Block) (logs st
 	// Sync the current block's state to the database
 	state.Sync()
 
+	written, skipped := state.StorageWrites()
+	sm.addStorageWrites(header.Number.Uint64(), written, skipped)
+	if glog.V(logger.Debug) {
+		glog.Infof("block #%v: %d storage writes (%d unchanged skipped)\n", header.Number, written, skipped)
+	}
+
 	// Remove transactions from the pool
 	sm.txpool.RemoveTransactions(block.Transactions())
 
@@ -264,6 +285,10 @@ func (sm *BlockProcessor) processWithParent(block, parent *types.Block) (logs st
 	for i, tx := range block.Transactions() {
 		putTx(sm.extraDb, tx, block, uint64(i))
 	}
+	putReceipts(sm.extraDb, block, receipts)
+	if indexed {
+		putTransfers(sm.extraDb, block, blockTransfers(state, block))
+	}
 
 	return state.Logs(), nil
 }
@@ -272,6 +297,10 @@ func (sm *BlockProcessor) processWithParent(block, parent *types.Block) (logs st
 // an uncle or anything that isn't on the current block chain.
 // Validation validates easy over difficult (dagger takes longer time = difficult)
 func (sm *BlockProcessor) ValidateHeader(block, parent *types.Header) error {
+	return sm.validateHeader(block, parent, true)
+}
+
+func (sm *BlockProcessor) validateHeader(block, parent *types.Header, checkPow bool) error {
 	if big.NewInt(int64(len(block.Extra))).Cmp(params.MaximumExtraDataSize) == 1 {
 		return fmt.Errorf("Block extra data too long (%d)", len(block.Extra))
 	}
@@ -303,8 +332,8 @@ func (sm *BlockProcessor) ValidateHeader(block, parent *types.Header) error {
 	}
 
 	// Verify the nonce of the block. Return an error if it's not valid
-	if !sm.Pow.Verify(types.NewBlockWithHeader(block)) {
-		return ValidationError("Block's nonce is invalid (= %x)", block.Nonce)
+	if checkPow && !sm.Pow.Verify(types.NewBlockWithHeader(block)) {
+		return PowError(block.Nonce)
 	}
 
 	return nil
@@ -385,6 +414,56 @@ func (sm *BlockProcessor) GetLogs(block *types.Block) (logs state.Logs, err erro
 	return state.Logs(), nil
 }
 
+// BlockLogs returns the logs of block from the receipts stored when it was
+// processed.
+func (sm *BlockProcessor) BlockLogs(block *types.Block) state.Logs {
+	var (
+		logs  state.Logs
+		txs   = block.Transactions()
+		index uint
+	)
+	for i, receipt := range GetBlockReceipts(sm.extraDb, block.Hash()) {
+		for _, log := range receipt.Logs() {
+			log.Number = block.NumberU64()
+			log.BlockHash = block.Hash()
+			if i < len(txs) {
+				log.TxHash = txs[i].Hash()
+			}
+			log.TxIndex, log.Index = uint(i), index
+			index++
+
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+var receiptsPre = []byte("receipts-")
+
+// GetBlockReceipts returns the receipts stored for the block with the given
+// hash, nil if there are none.
+func GetBlockReceipts(db common.Database, hash common.Hash) types.Receipts {
+	data, _ := db.Get(append(receiptsPre, hash[:]...))
+	if len(data) == 0 {
+		return nil
+	}
+	var receipts types.Receipts
+	if err := rlp.DecodeBytes(data, &receipts); err != nil {
+		glog.V(logger.Debug).Infoln("Failed decoding receipts", err)
+		return nil
+	}
+	return receipts
+}
+
+func putReceipts(db common.Database, block *types.Block, receipts types.Receipts) {
+	enc, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		glog.V(logger.Debug).Infoln("Failed encoding receipts", err)
+		return
+	}
+	db.Put(append(receiptsPre, block.Hash().Bytes()...), enc)
+}
+
 func putTx(db common.Database, tx *types.Transaction, block *types.Block, i uint64) {
 	rlpEnc, err := rlp.EncodeToBytes(tx)
 	if err != nil {
@@ -408,3 +487,35 @@ func putTx(db common.Database, tx *types.Transaction, block *types.Block, i uint
 	}
 	db.Put(append(tx.Hash().Bytes(), 0x0001), rlpMeta)
 }
+
+// StorageStats counts the storage slots written to the state database by the
+// blocks processed since the node started.
+type StorageStats struct {
+	Blocks  uint64 `json:"blocks"`
+	Written uint64 `json:"written"` // slots written to storage tries
+	Skipped uint64 `json:"skipped"` // modified slots restored to their original value
+
+	// Storage writes of the last processed block
+	LastBlock   uint64 `json:"lastBlock"`
+	LastWritten uint64 `json:"lastWritten"`
+	LastSkipped uint64 `json:"lastSkipped"`
+}
+
+func (sm *BlockProcessor) addStorageWrites(number uint64, written, skipped int) {
+	sm.statsMu.Lock()
+	defer sm.statsMu.Unlock()
+
+	stats := &sm.storageStats
+	stats.Blocks++
+	stats.Written += uint64(written)
+	stats.Skipped += uint64(skipped)
+	stats.LastBlock, stats.LastWritten, stats.LastSkipped = number, uint64(written), uint64(skipped)
+}
+
+// StorageStats returns the storage writes of the processed blocks.
+func (sm *BlockProcessor) StorageStats() StorageStats {
+	sm.statsMu.Lock()
+	defer sm.statsMu.Unlock()
+
+	return sm.storageStats
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/pow/ezp"
@@ -35,3 +37,26 @@ func TestNumber(t *testing.T) {
 		t.Errorf("didn't expect block number error")
 	}
 }
+
+func TestStorageStats(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+
+	// sstore(0, 1); sstore(1, 2); sstore(1, 0)
+	key, _ := crypto.GenerateKey()
+	tx := types.NewContractCreationTx(common.Big0, big.NewInt(100000), common.Big0, common.Hex2Bytes("600160005560026001556000600155"))
+	tx.SignECDSA(key)
+	genesis := bp.bc.Genesis()
+	block := newBlockFromParent(common.BytesToAddress([]byte{0xcb}), genesis)
+	finishTxBlock(bp, db, block, genesis, types.Transactions{tx})
+
+	db, _ = ethdb.NewMemDatabase()
+	bp, _ = newCanonical(0, db)
+	if _, err := bp.bc.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatal(err)
+	}
+	want := StorageStats{Blocks: 1, Written: 1, Skipped: 1, LastBlock: 1, LastWritten: 1, LastSkipped: 1}
+	if stats := bp.StorageStats(); stats != want {
+		t.Errorf("stats mismatch: have %+v, want %+v", stats, want)
+	}
+}
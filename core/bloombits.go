@@ -0,0 +1,254 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+)
+
+const (
+	// BloomBitsSection is the number of blocks whose header blooms are
+	// grouped into one section of the bloom-bits index.
+	BloomBitsSection = 4096
+
+	// bloomBitsConfirms is the number of blocks a section must be below the
+	// head before it is indexed, so that short reorgs don't invalidate it.
+	bloomBitsConfirms = 16
+
+	bloomBitLength = 2048 // number of bits in a header bloom
+)
+
+var (
+	bloomBitsPre      = []byte("bloombits-")      // bit + section -> vector
+	bloomBitsHeadPre  = []byte("bloombits-head-") // section -> hash of its last block
+	bloomBitsCountKey = []byte("bloombits-count")
+)
+
+// BloomIndexer maintains the bloom-bits index: for every section of
+// canonical blocks it stores one vector per bloom bit, holding a bit for
+// each block of the section which has the bloom bit set. Looking up a value
+// thus takes three vectors per section instead of a header per block.
+//
+// Sections are only indexed once complete. A reorg replacing any of their
+// blocks changes the hash of their last block, which invalidates them until
+// they are indexed again.
+type BloomIndexer struct {
+	db    common.Database
+	chain *ChainManager
+	mux   *event.TypeMux
+	size  uint64
+
+	mu       sync.RWMutex
+	sections uint64 // number of indexed sections
+
+	events event.Subscription
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewBloomIndexer(db common.Database, chain *ChainManager, mux *event.TypeMux, sectionSize uint64) *BloomIndexer {
+	data, _ := db.Get(bloomBitsCountKey)
+	return &BloomIndexer{
+		db:       db,
+		chain:    chain,
+		mux:      mux,
+		size:     sectionSize,
+		sections: decodeUint64(data),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start indexes the sections completed so far in the background and keeps up
+// with the chain from then on.
+func (self *BloomIndexer) Start() {
+	self.events = self.mux.Subscribe(ChainHeadEvent{})
+	self.wg.Add(1)
+	go self.loop()
+}
+
+func (self *BloomIndexer) Stop() {
+	close(self.quit)
+	if self.events != nil {
+		self.events.Unsubscribe()
+	}
+	self.wg.Wait()
+}
+
+func (self *BloomIndexer) loop() {
+	defer self.wg.Done()
+
+	self.Update()
+	for {
+		select {
+		case _, ok := <-self.events.Chan():
+			if !ok {
+				return
+			}
+			self.Update()
+		case <-self.quit:
+			return
+		}
+	}
+}
+
+// Update drops the sections which are no longer canonical and indexes the
+// ones completed since the last update.
+func (self *BloomIndexer) Update() {
+	self.mu.Lock()
+	for self.sections > 0 && !self.valid(self.sections-1) {
+		self.sections--
+		glog.V(logger.Debug).Infof("bloom-bits section %d invalidated by reorg\n", self.sections)
+	}
+	self.db.Put(bloomBitsCountKey, encodeUint64(self.sections))
+	next := self.sections
+	self.mu.Unlock()
+
+	for ; (next+1)*self.size-1+bloomBitsConfirms <= self.chain.CurrentBlock().NumberU64(); next++ {
+		select {
+		case <-self.quit:
+			return
+		default:
+		}
+		head, ok := self.index(next)
+		if !ok {
+			return
+		}
+
+		self.mu.Lock()
+		self.db.Put(bloomBitsHeadKey(next), head.Bytes())
+		self.sections = next + 1
+		self.db.Put(bloomBitsCountKey, encodeUint64(self.sections))
+		self.mu.Unlock()
+
+		glog.V(logger.Debug).Infof("bloom-bits section %d indexed\n", next)
+	}
+}
+
+// index writes the vectors of section and returns the hash of its last
+// block. It fails if the chain changed while indexing.
+func (self *BloomIndexer) index(section uint64) (common.Hash, bool) {
+	vectors := make([][]byte, bloomBitLength)
+	for bit := range vectors {
+		vectors[bit] = make([]byte, self.size/8)
+	}
+
+	var (
+		start = section * self.size
+		last  common.Hash
+	)
+	for i := uint64(0); i < self.size; i++ {
+		block := self.chain.GetBlockByNumber(start + i)
+		if block == nil || (i > 0 && block.ParentHash() != last) {
+			return common.Hash{}, false
+		}
+		last = block.Hash()
+
+		bloom := block.Bloom()
+		for j, b := range bloom {
+			for k := uint(0); b != 0; k, b = k+1, b>>1 {
+				if b&1 != 0 {
+					bit := (len(bloom)-1-j)*8 + int(k)
+					vectors[bit][i/8] |= 1 << (7 - i%8)
+				}
+			}
+		}
+	}
+
+	for bit, vector := range vectors {
+		key := bloomBitsKey(uint(bit), section)
+		if isZero(vector) {
+			// vectors of an earlier indexing may be left
+			self.db.Delete(key)
+		} else {
+			self.db.Put(key, vector)
+		}
+	}
+	return last, true
+}
+
+// valid reports whether section was indexed from the current canonical chain.
+func (self *BloomIndexer) valid(section uint64) bool {
+	data, _ := self.db.Get(bloomBitsHeadKey(section))
+	block := self.chain.GetBlockByNumber((section+1)*self.size - 1)
+	return block != nil && len(data) > 0 && common.BytesToHash(data) == block.Hash()
+}
+
+// SectionSize returns the number of blocks in a section.
+func (self *BloomIndexer) SectionSize() uint64 {
+	return self.size
+}
+
+// Sections returns the number of indexed sections.
+func (self *BloomIndexer) Sections() uint64 {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return self.sections
+}
+
+// Valid reports whether section is indexed and still canonical.
+func (self *BloomIndexer) Valid(section uint64) bool {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return section < self.sections && self.valid(section)
+}
+
+// Vector returns the bit vector of the given bloom bit in section.
+func (self *BloomIndexer) Vector(bit uint, section uint64) []byte {
+	data, _ := self.db.Get(bloomBitsKey(bit, section))
+	if len(data) == 0 {
+		return make([]byte, self.size/8)
+	}
+	return data
+}
+
+func bloomBitsKey(bit uint, section uint64) []byte {
+	key := append(append([]byte{}, bloomBitsPre...), byte(bit>>8), byte(bit))
+	return append(key, encodeUint64(section)...)
+}
+
+func bloomBitsHeadKey(section uint64) []byte {
+	return append(append([]byte{}, bloomBitsHeadPre...), encodeUint64(section)...)
+}
+
+// bloomBits returns the positions of the bloom bits set for data, numbered
+// from the least significant bit of the bloom as in types.BloomLookup.
+func bloomBits(data []byte) [3]uint {
+	hash := crypto.Sha3(data)
+
+	var bits [3]uint
+	for i := 0; i < 6; i += 2 {
+		bits[i/2] = (uint(hash[i+1]) + uint(hash[i])<<8) & (bloomBitLength - 1)
+	}
+	return bits
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetBloomIndexer makes log filters use the given bloom-bits index.
+func (self *ChainManager) SetBloomIndexer(indexer *BloomIndexer) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.bloomIndexer = indexer
+}
+
+// BloomIndexer returns the bloom-bits index, nil if there is none.
+func (self *ChainManager) BloomIndexer() *BloomIndexer {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	return self.bloomIndexer
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// bloomChain makes a chain of n blocks, those in logged having a log of addr
+// in their bloom.
+func bloomChain(genesis *types.Block, n int, difficulty int, seed byte, addr common.Address, logged ...int) []*types.Block {
+	d := make([]int, n)
+	for i := range d {
+		d[i] = difficulty
+	}
+	chain := makeChainWithDiff(genesis, d, seed)
+	for _, i := range logged {
+		chain[i-1].Header().Bloom = types.BytesToBloom(types.LogsBloom(state.Logs{state.NewLog(addr, nil, nil, 0)}).Bytes())
+	}
+	return chain
+}
+
+func TestBloomBitsIndex(t *testing.T) {
+	var (
+		addr  = common.BytesToAddress([]byte{0xaa})
+		other = common.BytesToAddress([]byte{0xbb})
+	)
+	db, _ := ethdb.NewMemDatabase()
+	genesis := GenesisBlock(db)
+	bc := chm(genesis, db)
+	bc.InsertChain(bloomChain(genesis, 40, 1, 10, addr, 3, 12, 21, 38))
+
+	indexer := NewBloomIndexer(db, bc, bc.eventMux, 8)
+	indexer.Update()
+	// sections are indexed 16 blocks below the head
+	if n := indexer.Sections(); n != 3 {
+		t.Fatalf("section count mismatch: have %d, want 3", n)
+	}
+
+	filter := NewFilter(nil)
+	filter.SetAddress([]common.Address{addr})
+	want := map[uint64][]byte{
+		0: {0x10}, // block 3
+		1: {0x08}, // block 12
+		2: {0x04}, // block 21
+	}
+	for section, bits := range want {
+		if matches := filter.sectionMatches(indexer, section); matches[0] != bits[0] {
+			t.Errorf("section %d matches mismatch: have %08b, want %08b", section, matches[0], bits[0])
+		}
+	}
+
+	filter.SetAddress([]common.Address{other})
+	if matches := filter.sectionMatches(indexer, 0); matches[0] != 0 {
+		t.Errorf("unexpected matches for other address: %08b", matches[0])
+	}
+	filter.SetAddress(nil)
+	filter.SetTopics([][]common.Hash{{common.Hash{}}})
+	if matches := filter.sectionMatches(indexer, 0); matches[0] != 0xff {
+		t.Errorf("wildcard doesn't match all blocks: %08b", matches[0])
+	}
+
+	// a heavier chain replacing blocks from the first section onwards
+	// invalidates all sections until they are indexed again
+	bc.InsertChain(bloomChain(genesis, 40, 2, 11, addr, 5))
+	if indexer.Valid(0) {
+		t.Fatalf("section 0 still valid after reorg")
+	}
+	indexer.Update()
+	if n := indexer.Sections(); n != 3 || !indexer.Valid(0) {
+		t.Fatalf("sections not indexed again after reorg: have %d", n)
+	}
+	filter.SetTopics(nil)
+	filter.SetAddress([]common.Address{addr})
+	if matches := filter.sectionMatches(indexer, 0); matches[0] != 0x04 {
+		t.Errorf("section 0 matches after reorg mismatch: have %08b, want %08b", matches[0], 0x04)
+	}
+	if matches := filter.sectionMatches(indexer, 1); matches[0] != 0 {
+		t.Errorf("stale vectors left in section 1: %08b", matches[0])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/pow"
 )
@@ -21,6 +22,28 @@ func (f FakePow) Verify(block pow.Block) bool { return true }
 func (f FakePow) GetHashrate() int64          { return 0 }
 func (f FakePow) Turbo(bool)                  {}
 
+// SlowPow accepts every block like FakePow, but hashes the header Rounds
+// times in Verify so that seal verification costs roughly as much as it does
+// with a real PoW. It is meant for benchmarking block import.
+type SlowPow struct{ Rounds int }
+
+// SlowPowRounds makes a SlowPow verification take about a millisecond, in the
+// order of a light ethash verification.
+const SlowPowRounds = 2500
+
+func (p SlowPow) Search(block pow.Block, stop <-chan struct{}) (uint64, []byte) {
+	return 0, nil
+}
+func (p SlowPow) Verify(block pow.Block) bool {
+	hash := block.HashNoNonce().Bytes()
+	for i := 0; i < p.Rounds; i++ {
+		hash = crypto.Sha3(hash)
+	}
+	return len(hash) > 0
+}
+func (p SlowPow) GetHashrate() int64 { return 0 }
+func (p SlowPow) Turbo(bool)         {}
+
 // So we can deterministically seed different blockchains
 var (
 	CanonicalSeed = 1
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/logger"
 	"github.com/ethereum/go-ethereum/logger/glog"
@@ -91,6 +92,13 @@ type ChainManager struct {
 	cache        *BlockCache
 	futureBlocks *BlockCache
 
+	badMu sync.Mutex // serialises updates of the bad block store
+
+	addrIndex    *AddressIndex // nil unless enabled
+	bloomIndexer *BloomIndexer // used by log filters if set
+
+	precompiled *vm.PrecompiledRegistry // nil for the frontier contracts
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
@@ -150,6 +158,10 @@ func (bc *ChainManager) SetHead(head *types.Block) {
 	bc.setTotalDifficulty(head.Td)
 	bc.insert(head)
 	bc.setLastState()
+
+	if bc.addrIndex != nil {
+		bc.addrIndex.Unwind(head.NumberU64() + 1)
+	}
 }
 
 func (self *ChainManager) Td() *big.Int {
@@ -189,6 +201,18 @@ func (self *ChainManager) SetProcessor(proc types.BlockProcessor) {
 	self.processor = proc
 }
 
+// SetPrecompiled sets the native contracts of the chain. It must be called
+// before any block or transaction is executed.
+func (self *ChainManager) SetPrecompiled(registry *vm.PrecompiledRegistry) {
+	self.precompiled = registry
+}
+
+// Precompiled returns the native contracts of the chain, nil if the frontier
+// contracts are used.
+func (self *ChainManager) Precompiled() *vm.PrecompiledRegistry {
+	return self.precompiled
+}
+
 func (self *ChainManager) State() *state.StateDB {
 	return state.New(self.CurrentBlock().Root(), self.stateDb)
 }
@@ -304,6 +328,10 @@ func (bc *ChainManager) Reset() {
 	bc.makeCache()
 
 	bc.setTotalDifficulty(common.Big("0"))
+
+	if bc.addrIndex != nil {
+		bc.addrIndex.Unwind(1)
+	}
 }
 
 func (bc *ChainManager) removeBlock(block *types.Block) {
@@ -326,17 +354,32 @@ func (bc *ChainManager) ResetWithGenesisBlock(gb *types.Block) {
 	bc.currentBlock = bc.genesisBlock
 	bc.makeCache()
 	bc.td = gb.Difficulty()
+
+	if bc.addrIndex != nil {
+		bc.addrIndex.Unwind(1)
+	}
 }
 
 // Export writes the active chain to the given writer.
 func (self *ChainManager) Export(w io.Writer) error {
+	return self.ExportN(w, 0, self.CurrentBlock().NumberU64())
+}
+
+// ExportN writes the canonical blocks first to last, both inclusive, to the
+// given writer.
+func (self *ChainManager) ExportN(w io.Writer, first, last uint64) error {
 	self.mu.RLock()
 	defer self.mu.RUnlock()
-	glog.V(logger.Info).Infof("exporting %v blocks...\n", self.currentBlock.Header().Number)
 
-	last := self.currentBlock.NumberU64()
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	if head := self.currentBlock.NumberU64(); last > head {
+		return fmt.Errorf("export failed: last (%d) is beyond the head (%d)", last, head)
+	}
+	glog.V(logger.Info).Infof("exporting %d blocks...\n", last-first+1)
 
-	for nr := uint64(0); nr <= last; nr++ {
+	for nr := first; nr <= last; nr++ {
 		block := self.GetBlockByNumber(nr)
 		if block == nil {
 			return fmt.Errorf("export failed on #%d: not found", nr)
@@ -363,6 +406,7 @@ func (bc *ChainManager) write(block *types.Block) {
 	enc, _ := rlp.EncodeToBytes((*types.StorageBlock)(block))
 	key := append(blockHashPre, block.Hash().Bytes()...)
 	bc.blockDb.Put(key, enc)
+	bc.indexHeight(block)
 	// Push block to cache
 	bc.cache.Push(block)
 }
@@ -514,15 +558,37 @@ func (self *ChainManager) InsertChain(chain types.Blocks) (int, error) {
 
 	// A queued approach to delivering events. This is generally faster than direct delivery and requires much less mutex acquiring.
 	var (
-		queue      = make([]interface{}, len(chain))
-		queueEvent = queueEvent{queue: queue}
+		queueEvent = queueEvent{queue: make([]interface{}, 0, len(chain))}
 		stats      struct{ queued, processed, ignored int }
 		tstart     = time.Now()
 	)
+	// Seals and senders are verified concurrently ahead of the sequential
+	// state transitions
+	var (
+		abort    = make(chan struct{})
+		verified <-chan error
+	)
+	defer close(abort)
+	if verifier, ok := self.processor.(blockVerifier); ok {
+		verified = verifier.VerifyBlocks(chain, abort)
+	}
+
 	for i, block := range chain {
+		var verifyErr error
+		if verified != nil {
+			verifyErr = <-verified
+		}
 		if block == nil {
 			continue
 		}
+		if verifyErr != nil && !self.HasBlock(block.Hash()) {
+			glog.V(logger.Error).Infof("INVALID block #%v (%x)\n", block.Number(), block.Hash().Bytes())
+			glog.V(logger.Error).Infoln(verifyErr)
+
+			self.addBadBlock(block, verifyErr)
+
+			return i, verifyErr
+		}
 		// Setting block.Td regardless of error (known for example) prevents errors down the line
 		// in the protocol handler
 		block.Td = new(big.Int).Set(CalculateTD(block, self.GetBlock(block.ParentHash())))
@@ -559,9 +625,13 @@ func (self *ChainManager) InsertChain(chain types.Blocks) (int, error) {
 			glog.V(logger.Error).Infoln(err)
 			glog.V(logger.Debug).Infoln(block)
 
+			self.addBadBlock(block, err)
+
 			return i, err
 		}
 
+		var canonical types.Blocks // blocks to add to the address index, in order
+
 		self.mu.Lock()
 		{
 			cblock := self.currentBlock
@@ -580,15 +650,26 @@ func (self *ChainManager) InsertChain(chain types.Blocks) (int, error) {
 						glog.Infof("Split detected. New head #%v (%x) TD=%v, was #%v (%x) TD=%v\n", block.Header().Number, hash[:4], block.Td, cblock.Header().Number, chash[:4], self.td)
 					}
 
+					// the transactions and logs of the old chain which aren't part of the new one are
+					// reverted ahead of the split
+					txs, removed := self.reorged(cblock, block)
+					if len(txs) > 0 {
+						queueEvent.queue = append(queueEvent.queue, RemovedTransactionEvent{txs})
+					}
+					if len(removed) > 0 {
+						queueEvent.queue = append(queueEvent.queue, RemovedLogsEvent{removed})
+					}
+
 					// during split we merge two different chains and create the new canonical chain
-					self.merge(previous, block)
+					canonical = self.merge(previous, block)
 
-					queue[i] = ChainSplitEvent{block, logs}
+					queueEvent.queue = append(queueEvent.queue, ChainSplitEvent{block, logs})
 					queueEvent.splitCount++
 				}
 
 				self.setTotalDifficulty(block.Td)
 				self.insert(block)
+				canonical = append(canonical, block)
 
 				jsonlogger.LogJson(&logger.EthChainNewHead{
 					BlockHash:     block.Hash().Hex(),
@@ -600,7 +681,7 @@ func (self *ChainManager) InsertChain(chain types.Blocks) (int, error) {
 				self.setTransState(state.New(block.Root(), self.stateDb))
 				self.txState.SetState(state.New(block.Root(), self.stateDb))
 
-				queue[i] = ChainEvent{block, logs}
+				queueEvent.queue = append(queueEvent.queue, ChainEvent{block, logs})
 				queueEvent.canonicalCount++
 
 				if glog.V(logger.Debug) {
@@ -611,10 +692,17 @@ func (self *ChainManager) InsertChain(chain types.Blocks) (int, error) {
 					glog.Infof("inserted forked block #%d (TD=%v) (%d TXs %d UNCs) (%x...)\n", block.Number(), block.Difficulty(), len(block.Transactions()), len(block.Uncles()), block.Hash().Bytes()[0:4])
 				}
 
-				queue[i] = ChainSideEvent{block, logs}
+				queueEvent.queue = append(queueEvent.queue, ChainSideEvent{block, logs})
 				queueEvent.sideCount++
 			}
 			self.futureBlocks.Delete(block.Hash())
+
+			// Index the blocks which became canonical while the chain can't change
+			if self.addrIndex != nil {
+				for _, block := range canonical {
+					self.addrIndex.add(block, self.recordedTransfers(block))
+				}
+			}
 		}
 		self.mu.Unlock()
 
@@ -657,14 +745,67 @@ func (self *ChainManager) diff(oldBlock, newBlock *types.Block) types.Blocks {
 	return newChain
 }
 
-// merge merges two different chain to the new canonical chain
-func (self *ChainManager) merge(oldBlock, newBlock *types.Block) {
+// merge merges two different chain to the new canonical chain and returns the
+// inserted blocks, lowest first.
+func (self *ChainManager) merge(oldBlock, newBlock *types.Block) types.Blocks {
 	newChain := self.diff(oldBlock, newBlock)
 
 	// insert blocks
-	for _, block := range newChain {
+	inserted := make(types.Blocks, len(newChain))
+	for i, block := range newChain {
 		self.insert(block)
+		inserted[len(newChain)-1-i] = block
+	}
+	return inserted
+}
+
+// reorged walks the chains of oldBlock and newBlock back to their common
+// ancestor and returns the transactions and logs of the old chain, which are
+// no longer canonical, oldest first. Transactions included again in the new
+// chain are left out.
+func (self *ChainManager) reorged(oldBlock, newBlock *types.Block) (txs types.Transactions, logs state.Logs) {
+	var oldChain, newChain types.Blocks
+	for oldBlock != nil && newBlock != nil && oldBlock.Hash() != newBlock.Hash() {
+		if oldBlock.NumberU64() >= newBlock.NumberU64() {
+			oldChain = append(oldChain, oldBlock)
+			oldBlock = self.GetBlock(oldBlock.ParentHash())
+		} else {
+			newChain = append(newChain, newBlock)
+			newBlock = self.GetBlock(newBlock.ParentHash())
+		}
+	}
+	if oldBlock == nil || newBlock == nil {
+		glog.V(logger.Error).Infoln("no common ancestor of reorganised chains")
+		return nil, nil
 	}
+
+	included := make(map[common.Hash]bool)
+	for _, block := range newChain {
+		for _, tx := range block.Transactions() {
+			included[tx.Hash()] = true
+		}
+	}
+	store, _ := self.processor.(logStore)
+	for i := len(oldChain) - 1; i >= 0; i-- {
+		block := oldChain[i]
+		for _, tx := range block.Transactions() {
+			if !included[tx.Hash()] {
+				txs = append(txs, tx)
+			}
+		}
+		if store != nil {
+			for _, log := range store.BlockLogs(block) {
+				logs = append(logs, log.RemovedCopy())
+			}
+		}
+	}
+	return txs, logs
+}
+
+// logStore is implemented by block processors which keep the logs of the
+// blocks they processed.
+type logStore interface {
+	BlockLogs(*types.Block) state.Logs
 }
 
 func (self *ChainManager) update() {
@@ -676,19 +817,22 @@ out:
 		case ev := <-events.Chan():
 			switch ev := ev.(type) {
 			case queueEvent:
-				for i, event := range ev.queue {
+				var canonical, split int
+				for _, event := range ev.queue {
 					switch event := event.(type) {
 					case ChainEvent:
 						// We need some control over the mining operation. Acquiring locks and waiting for the miner to create new block takes too long
 						// and in most cases isn't even necessary.
-						if i+1 == ev.canonicalCount {
+						canonical++
+						if canonical == ev.canonicalCount {
 							self.currentGasLimit = CalcGasLimit(event.Block)
 							self.eventMux.Post(ChainHeadEvent{event.Block})
 						}
 					case ChainSplitEvent:
 						// On chain splits we need to reset the transaction state. We can't be sure whether the actual
 						// state of the accounts are still valid.
-						if i == ev.splitCount {
+						split++
+						if split == ev.splitCount {
 							self.setTxState(state.New(event.Block.Root(), self.stateDb))
 						}
 					}
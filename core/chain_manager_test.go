@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"os"
@@ -8,10 +9,12 @@ import (
 	"runtime"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -419,3 +422,191 @@ func TestReorgShortest(t *testing.T) {
 		}
 	}
 }
+
+func TestChainForks(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := GenesisBlock(db)
+	bc := chm(genesis, db)
+
+	chain1 := makeChainWithDiff(genesis, []int{1, 2, 4}, 10)
+	chain2 := makeChainWithDiff(genesis, []int{1, 2, 3, 4}, 11)
+
+	bc.InsertChain(chain1)
+	bc.InsertChain(chain2)
+
+	blocks := bc.BlocksAt(2)
+	if len(blocks) != 2 {
+		t.Fatalf("block count at height 2 mismatch: have %d, want 2", len(blocks))
+	}
+	if !blocks[0].Canonical || blocks[0].Hash != chain2[1].Hash().Hex() {
+		t.Errorf("canonical block not first: %+v", blocks[0])
+	}
+	if blocks[1].Canonical || blocks[1].Hash != chain1[1].Hash().Hex() {
+		t.Errorf("side block mismatch: %+v", blocks[1])
+	}
+
+	if ancestor := bc.CommonAncestor(chain1[2].Hash(), chain2[3].Hash()); ancestor == nil || ancestor.Hash() != genesis.Hash() {
+		t.Errorf("common ancestor mismatch: %v", ancestor)
+	}
+	if ancestor := bc.CommonAncestor(chain2[1].Hash(), chain2[3].Hash()); ancestor == nil || ancestor.Hash() != chain2[1].Hash() {
+		t.Errorf("common ancestor of a block and its descendant mismatch: %v", ancestor)
+	}
+
+	roots := bc.ForkTree(10)
+	if len(roots) != 1 || roots[0].Hash != genesis.Hash().Hex() {
+		t.Fatalf("fork tree roots mismatch: %+v", roots)
+	}
+	if len(roots[0].Children) != 2 || !roots[0].Children[0].Canonical {
+		t.Errorf("fork tree branches mismatch: %+v", roots[0].Children)
+	}
+	// above the fork point both branches are separate roots
+	roots = bc.ForkTree(2)
+	if len(roots) != 2 || !roots[0].Canonical || roots[1].Canonical {
+		t.Errorf("shallow fork tree mismatch: %+v", roots)
+	}
+}
+
+// logProc is a block processor reporting a log for every transaction.
+type logProc struct{ bproc }
+
+func (logProc) BlockLogs(block *types.Block) state.Logs {
+	var logs state.Logs
+	for _, tx := range block.Transactions() {
+		log := state.NewLog(common.Address{}, nil, nil, block.NumberU64())
+		log.TxHash, log.BlockHash = tx.Hash(), block.Hash()
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func TestReorgRemovedTxsAndLogs(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := GenesisBlock(db)
+	bc := chm(genesis, db)
+	bc.processor = logProc{}
+
+	txA := types.NewTransactionMessage(common.Address{0x0a}, common.Big0, common.Big0, common.Big0, nil)
+	txB := types.NewTransactionMessage(common.Address{0x0b}, common.Big0, common.Big0, common.Big0, nil)
+
+	chain1 := makeChainWithDiff(genesis, []int{1, 2}, 10)
+	chain1[0].SetTransactions(types.Transactions{txA, txB})
+	chain2 := makeChainWithDiff(genesis, []int{1, 2, 3}, 11)
+	chain2[1].SetTransactions(types.Transactions{txA})
+
+	sub := bc.eventMux.Subscribe(queueEvent{})
+	defer sub.Unsubscribe()
+
+	bc.InsertChain(chain1)
+	<-sub.Chan()
+	bc.InsertChain(chain2)
+
+	var ev queueEvent
+	select {
+	case e := <-sub.Chan():
+		ev = e.(queueEvent)
+	case <-time.After(time.Second):
+		t.Fatal("chain events not posted")
+	}
+	// the reverted transactions and logs directly precede the split
+	var queue []interface{}
+	for i, event := range ev.queue {
+		if _, ok := event.(RemovedTransactionEvent); ok {
+			queue = ev.queue[i:]
+			break
+		}
+	}
+	if len(queue) < 3 {
+		t.Fatalf("removed transactions missing: %v", ev.queue)
+	}
+	txs := queue[0].(RemovedTransactionEvent)
+	if len(txs.Txs) != 1 || txs.Txs[0].Hash() != txB.Hash() {
+		t.Errorf("dropped transactions mismatch: %v", txs.Txs)
+	}
+	logs, ok := queue[1].(RemovedLogsEvent)
+	if !ok {
+		t.Fatalf("expected removed logs, got %T", queue[1])
+	}
+	if len(logs.Logs) != 2 {
+		t.Errorf("removed log count mismatch: have %d, want 2", len(logs.Logs))
+	}
+	for _, log := range logs.Logs {
+		if !log.Removed() || log.BlockHash != chain1[0].Hash() {
+			t.Errorf("removed log mismatch: %+v", log)
+		}
+	}
+	if _, ok := queue[2].(ChainSplitEvent); !ok {
+		t.Errorf("expected split event, got %T", queue[2])
+	}
+}
+
+func TestExportN(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := GenesisBlock(db)
+	bc := chm(genesis, db)
+	chain := makeChainWithDiff(genesis, []int{1, 2, 3, 4, 5}, 10)
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bc.ExportN(&buf, 2, 4); err != nil {
+		t.Fatal(err)
+	}
+	stream := rlp.NewStream(&buf, 0)
+	for _, want := range chain[1:4] {
+		var block types.Block
+		if err := stream.Decode(&block); err != nil {
+			t.Fatal(err)
+		}
+		if block.NumberU64() != want.NumberU64() {
+			t.Errorf("block number mismatch: have %v, want %v", block.Number(), want.Number())
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes exported past the range", buf.Len())
+	}
+
+	if err := bc.ExportN(&buf, 4, 2); err == nil {
+		t.Errorf("expected error for reversed range")
+	}
+	if err := bc.ExportN(&buf, 0, 6); err == nil {
+		t.Errorf("expected error for range beyond the head")
+	}
+}
+
+type constContract []byte
+
+func (constContract) RequiredGas(input []byte) *big.Int { return big.NewInt(1) }
+func (c constContract) Run(input []byte) []byte         { return c }
+
+func TestChainPrecompiled(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := GenesisBlock(db)
+	addr := common.BytesToAddress([]byte{0x10})
+
+	// Chains in the same process must each use their own contracts.
+	registry := vm.PrecompiledContracts()
+	registry.Register(common.Big0, addr, constContract{0x2a})
+	custom, frontier := chm(genesis, db), chm(genesis, db)
+	custom.SetPrecompiled(registry)
+
+	call := func(chain *ChainManager, to common.Address) []byte {
+		statedb := state.New(common.Hash{}, db)
+		sender := statedb.GetOrNewStateObject(common.BytesToAddress([]byte{0xbb}))
+		ret, err := NewEnv(statedb, chain, nil, genesis).Call(sender, to, []byte("hello"), big.NewInt(100000), common.Big0, common.Big0)
+		if err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+		return ret
+	}
+	if ret := call(custom, addr); !bytes.Equal(ret, []byte{0x2a}) {
+		t.Errorf("custom contract output mismatch: have %x, want 2a", ret)
+	}
+	if ret := call(frontier, addr); len(ret) != 0 {
+		t.Errorf("custom contract active on frontier chain: %x", ret)
+	}
+	identity := common.BytesToAddress([]byte{4})
+	if ret := call(custom, identity); !bytes.Equal(ret, []byte("hello")) {
+		t.Errorf("identity output mismatch: have %x", ret)
+	}
+}
@@ -71,6 +71,25 @@ func IsValidationErr(err error) bool {
 	return ok
 }
 
+// Proof of work error. Thrown for blocks whose seal doesn't verify
+type PowErr struct {
+	Message string
+}
+
+func (err *PowErr) Error() string {
+	return err.Message
+}
+
+func PowError(nonce [8]byte) error {
+	return &PowErr{Message: fmt.Sprintf("Block's nonce is invalid (= %x)", nonce)}
+}
+
+func IsPowErr(err error) bool {
+	_, ok := err.(*PowErr)
+
+	return ok
+}
+
 type NonceErr struct {
 	Message string
 	Is, Exp uint64
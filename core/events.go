@@ -40,6 +40,14 @@ type PendingBlockEvent struct {
 	Logs  state.Logs
 }
 
+// RemovedTransactionEvent is posted when a reorg drops transactions which
+// are not part of the new canonical chain.
+type RemovedTransactionEvent struct{ Txs types.Transactions }
+
+// RemovedLogsEvent is posted when a reorg reverts logs. The logs are copies
+// marked as removed.
+type RemovedLogsEvent struct{ Logs state.Logs }
+
 type ChainUncleEvent struct {
 	Block *types.Block
 }
@@ -29,20 +29,21 @@ func NewExecution(env vm.Environment, address *common.Address, input []byte, gas
 func (self *Execution) Call(codeAddr common.Address, caller vm.ContextRef) ([]byte, error) {
 	// Retrieve the executing code
 	code := self.env.State().GetCode(codeAddr)
+	hash := self.env.State().GetCodeHash(codeAddr)
 
-	return self.exec(&codeAddr, code, caller)
+	return self.exec(&codeAddr, hash, code, caller)
 }
 
 func (self *Execution) Create(caller vm.ContextRef) (ret []byte, err error, account *state.StateObject) {
 	// Input must be nil for create
 	code := self.input
 	self.input = nil
-	ret, err = self.exec(nil, code, caller)
+	ret, err = self.exec(nil, common.Hash{}, code, caller)
 	account = self.env.State().GetStateObject(*self.address)
 	return
 }
 
-func (self *Execution) exec(contextAddr *common.Address, code []byte, caller vm.ContextRef) (ret []byte, err error) {
+func (self *Execution) exec(contextAddr *common.Address, codeHash common.Hash, code []byte, caller vm.ContextRef) (ret []byte, err error) {
 	start := time.Now()
 
 	env := self.env
@@ -53,7 +54,7 @@ func (self *Execution) exec(contextAddr *common.Address, code []byte, caller vm.
 		return nil, vm.DepthError{}
 	}
 
-	vsnapshot := env.State().Copy()
+	vsnapshot := env.State().Snapshot()
 	var createAccount bool
 	if self.address == nil {
 		// Generate a new address
@@ -65,7 +66,7 @@ func (self *Execution) exec(contextAddr *common.Address, code []byte, caller vm.
 		self.address = &addr
 		createAccount = true
 	}
-	snapshot := env.State().Copy()
+	snapshot := env.State().Snapshot()
 
 	var (
 		from = env.State().GetStateObject(caller.Address())
@@ -79,7 +80,7 @@ func (self *Execution) exec(contextAddr *common.Address, code []byte, caller vm.
 
 	err = env.Transfer(from, to, self.value)
 	if err != nil {
-		env.State().Set(vsnapshot)
+		env.State().RevertToSnapshot(vsnapshot)
 
 		caller.ReturnGas(self.Gas, self.price)
 
@@ -87,12 +88,12 @@ func (self *Execution) exec(contextAddr *common.Address, code []byte, caller vm.
 	}
 
 	context := vm.NewContext(caller, to, self.value, self.Gas, self.price)
-	context.SetCallCode(contextAddr, code)
+	context.SetCallCode(contextAddr, codeHash, code)
 
 	ret, err = evm.Run(context, self.input)
 	evm.Printf("message call took %v", time.Since(start)).Endl()
 	if err != nil {
-		env.State().Set(snapshot)
+		env.State().RevertToSnapshot(snapshot)
 	}
 
 	return
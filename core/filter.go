@@ -60,7 +60,9 @@ func (self *Filter) SetSkip(skip int) {
 	self.skip = skip
 }
 
-// Run filters logs with the current parameters set
+// Run filters logs with the current parameters set. Blocks in sections of
+// the bloom-bits index are only loaded if the index matches them, the others
+// are checked against their own bloom.
 func (self *Filter) Find() state.Logs {
 	earliestBlock := self.eth.ChainManager().CurrentBlock()
 	var earliestBlockNo uint64 = uint64(self.earliest)
@@ -73,34 +75,51 @@ func (self *Filter) Find() state.Logs {
 	}
 
 	var (
-		logs  state.Logs
-		block = self.eth.ChainManager().GetBlockByNumber(latestBlockNo)
-		quit  bool
+		logs    state.Logs
+		indexer = self.eth.ChainManager().BloomIndexer()
+		section = uint64(math.MaxUint64)
+		matches []byte // candidate blocks of section, nil if it isn't indexed
 	)
-	for i := 0; !quit && block != nil; i++ {
-		// Quit on latest
-		switch {
-		case block.NumberU64() == earliestBlockNo, block.NumberU64() == 0:
-			quit = true
-		case self.max <= len(logs):
-			break
+	for number := latestBlockNo; ; number-- {
+		candidate := true
+		if indexer != nil {
+			size := indexer.SectionSize()
+			if number/size != section {
+				section, matches = number/size, nil
+				if indexer.Valid(section) {
+					matches = self.sectionMatches(indexer, section)
+				}
+			}
+			if matches != nil {
+				i := number % size
+				candidate = matches[i/8]&(1<<(7-i%8)) != 0
+			}
 		}
 
-		// Use bloom filtering to see if this block is interesting given the
-		// current parameters
-		if self.bloomFilter(block) {
-			// Get the logs of the block
-			unfiltered, err := self.eth.BlockProcessor().GetLogs(block)
-			if err != nil {
-				chainlogger.Warnln("err: filter get logs ", err)
-
+		if candidate {
+			block := self.eth.ChainManager().GetBlockByNumber(number)
+			if block == nil {
 				break
 			}
+			// Use bloom filtering to see if this block is interesting given the
+			// current parameters
+			if self.bloomFilter(block) {
+				// Get the logs of the block
+				unfiltered, err := self.eth.BlockProcessor().GetLogs(block)
+				if err != nil {
+					chainlogger.Warnln("err: filter get logs ", err)
+
+					break
+				}
 
-			logs = append(logs, self.FilterLogs(unfiltered)...)
+				logs = append(logs, self.FilterLogs(unfiltered)...)
+			}
 		}
 
-		block = self.eth.ChainManager().GetBlock(block.ParentHash())
+		// Quit on latest
+		if number == earliestBlockNo || number == 0 {
+			break
+		}
 	}
 
 	skip := int(math.Min(float64(len(logs)), float64(self.skip)))
@@ -108,6 +127,66 @@ func (self *Filter) Find() state.Logs {
 	return logs[skip:]
 }
 
+// sectionMatches returns a bit vector of the blocks in section whose bloom
+// may match the addresses and topics of the filter.
+func (self *Filter) sectionMatches(indexer *BloomIndexer, section uint64) []byte {
+	matches := make([]byte, indexer.SectionSize()/8)
+	for i := range matches {
+		matches[i] = 0xff
+	}
+
+	if len(self.address) > 0 {
+		values := make([][]byte, len(self.address))
+		for i, addr := range self.address {
+			values[i] = addr.Bytes()
+		}
+		andBits(matches, anyBloomMatch(indexer, section, values))
+	}
+	for _, sub := range self.topics {
+		var (
+			values   [][]byte
+			wildcard bool
+		)
+		for _, topic := range sub {
+			if (topic == common.Hash{}) {
+				wildcard = true
+				break
+			}
+			values = append(values, topic.Bytes())
+		}
+		if !wildcard {
+			andBits(matches, anyBloomMatch(indexer, section, values))
+		}
+	}
+	return matches
+}
+
+// anyBloomMatch returns a bit vector of the blocks in section whose bloom
+// includes any of values.
+func anyBloomMatch(indexer *BloomIndexer, section uint64, values [][]byte) []byte {
+	size := indexer.SectionSize() / 8
+	res := make([]byte, size)
+	for _, value := range values {
+		match := make([]byte, size)
+		for i := range match {
+			match[i] = 0xff
+		}
+		for _, bit := range bloomBits(value) {
+			andBits(match, indexer.Vector(bit, section))
+		}
+		for i := range res {
+			res[i] |= match[i]
+		}
+	}
+	return res
+}
+
+func andBits(dst, src []byte) {
+	for i := range dst {
+		dst[i] &= src[i]
+	}
+}
+
 func includes(addresses []common.Address, a common.Address) bool {
 	for _, addr := range addresses {
 		if addr != a {
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// blockHeightPre prefixes the hashes of all blocks written at a height,
+// canonical or not.
+var blockHeightPre = []byte("block-height-")
+
+// ForkBlock describes a known block and whether it is part of the canonical
+// chain.
+type ForkBlock struct {
+	Hash       string       `json:"hash"`
+	ParentHash string       `json:"parentHash"`
+	Number     uint64       `json:"number"`
+	Td         string       `json:"td"`
+	Canonical  bool         `json:"canonical"`
+	Children   []*ForkBlock `json:"children,omitempty"`
+}
+
+// indexHeight records the hash of block among the blocks at its height.
+func (bc *ChainManager) indexHeight(block *types.Block) {
+	hash := block.Hash()
+	hashes := bc.hashesAt(block.NumberU64())
+	for _, known := range hashes {
+		if known == hash {
+			return
+		}
+	}
+	enc, _ := rlp.EncodeToBytes(append(hashes, hash))
+	bc.blockDb.Put(append(blockHeightPre, big.NewInt(int64(block.NumberU64())).Bytes()...), enc)
+}
+
+func (bc *ChainManager) hashesAt(number uint64) []common.Hash {
+	data, _ := bc.blockDb.Get(append(blockHeightPre, big.NewInt(int64(number)).Bytes()...))
+	if len(data) == 0 {
+		return nil
+	}
+	var hashes []common.Hash
+	rlp.DecodeBytes(data, &hashes)
+	return hashes
+}
+
+// BlocksAt returns all known blocks with the given number, the canonical one
+// first and the others by descending total difficulty.
+func (self *ChainManager) BlocksAt(number uint64) []*ForkBlock {
+	var canonical common.Hash
+	if block := self.GetBlockByNumber(number); block != nil {
+		canonical = block.Hash()
+	}
+
+	hashes := self.hashesAt(number)
+	if canonical != (common.Hash{}) {
+		// Blocks written before the height index existed are only known
+		// through the canonical number index
+		hashes = append([]common.Hash{canonical}, hashes...)
+	}
+
+	var (
+		blocks = []*ForkBlock{}
+		seen   = make(map[common.Hash]bool)
+	)
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		if block := self.GetBlock(hash); block != nil {
+			blocks = append(blocks, newForkBlock(block, hash == canonical))
+		}
+	}
+	sort.Sort(forkBlocksByTd(blocks))
+
+	return blocks
+}
+
+// CommonAncestor returns the most recent block both given blocks descend
+// from, or nil if either of them or one of their ancestors is unknown.
+func (self *ChainManager) CommonAncestor(a, b common.Hash) *types.Block {
+	x, y := self.GetBlock(a), self.GetBlock(b)
+	for x != nil && y != nil && x.NumberU64() > y.NumberU64() {
+		x = self.GetBlock(x.ParentHash())
+	}
+	for x != nil && y != nil && y.NumberU64() > x.NumberU64() {
+		y = self.GetBlock(y.ParentHash())
+	}
+	for x != nil && y != nil {
+		if x.Hash() == y.Hash() {
+			return x
+		}
+		x, y = self.GetBlock(x.ParentHash()), self.GetBlock(y.ParentHash())
+	}
+	return nil
+}
+
+// ForkTree returns the known blocks from depth blocks below the current head
+// upwards, linked to their parents. The canonical block at that height is
+// the first root, branches leaving the chain below it are further roots.
+func (self *ChainManager) ForkTree(depth uint64) []*ForkBlock {
+	var from uint64
+	if head := self.CurrentBlock().NumberU64(); head > depth {
+		from = head - depth
+	}
+
+	var (
+		nodes = make(map[string]*ForkBlock)
+		order []*ForkBlock
+	)
+	// Side chains may be longer than the canonical one, continue up to
+	// the first height without any block
+	for number := from; ; number++ {
+		blocks := self.BlocksAt(number)
+		if len(blocks) == 0 {
+			break
+		}
+		for _, block := range blocks {
+			nodes[block.Hash] = block
+			order = append(order, block)
+		}
+	}
+
+	roots := []*ForkBlock{}
+	for _, block := range order {
+		if parent := nodes[block.ParentHash]; parent != nil {
+			parent.Children = append(parent.Children, block)
+		} else {
+			roots = append(roots, block)
+		}
+	}
+	return roots
+}
+
+func newForkBlock(block *types.Block, canonical bool) *ForkBlock {
+	td := "0"
+	if block.Td != nil {
+		td = block.Td.String()
+	}
+	return &ForkBlock{
+		Hash:       block.Hash().Hex(),
+		ParentHash: block.ParentHash().Hex(),
+		Number:     block.NumberU64(),
+		Td:         td,
+		Canonical:  canonical,
+	}
+}
+
+type forkBlocksByTd []*ForkBlock
+
+func (s forkBlocksByTd) Len() int      { return len(s) }
+func (s forkBlocksByTd) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s forkBlocksByTd) Less(i, j int) bool {
+	if s[i].Canonical != s[j].Canonical {
+		return s[i].Canonical
+	}
+	ti, _ := new(big.Int).SetString(s[i].Td, 10)
+	tj, _ := new(big.Int).SetString(s[j].Td, 10)
+	if c := ti.Cmp(tj); c != 0 {
+		return c > 0
+	}
+	return s[i].Hash < s[j].Hash
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// ProfileReport summarises a vm.Profiler, with opcodes, contracts and
+// instructions sorted by the gas they consumed.
+type ProfileReport struct {
+	Opcodes   []OpcodeProfile   `json:"opcodes"`
+	Contracts []ContractProfile `json:"contracts"`
+}
+
+type OpcodeProfile struct {
+	Op        string `json:"op"`
+	Count     uint64 `json:"count"`
+	Gas       uint64 `json:"gas"`
+	MemoryGas uint64 `json:"memoryGas"`
+}
+
+type ContractProfile struct {
+	Address      string               `json:"address"`
+	Init         bool                 `json:"init"` // contract creation code
+	Gas          uint64               `json:"gas"`
+	Instructions []InstructionProfile `json:"instructions"`
+}
+
+type InstructionProfile struct {
+	PC        uint64 `json:"pc"`
+	Asm       string `json:"asm"`
+	Count     uint64 `json:"count"`
+	Gas       uint64 `json:"gas"`
+	MemoryGas uint64 `json:"memoryGas"`
+}
+
+func NewProfileReport(profiler *vm.Profiler) *ProfileReport {
+	report := &ProfileReport{Opcodes: []OpcodeProfile{}, Contracts: []ContractProfile{}}
+	for op, stats := range profiler.Ops {
+		if stats.Count > 0 {
+			report.Opcodes = append(report.Opcodes, OpcodeProfile{vm.OpCode(op).String(), stats.Count, stats.Gas, stats.MemoryGas})
+		}
+	}
+	sort.Sort(opcodesByGas(report.Opcodes))
+
+	for _, code := range profiler.Codes() {
+		asm := disassemblyByPC(code.Code)
+		contract := ContractProfile{Address: common.ToHex(code.Address.Bytes()), Init: code.Init}
+		for pc, stats := range code.PCs {
+			contract.Gas += stats.Gas
+			contract.Instructions = append(contract.Instructions, InstructionProfile{pc, asm[pc], stats.Count, stats.Gas, stats.MemoryGas})
+		}
+		sort.Sort(instructionsByGas(contract.Instructions))
+		report.Contracts = append(report.Contracts, contract)
+	}
+	sort.Sort(contractsByGas(report.Contracts))
+
+	return report
+}
+
+// disassemblyByPC maps the program counters of code to their instruction,
+// including push data.
+func disassemblyByPC(code []byte) map[uint64]string {
+	asm := make(map[uint64]string)
+
+	var last uint64
+	for _, line := range Disassemble(code) {
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		// Push data follows its instruction on a separate line
+		if strings.HasPrefix(parts[1], "0x") {
+			asm[last] += " " + parts[1]
+			continue
+		}
+		pc, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		asm[pc], last = parts[1], pc
+	}
+	return asm
+}
+
+// WriteText writes the report as text tables.
+func (self *ProfileReport) WriteText(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "opcode\tcount\tgas\tmemory gas\t")
+	for _, op := range self.Opcodes {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t\n", op.Op, op.Count, op.Gas, op.MemoryGas)
+	}
+	for _, contract := range self.Contracts {
+		kind := "code"
+		if contract.Init {
+			kind = "init code"
+		}
+		fmt.Fprintf(tw, "\n%s %s: %d gas\n", kind, contract.Address, contract.Gas)
+		fmt.Fprintln(tw, "pc\tinstruction\tcount\tgas\tmemory gas\t")
+		for _, ins := range contract.Instructions {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t\n", ins.PC, ins.Asm, ins.Count, ins.Gas, ins.MemoryGas)
+		}
+	}
+	tw.Flush()
+}
+
+type opcodesByGas []OpcodeProfile
+
+func (s opcodesByGas) Len() int      { return len(s) }
+func (s opcodesByGas) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s opcodesByGas) Less(i, j int) bool {
+	if s[i].Gas != s[j].Gas {
+		return s[i].Gas > s[j].Gas
+	}
+	return s[i].Op < s[j].Op
+}
+
+type contractsByGas []ContractProfile
+
+func (s contractsByGas) Len() int      { return len(s) }
+func (s contractsByGas) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s contractsByGas) Less(i, j int) bool {
+	if s[i].Gas != s[j].Gas {
+		return s[i].Gas > s[j].Gas
+	}
+	return s[i].Address < s[j].Address
+}
+
+type instructionsByGas []InstructionProfile
+
+func (s instructionsByGas) Len() int      { return len(s) }
+func (s instructionsByGas) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s instructionsByGas) Less(i, j int) bool {
+	if s[i].Gas != s[j].Gas {
+		return s[i].Gas > s[j].Gas
+	}
+	return s[i].PC < s[j].PC
+}
+
+// ProfileTransaction executes the transaction at index in block on top of
+// the state of its parent and the transactions preceding it, and profiles
+// its execution.
+func (self *BlockProcessor) ProfileTransaction(block *types.Block, index int) (*ProfileReport, error) {
+	txs := block.Transactions()
+	if index < 0 || index >= len(txs) {
+		return nil, fmt.Errorf("transaction index %d out of range", index)
+	}
+	parent := self.bc.GetBlock(block.ParentHash())
+	if parent == nil {
+		return nil, ParentError(block.ParentHash())
+	}
+
+	statedb := state.New(parent.Root(), self.db)
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(block.GasLimit())
+
+	usedGas := new(big.Int)
+	for i, tx := range txs[:index] {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+		if _, _, err := self.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true); err != nil && (IsNonceErr(err) || state.IsGasLimitErr(err) || IsInvalidTxErr(err)) {
+			return nil, err
+		}
+	}
+
+	tx := txs[index]
+	statedb.StartRecord(tx.Hash(), block.Hash(), index)
+
+	profiler := vm.NewProfiler()
+	env := NewEnv(statedb, self.bc, tx, block)
+	env.SetProfiler(profiler)
+	if _, _, err := ApplyMessage(env, tx, statedb.GetStateObject(coinbase.Address())); err != nil && (IsNonceErr(err) || state.IsGasLimitErr(err) || IsInvalidTxErr(err)) {
+		return nil, err
+	}
+	return NewProfileReport(profiler), nil
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestProfileReport(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb := state.New(common.Hash{}, db)
+
+	// sstore(0, 1); mstore(0, 0x2a); return(0, 32)
+	code := common.Hex2Bytes("6001600055602a60005260206000f3")
+	addr := common.BytesToAddress([]byte{0xaa})
+	statedb.GetOrNewStateObject(addr).SetCode(code)
+	sender := statedb.GetOrNewStateObject(common.BytesToAddress([]byte{0xbb}))
+
+	block := types.NewBlock(common.Hash{}, common.Address{}, common.Hash{}, common.Big1, 0, nil)
+	tx := types.NewTransactionMessage(addr, common.Big0, big.NewInt(100000), common.Big0, nil)
+	env := NewEnv(statedb, nil, tx, block)
+	profiler := vm.NewProfiler()
+	env.SetProfiler(profiler)
+	if _, err := env.Call(sender, addr, nil, big.NewInt(100000), common.Big0, common.Big0); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+
+	report := NewProfileReport(profiler)
+	if len(report.Opcodes) != 4 {
+		t.Fatalf("opcode count mismatch: have %d, want 4", len(report.Opcodes))
+	}
+	if op := report.Opcodes[0]; op.Op != "SSTORE" || op.Count != 1 || op.Gas != 20000 {
+		t.Errorf("most expensive opcode mismatch: %+v", op)
+	}
+	if len(report.Contracts) != 1 {
+		t.Fatalf("contract count mismatch: have %d, want 1", len(report.Contracts))
+	}
+	contract := report.Contracts[0]
+	if contract.Gas != 20000+6*3+3+3 {
+		t.Errorf("contract gas mismatch: have %d, want %d", contract.Gas, 20000+6*3+3+3)
+	}
+	for _, ins := range contract.Instructions {
+		switch ins.PC {
+		case 0:
+			if ins.Asm != "PUSH1 0x01" {
+				t.Errorf("pc 0 disassembly mismatch: have %q", ins.Asm)
+			}
+		case 9:
+			if ins.Asm != "MSTORE" || ins.MemoryGas != 3 {
+				t.Errorf("pc 9 mismatch: %+v", ins)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	report.WriteText(&buf)
+	if !strings.Contains(buf.String(), "PUSH1 0x2a") {
+		t.Errorf("text report misses instructions:\n%s", buf.String())
+	}
+}
+
+func TestProfileNestedCall(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb := state.New(common.Hash{}, db)
+
+	// call(50000, 0xcc, 0, 0, 0, 0, 32); stop
+	caller := common.BytesToAddress([]byte{0xaa})
+	statedb.GetOrNewStateObject(caller).SetCode(common.Hex2Bytes("60206000600060006000" + "60cc61c350f100"))
+	// sstore(0, 1); stop
+	callee := common.BytesToAddress([]byte{0xcc})
+	statedb.GetOrNewStateObject(callee).SetCode(common.Hex2Bytes("600160005500"))
+
+	key, _ := crypto.GenerateKey()
+	tx := types.NewTransactionMessage(caller, common.Big0, big.NewInt(100000), common.Big0, nil)
+	tx.SignECDSA(key)
+
+	block := types.NewBlock(common.Hash{}, common.Address{}, common.Hash{}, common.Big1, 0, nil)
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(big.NewInt(1000000))
+
+	env := NewEnv(statedb, nil, tx, block)
+	profiler := vm.NewProfiler()
+	env.SetProfiler(profiler)
+	_, gasUsed, err := ApplyMessage(env, tx, coinbase)
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	// The profiled instructions must account for exactly the execution gas,
+	// without counting the gas forwarded to the callee twice.
+	var total uint64
+	for _, stats := range profiler.Ops {
+		total += stats.Gas
+	}
+	want := new(big.Int).Sub(gasUsed, IntrinsicGas(tx)).Uint64()
+	if total != want {
+		t.Errorf("opcode gas mismatch: have %d, want %d", total, want)
+	}
+	total = 0
+	for _, code := range profiler.Codes() {
+		for _, stats := range code.PCs {
+			total += stats.Gas
+		}
+	}
+	if total != want {
+		t.Errorf("contract gas mismatch: have %d, want %d", total, want)
+	}
+	if gas := profiler.Ops[vm.CALL].Gas; gas >= 20000 {
+		t.Errorf("CALL gas includes callee execution: %d", gas)
+	}
+	if gas := profiler.Ops[vm.SSTORE].Gas; gas != 20000 {
+		t.Errorf("SSTORE gas mismatch: have %d, want 20000", gas)
+	}
+}
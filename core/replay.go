@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxReplay holds the receipt values of a replayed transaction next to the
+// ones the block was produced with, if those are known.
+type TxReplay struct {
+	Index                     int    `json:"index"`
+	Hash                      string `json:"hash"`
+	PostState                 string `json:"postState"`
+	CumulativeGasUsed         string `json:"cumulativeGasUsed"`
+	ExpectedPostState         string `json:"expectedPostState,omitempty"`
+	ExpectedCumulativeGasUsed string `json:"expectedCumulativeGasUsed,omitempty"`
+	Error                     string `json:"error,omitempty"`
+}
+
+// ReplayReport is the outcome of re-executing a block on its parent state.
+type ReplayReport struct {
+	Block        string     `json:"block"`
+	Number       uint64     `json:"number"`
+	Transactions []TxReplay `json:"transactions"`
+	// Receipts tells whether the receipts of the block are known, without
+	// them diverging transactions can't be identified.
+	Receipts bool `json:"receipts"`
+	// Divergence is the index of the first transaction whose outcome differs
+	// from the block's, -1 if there is none.
+	Divergence int `json:"divergence"`
+	// Diff holds the state changes made by the diverging transaction.
+	Diff *state.WorldDiff `json:"diff,omitempty"`
+	// Errors lists the header fields which don't match the replay.
+	Errors []string `json:"errors"`
+}
+
+// ReplayBlock re-executes block on top of its parent's state and compares
+// the receipt of each transaction with the block's. Receipts which don't come
+// with the block are loaded from the ones stored when it was imported.
+// Nothing is written to the state database.
+func (self *BlockProcessor) ReplayBlock(block *types.Block) (*ReplayReport, error) {
+	parent := self.bc.GetBlock(block.ParentHash())
+	if parent == nil {
+		return nil, ParentError(block.ParentHash())
+	}
+
+	db := newReplayDatabase(self.db)
+	statedb, coinbase := replayState(db, parent, block)
+
+	expected := block.Receipts()
+	if len(expected) == 0 {
+		expected = GetBlockReceipts(self.extraDb, block.Hash())
+	}
+
+	var (
+		report = &ReplayReport{
+			Block:        block.Hash().Hex(),
+			Number:       block.NumberU64(),
+			Transactions: []TxReplay{},
+			Receipts:     len(expected) > 0,
+			Divergence:   -1,
+			Errors:       []string{},
+		}
+		receipts types.Receipts
+		usedGas  = new(big.Int)
+	)
+	for i, tx := range block.Transactions() {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+
+		result := TxReplay{Index: i, Hash: tx.Hash().Hex()}
+		receipt, _, err := self.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true)
+		if err != nil && (IsNonceErr(err) || state.IsGasLimitErr(err) || IsInvalidTxErr(err)) {
+			// The block is invalid, replaying any further is meaningless
+			result.Error = err.Error()
+			report.Transactions = append(report.Transactions, result)
+			if report.Divergence < 0 {
+				report.Divergence = i
+			}
+			report.Errors = append(report.Errors, fmt.Sprintf("transaction %d: %v", i, err))
+			return report, nil
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		receipts = append(receipts, receipt)
+
+		result.PostState = common.ToHex(receipt.PostState)
+		result.CumulativeGasUsed = receipt.CumulativeGasUsed.String()
+		if i < len(expected) {
+			result.ExpectedPostState = common.ToHex(expected[i].PostState)
+			result.ExpectedCumulativeGasUsed = expected[i].CumulativeGasUsed.String()
+
+			if report.Divergence < 0 && (result.PostState != result.ExpectedPostState || result.CumulativeGasUsed != result.ExpectedCumulativeGasUsed) {
+				report.Divergence = i
+				report.Diff = replayDiff(self.replayPreState(db, parent, block, i), statedb)
+			}
+		}
+		report.Transactions = append(report.Transactions, result)
+	}
+
+	header := block.Header()
+	if usedGas.Cmp(header.GasUsed) != 0 {
+		report.Errors = append(report.Errors, fmt.Sprintf("gas used: block=%v replay=%v", header.GasUsed, usedGas))
+	}
+	if bloom := types.CreateBloom(receipts); bloom != header.Bloom {
+		report.Errors = append(report.Errors, fmt.Sprintf("bloom: block=%x replay=%x", header.Bloom, bloom))
+	}
+	if receiptSha := types.DeriveSha(receipts); receiptSha != header.ReceiptHash {
+		report.Errors = append(report.Errors, fmt.Sprintf("receipt root: block=%x replay=%x", header.ReceiptHash, receiptSha))
+	}
+	AccumulateRewards(statedb, block)
+	statedb.Update()
+	if root := statedb.Root(); root != header.Root {
+		report.Errors = append(report.Errors, fmt.Sprintf("state root: block=%x replay=%x", header.Root, root))
+	}
+
+	return report, nil
+}
+
+// replayState returns the parent state of block and its coinbase, ready for
+// the block's transactions to be applied.
+func replayState(db common.Database, parent, block *types.Block) (*state.StateDB, *state.StateObject) {
+	statedb := state.New(parent.Root(), db)
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(block.GasLimit())
+
+	return statedb, coinbase
+}
+
+// replayPreState rebuilds the state the transaction at index was applied to by
+// executing the transactions preceding it once more. This is only done after
+// a divergence was found, so that replays don't have to keep a copy of the
+// state before every transaction.
+func (self *BlockProcessor) replayPreState(db common.Database, parent, block *types.Block, index int) *state.StateDB {
+	statedb, coinbase := replayState(db, parent, block)
+	usedGas := new(big.Int)
+	for i, tx := range block.Transactions()[:index] {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+		self.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true)
+	}
+	return statedb
+}
+
+// replayDiff returns the difference between two uncommitted states. Copies
+// of both are committed to the replay database so that their storage tries
+// can be resolved.
+func replayDiff(from, to *state.StateDB) *state.WorldDiff {
+	to = to.Copy()
+	from.Sync()
+	to.Sync()
+	diff := from.RawDiff(to)
+	return &diff
+}
+
+// replayDatabase keeps writes in memory and reads everything else from the
+// underlying database.
+type replayDatabase struct {
+	common.Database
+	written map[string][]byte
+}
+
+func newReplayDatabase(db common.Database) *replayDatabase {
+	return &replayDatabase{Database: db, written: make(map[string][]byte)}
+}
+
+func (self *replayDatabase) Put(key []byte, value []byte) {
+	self.written[string(key)] = common.CopyBytes(value)
+}
+
+func (self *replayDatabase) Get(key []byte) ([]byte, error) {
+	if value, ok := self.written[string(key)]; ok {
+		return value, nil
+	}
+	return self.Database.Get(key)
+}
+
+func (self *replayDatabase) Delete(key []byte) error {
+	delete(self.written, string(key))
+	return nil
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// replayBlock creates a block on top of the genesis of bp holding two
+// transactions and sets its receipts and header the way a miner would.
+func replayBlock(t *testing.T, bp *BlockProcessor, db common.Database) *types.Block {
+	key, _ := crypto.GenerateKey()
+	to := common.BytesToAddress([]byte{0xaa})
+
+	var txs types.Transactions
+	for i := uint64(0); i < 2; i++ {
+		tx := types.NewTransactionMessage(to, common.Big0, big.NewInt(21000), common.Big0, nil)
+		tx.SetNonce(i)
+		if err := tx.SignECDSA(key); err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+	}
+
+	genesis := bp.bc.Genesis()
+	block := newBlockFromParent(common.BytesToAddress([]byte{0xcb}), genesis)
+	block.SetTransactions(txs)
+
+	statedb := state.New(genesis.Root(), db)
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(block.GasLimit())
+
+	var (
+		receipts types.Receipts
+		usedGas  = new(big.Int)
+	)
+	for i, tx := range txs {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+		receipt, _, err := bp.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	block.Header().GasUsed = usedGas
+	block.SetReceipts(receipts)
+	AccumulateRewards(statedb, block)
+	statedb.Update()
+	block.SetRoot(statedb.Root())
+
+	return block
+}
+
+func TestReplayBlock(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := replayBlock(t, bp, db)
+
+	report, err := bp.ReplayBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Divergence != -1 || len(report.Errors) != 0 {
+		t.Fatalf("valid block diverged at %d: %v", report.Divergence, report.Errors)
+	}
+	if len(report.Transactions) != 2 || report.Transactions[1].CumulativeGasUsed != "42000" {
+		t.Errorf("unexpected transactions: %+v", report.Transactions)
+	}
+	if report.Diff != nil {
+		t.Errorf("unexpected diff for valid block")
+	}
+}
+
+func TestReplayBlockDivergence(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := replayBlock(t, bp, db)
+
+	// pretend the second transaction used more gas than it does
+	receipts := block.Receipts()
+	receipts[1].CumulativeGasUsed = big.NewInt(50000)
+	block.Header().GasUsed = big.NewInt(50000)
+	block.SetReceipts(receipts)
+
+	report, err := bp.ReplayBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Divergence != 1 {
+		t.Fatalf("divergence mismatch: have %d, want 1", report.Divergence)
+	}
+	if len(report.Errors) != 2 {
+		t.Errorf("expected gas used and receipt root errors, got %v", report.Errors)
+	}
+	if report.Diff == nil || len(report.Diff.Accounts) == 0 {
+		t.Fatalf("missing state diff")
+	}
+	// the diverging transaction only bumps the sender nonce
+	for addr, account := range report.Diff.Accounts {
+		if account.Nonce == nil || account.Nonce.From != "1" || account.Nonce.To != "2" {
+			t.Errorf("unexpected change of %s: %+v", addr, account)
+		}
+	}
+	// the state database is left untouched
+	if value, _ := db.Get(common.FromHex(report.Diff.To)); value != nil {
+		t.Errorf("replay state was written to the database")
+	}
+}
+
+func TestReplayStoredBlock(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, err := newCanonical(0, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := replayBlock(t, bp, db)
+	if _, err := bp.bc.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatal(err)
+	}
+
+	// blocks read back from the database don't carry their receipts
+	data, _ := db.Get(append(blockHashPre, block.Hash().Bytes()...))
+	var stored types.StorageBlock
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len((*types.Block)(&stored).Receipts()) != 0 {
+		t.Fatalf("expected stored block without receipts")
+	}
+	report, err := bp.ReplayBlock((*types.Block)(&stored))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Receipts {
+		t.Fatalf("stored receipts were not loaded")
+	}
+	if report.Divergence != -1 || len(report.Errors) != 0 {
+		t.Fatalf("valid block diverged at %d: %v", report.Divergence, report.Errors)
+	}
+	for i, tx := range report.Transactions {
+		if tx.ExpectedPostState != tx.PostState || tx.ExpectedCumulativeGasUsed != tx.CumulativeGasUsed {
+			t.Errorf("transaction %d mismatch: %+v", i, tx)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Change holds the value of a field before and after. Absent values are
+// represented by an empty string. Storage values are decoded, as returned by
+// StorageRange.
+type Change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type AccountDiff struct {
+	Balance  *Change           `json:"balance,omitempty"`
+	Nonce    *Change           `json:"nonce,omitempty"`
+	CodeHash *Change           `json:"codeHash,omitempty"`
+	Code     *Change           `json:"code,omitempty"`
+	Storage  map[string]Change `json:"storage,omitempty"`
+}
+
+type WorldDiff struct {
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Accounts map[string]AccountDiff `json:"accounts"`
+}
+
+// RawDiff compares the committed state of self against other and returns
+// the accounts which were added, removed or changed. Subtrees shared by both
+// states are skipped.
+func (self *StateDB) RawDiff(other *StateDB) WorldDiff {
+	diff := WorldDiff{
+		From:     common.Bytes2Hex(self.trie.Root()),
+		To:       common.Bytes2Hex(other.trie.Root()),
+		Accounts: make(map[string]AccountDiff),
+	}
+
+	it := trie.NewDiffIterator(self.trie.Trie, other.trie.Trie)
+	for it.Next() {
+		addr := preimage(it.Key, self.trie, other.trie)
+
+		var from, to *StateObject
+		if it.From != nil {
+			from = NewStateObjectFromBytes(common.BytesToAddress(addr), it.From, self.db)
+		}
+		if it.To != nil {
+			to = NewStateObjectFromBytes(common.BytesToAddress(addr), it.To, other.db)
+		}
+		if from == nil && to == nil {
+			continue
+		}
+		diff.Accounts[common.Bytes2Hex(addr)] = diffAccounts(from, to)
+	}
+
+	return diff
+}
+
+func (self *StateDB) Diff(other *StateDB) []byte {
+	json, err := json.MarshalIndent(self.RawDiff(other), "", "    ")
+	if err != nil {
+		fmt.Println("diff err", err)
+	}
+
+	return json
+}
+
+func diffAccounts(from, to *StateObject) AccountDiff {
+	var (
+		account          AccountDiff
+		fromVal, toVal   [4]string
+		fromTrie, toTrie *trie.SecureTrie
+	)
+	if from != nil {
+		fromVal = [4]string{from.balance.String(), fmt.Sprint(from.nonce), common.Bytes2Hex(from.codeHash), common.Bytes2Hex(from.code)}
+		fromTrie = from.State.trie
+	} else {
+		fromTrie = trie.NewSecure(nil, to.db)
+	}
+	if to != nil {
+		toVal = [4]string{to.balance.String(), fmt.Sprint(to.nonce), common.Bytes2Hex(to.codeHash), common.Bytes2Hex(to.code)}
+		toTrie = to.State.trie
+	} else {
+		toTrie = trie.NewSecure(nil, from.db)
+	}
+
+	if fromVal[0] != toVal[0] {
+		account.Balance = &Change{fromVal[0], toVal[0]}
+	}
+	if fromVal[1] != toVal[1] {
+		account.Nonce = &Change{fromVal[1], toVal[1]}
+	}
+	if fromVal[2] != toVal[2] {
+		account.CodeHash = &Change{fromVal[2], toVal[2]}
+		account.Code = &Change{fromVal[3], toVal[3]}
+	}
+
+	it := trie.NewDiffIterator(fromTrie.Trie, toTrie.Trie)
+	for it.Next() {
+		if account.Storage == nil {
+			account.Storage = make(map[string]Change)
+		}
+		key := preimage(it.Key, fromTrie, toTrie)
+		account.Storage[common.Bytes2Hex(key)] = Change{common.Bytes2Hex(storageValue(it.From)), common.Bytes2Hex(storageValue(it.To))}
+	}
+
+	return account
+}
+
+// preimage looks up the preimage of a hashed key in either of the given
+// tries. The hashed key itself is returned if the preimage is unknown.
+func preimage(shaKey []byte, tries ...*trie.SecureTrie) []byte {
+	for _, t := range tries {
+		if key := t.GetKey(shaKey); key != nil {
+			return key
+		}
+	}
+
+	return shaKey
+}
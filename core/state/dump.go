@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 type Account struct {
@@ -36,13 +37,58 @@ func (self *StateDB) RawDump() World {
 
 		storageIt := stateObject.State.trie.Iterator()
 		for storageIt.Next() {
-			account.Storage[common.Bytes2Hex(self.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(storageIt.Value)
+			account.Storage[common.Bytes2Hex(stateObject.State.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(storageIt.Value)
 		}
 		world.Accounts[common.Bytes2Hex(addr)] = account
 	}
 	return world
 }
 
+type StorageEntry struct {
+	Key   *string `json:"key"` // Preimage of the hashed key, nil if unknown
+	Value string  `json:"value"`
+}
+
+type StorageRange struct {
+	Storage map[string]StorageEntry `json:"storage"`
+	NextKey *string                 `json:"nextKey"` // Hashed key to resume from, nil if done
+}
+
+// StorageRange returns at most limit storage slots of the given account in
+// hashed key order, starting at the hashed key start. The slots are read from
+// the storage trie directly rather than through the storage cache.
+func (self *StateDB) StorageRange(addr common.Address, start []byte, limit int) StorageRange {
+	result := StorageRange{Storage: make(map[string]StorageEntry)}
+
+	stateObject := self.GetStateObject(addr)
+	if stateObject == nil {
+		return result
+	}
+
+	storage := stateObject.State.trie
+	it := trie.NewRangeIterator(storage.Trie, start, nil)
+	for i := 0; i < limit && it.Next(); i++ {
+		entry := StorageEntry{Value: common.ToHex(storageValue(it.Value))}
+		if key := storage.GetKey(it.Key); key != nil {
+			hex := common.ToHex(key)
+			entry.Key = &hex
+		}
+		result.Storage[common.ToHex(it.Key)] = entry
+	}
+	if next := it.Position(); next != nil {
+		hex := common.ToHex(next)
+		result.NextKey = &hex
+	}
+
+	return result
+}
+
+// storageValue decodes a storage slot as it is stored in the trie, nil if
+// the slot is empty.
+func storageValue(enc []byte) []byte {
+	return common.NewValueFromBytes(enc).Bytes()
+}
+
 func (self *StateDB) Dump() []byte {
 	json, err := json.MarshalIndent(self.RawDump(), "", "    ")
 	if err != nil {
@@ -0,0 +1,131 @@
+package state
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// journalEntry is a modification of the state which can be undone.
+type journalEntry interface {
+	undo(*StateDB)
+}
+
+type journal []journalEntry
+
+type (
+	// Changes to the account map
+	createObjectChange struct {
+		account common.Address
+		prev    *StateObject // Object replaced by the new one, nil if none
+	}
+	suicideChange struct {
+		account     *StateObject
+		prev        bool // Whether the account was already marked for deletion
+		prevbalance *big.Int
+	}
+
+	// Changes to individual accounts
+	balanceChange struct {
+		account *StateObject
+		prev    *big.Int
+	}
+	nonceChange struct {
+		account *StateObject
+		prev    uint64
+	}
+	codeChange struct {
+		account            *StateObject
+		prevcode, prevhash []byte
+	}
+	storageChange struct {
+		account   *StateObject
+		key       string
+		prevvalue *common.Value // Modified value before the change, nil if unmodified
+	}
+	gasPoolChange struct {
+		account *StateObject
+		prev    *big.Int
+	}
+
+	// Changes to other state values
+	refundChange struct {
+		account string
+		prev    *big.Int // Refund before the change, nil if none
+	}
+	addLogChange struct {
+		txhash common.Hash
+	}
+	addTransferChange struct {
+		txhash common.Hash
+	}
+)
+
+func (ch createObjectChange) undo(s *StateDB) {
+	if ch.prev == nil {
+		delete(s.stateObjects, ch.account.Str())
+	} else {
+		s.stateObjects[ch.account.Str()] = ch.prev
+	}
+}
+
+func (ch suicideChange) undo(s *StateDB) {
+	ch.account.remove = ch.prev
+	ch.account.balance = ch.prevbalance
+	ch.account.dirty = true
+}
+
+func (ch balanceChange) undo(s *StateDB) {
+	ch.account.balance = ch.prev
+	ch.account.dirty = true
+}
+
+func (ch nonceChange) undo(s *StateDB) {
+	ch.account.nonce = ch.prev
+	ch.account.dirty = true
+}
+
+func (ch codeChange) undo(s *StateDB) {
+	ch.account.code = ch.prevcode
+	ch.account.codeHash = ch.prevhash
+	ch.account.dirty = true
+}
+
+func (ch storageChange) undo(s *StateDB) {
+	if ch.prevvalue == nil {
+		delete(ch.account.dirtyStorage, ch.key)
+	} else {
+		ch.account.dirtyStorage[ch.key] = ch.prevvalue
+	}
+	ch.account.dirty = true
+}
+
+func (ch gasPoolChange) undo(s *StateDB) {
+	ch.account.gasPool = ch.prev
+}
+
+func (ch refundChange) undo(s *StateDB) {
+	if ch.prev == nil {
+		delete(s.refund, ch.account)
+	} else {
+		s.refund[ch.account] = ch.prev
+	}
+}
+
+func (ch addLogChange) undo(s *StateDB) {
+	logs := s.logs[ch.txhash]
+	if len(logs) == 1 {
+		delete(s.logs, ch.txhash)
+	} else {
+		s.logs[ch.txhash] = logs[:len(logs)-1]
+	}
+}
+
+func (ch addTransferChange) undo(s *StateDB) {
+	transfers := s.transfers[ch.txhash]
+	if len(transfers) == 1 {
+		delete(s.transfers, ch.txhash)
+	} else {
+		s.transfers[ch.txhash] = transfers[:len(transfers)-1]
+	}
+}
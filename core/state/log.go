@@ -18,6 +18,8 @@ type Log struct {
 	TxIndex   uint
 	BlockHash common.Hash
 	Index     uint
+
+	removed bool // set when the log was reverted by a chain reorganisation
 }
 
 func NewLog(address common.Address, topics []common.Hash, data []byte, number uint64) *Log {
@@ -28,6 +30,18 @@ func (self *Log) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, []interface{}{self.Address, self.Topics, self.Data})
 }
 
+// Removed reports whether the log was reverted by a chain reorganisation.
+func (self *Log) Removed() bool {
+	return self.removed
+}
+
+// RemovedCopy returns a copy of the log marked as reverted.
+func (self *Log) RemovedCopy() *Log {
+	cpy := *self
+	cpy.removed = true
+	return &cpy
+}
+
 func (self *Log) String() string {
 	return fmt.Sprintf(`log: %x %x %x`, self.Address, self.Topics, self.Data)
 }
@@ -42,6 +42,9 @@ func (self Storage) Copy() Storage {
 type StateObject struct {
 	// State database for storing state changes
 	db common.Database
+	// The state which owns this object and journals its changes, nil if the
+	// object is not part of a state
+	owner *StateDB
 	// The state object
 	State *StateDB
 
@@ -57,8 +60,10 @@ type StateObject struct {
 	code Code
 	// Temporarily initialisation code
 	initCode Code
-	// Cached storage (flushed when updated)
-	storage Storage
+	// Storage values as committed to the storage trie
+	originStorage Storage
+	// Storage values modified since the last sync
+	dirtyStorage Storage
 	// Temporary prepaid gas, reward after transition
 	prepaid *big.Int
 
@@ -75,7 +80,8 @@ type StateObject struct {
 }
 
 func (self *StateObject) Reset() {
-	self.storage = make(Storage)
+	self.originStorage = make(Storage)
+	self.dirtyStorage = make(Storage)
 	self.State.Reset()
 }
 
@@ -85,7 +91,8 @@ func NewStateObject(address common.Address, db common.Database) *StateObject {
 
 	object := &StateObject{db: db, address: address, balance: new(big.Int), gasPool: new(big.Int), dirty: true}
 	object.State = New(common.Hash{}, db) //New(trie.New(common.Config.Db, ""))
-	object.storage = make(Storage)
+	object.originStorage = make(Storage)
+	object.dirtyStorage = make(Storage)
 	object.gasPool = new(big.Int)
 	object.prepaid = new(big.Int)
 
@@ -112,7 +119,8 @@ func NewStateObjectFromBytes(address common.Address, data []byte, db common.Data
 	object.balance = extobject.Balance
 	object.codeHash = extobject.CodeHash
 	object.State = New(extobject.Root, db)
-	object.storage = make(map[string]*common.Value)
+	object.originStorage = make(Storage)
+	object.dirtyStorage = make(Storage)
 	object.gasPool = new(big.Int)
 	object.prepaid = new(big.Int)
 	object.code, _ = db.Get(extobject.CodeHash)
@@ -147,18 +155,35 @@ func (self *StateObject) SetStorage(key *big.Int, value *common.Value) {
 	self.SetState(common.BytesToHash(key.Bytes()), value)
 }
 
+// Storage returns the cached storage of the object, with modified values
+// taking precedence over the committed ones.
 func (self *StateObject) Storage() Storage {
-	return self.storage
+	storage := self.originStorage.Copy()
+	for key, value := range self.dirtyStorage {
+		storage[key] = value
+	}
+
+	return storage
 }
 
 func (self *StateObject) GetState(key common.Hash) *common.Value {
 	strkey := key.Str()
-	value := self.storage[strkey]
+	if value, ok := self.dirtyStorage[strkey]; ok {
+		return value
+	}
+
+	return self.getOrigin(strkey)
+}
+
+// getOrigin returns the committed value of a storage slot, loading it from
+// the storage trie if it isn't cached yet.
+func (self *StateObject) getOrigin(key string) *common.Value {
+	value := self.originStorage[key]
 	if value == nil {
-		value = self.getAddr(key)
+		value = self.getAddr(common.BytesToHash([]byte(key)))
 
 		if !value.IsNil() {
-			self.storage[strkey] = value
+			self.originStorage[key] = value
 		}
 	}
 
@@ -166,20 +191,43 @@ func (self *StateObject) GetState(key common.Hash) *common.Value {
 }
 
 func (self *StateObject) SetState(k common.Hash, value *common.Value) {
-	self.storage[k.Str()] = value.Copy()
+	self.record(storageChange{self, k.Str(), self.dirtyStorage[k.Str()]})
+	self.dirtyStorage[k.Str()] = value.Copy()
 	self.dirty = true
 }
 
-func (self *StateObject) Sync() {
-	for key, value := range self.storage {
+// Sync writes the modified storage slots to the storage trie. Slots which
+// were set back to their committed value are skipped. It returns the number
+// of slots written and skipped.
+func (self *StateObject) Sync() (written, skipped int) {
+	for key, value := range self.dirtyStorage {
+		if storageEqual(value, self.getOrigin(key)) {
+			skipped++
+			continue
+		}
+
 		if value.Len() == 0 {
 			self.State.trie.Delete([]byte(key))
-			continue
+			delete(self.originStorage, key)
+		} else {
+			self.setAddr([]byte(key), value)
+			self.originStorage[key] = value
 		}
+		written++
+	}
+	self.dirtyStorage = make(Storage)
 
-		self.setAddr([]byte(key), value)
+	return written, skipped
+}
+
+// storageEqual reports whether two storage values have the same trie
+// representation.
+func storageEqual(a, b *common.Value) bool {
+	if a.Len() == 0 || b.Len() == 0 {
+		return a.Len() == b.Len()
 	}
-	self.storage = make(Storage)
+
+	return bytes.Equal(common.Encode(a), common.Encode(b))
 }
 
 func (c *StateObject) GetInstr(pc *big.Int) *common.Value {
@@ -207,12 +255,13 @@ func (c *StateObject) SubBalance(amount *big.Int) {
 }
 
 func (c *StateObject) SetBalance(amount *big.Int) {
+	c.record(balanceChange{c, new(big.Int).Set(c.balance)})
 	c.balance = amount
 	c.dirty = true
 }
 
 func (c *StateObject) St() Storage {
-	return c.storage
+	return c.Storage()
 }
 
 //
@@ -235,6 +284,7 @@ func (c *StateObject) ConvertGas(gas, price *big.Int) error {
 }
 
 func (self *StateObject) SetGasPool(gasLimit *big.Int) {
+	self.record(gasPoolChange{self, new(big.Int).Set(self.gasPool)})
 	self.gasPool = new(big.Int).Set(gasLimit)
 
 	if glog.V(logger.Core) {
@@ -247,6 +297,7 @@ func (self *StateObject) BuyGas(gas, price *big.Int) error {
 		return GasLimitError(self.gasPool, gas)
 	}
 
+	self.record(gasPoolChange{self, new(big.Int).Set(self.gasPool)})
 	self.gasPool.Sub(self.gasPool, gas)
 
 	rGas := new(big.Int).Set(gas)
@@ -258,6 +309,7 @@ func (self *StateObject) BuyGas(gas, price *big.Int) error {
 }
 
 func (self *StateObject) RefundGas(gas, price *big.Int) {
+	self.record(gasPoolChange{self, new(big.Int).Set(self.gasPool)})
 	self.gasPool.Add(self.gasPool, gas)
 }
 
@@ -271,7 +323,8 @@ func (self *StateObject) Copy() *StateObject {
 	}
 	stateObject.code = common.CopyBytes(self.code)
 	stateObject.initCode = common.CopyBytes(self.initCode)
-	stateObject.storage = self.storage.Copy()
+	stateObject.originStorage = self.originStorage.Copy()
+	stateObject.dirtyStorage = self.dirtyStorage.Copy()
 	stateObject.gasPool.Set(self.gasPool)
 	stateObject.remove = self.remove
 	stateObject.dirty = self.dirty
@@ -279,6 +332,13 @@ func (self *StateObject) Copy() *StateObject {
 	return stateObject
 }
 
+// record adds a change to the journal of the owning state.
+func (self *StateObject) record(entry journalEntry) {
+	if self.owner != nil {
+		self.owner.journal = append(self.owner.journal, entry)
+	}
+}
+
 func (self *StateObject) Set(stateObject *StateObject) {
 	*self = *stateObject
 }
@@ -318,7 +378,9 @@ func (self *StateObject) Code() []byte {
 }
 
 func (self *StateObject) SetCode(code []byte) {
+	self.record(codeChange{self, self.code, self.codeHash})
 	self.code = code
+	self.codeHash = crypto.Sha3(code)
 	self.dirty = true
 }
 
@@ -328,6 +390,7 @@ func (self *StateObject) SetInitCode(code []byte) {
 }
 
 func (self *StateObject) SetNonce(nonce uint64) {
+	self.record(nonceChange{self, self.nonce})
 	self.nonce = nonce
 	self.dirty = true
 }
@@ -346,7 +409,10 @@ func (c *StateObject) RlpEncode() []byte {
 }
 
 func (c *StateObject) CodeHash() common.Bytes {
-	return crypto.Sha3(c.code)
+	if c.codeHash == nil {
+		c.codeHash = crypto.Sha3(c.code)
+	}
+	return c.codeHash
 }
 
 func (c *StateObject) RlpDecode(data []byte) {
@@ -354,7 +420,8 @@ func (c *StateObject) RlpDecode(data []byte) {
 	c.nonce = decoder.Get(0).Uint()
 	c.balance = decoder.Get(1).BigInt()
 	c.State = New(common.BytesToHash(decoder.Get(2).Bytes()), c.db) //New(trie.New(common.Config.Db, decoder.Get(2).Interface()))
-	c.storage = make(map[string]*common.Value)
+	c.originStorage = make(Storage)
+	c.dirtyStorage = make(Storage)
 	c.gasPool = new(big.Int)
 
 	c.codeHash = decoder.Get(3).Bytes()
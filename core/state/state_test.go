@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 type StateSuite struct {
@@ -104,3 +106,198 @@ func (s *StateSuite) TestSnapshot(c *checker.C) {
 
 	c.Assert(data1, checker.DeepEquals, res)
 }
+
+func TestRevertToSnapshot(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state := New(common.Hash{}, db)
+
+	addr1, addr2, addr3 := toAddr([]byte{0x01}), toAddr([]byte{0x02}), toAddr([]byte{0x03})
+	state.AddBalance(addr1, big.NewInt(10))
+	state.SetNonce(addr1, 1)
+	state.SetCode(addr1, []byte{1, 2, 3})
+	state.SetState(addr1, common.Hash{1}, []byte{1})
+	state.AddBalance(addr2, big.NewInt(20))
+	state.Refund(addr1, big.NewInt(5))
+	state.StartRecord(common.Hash{1}, common.Hash{}, 0)
+	state.AddLog(&Log{Address: addr1})
+	state.Update()
+	state.Sync()
+	root := state.Root()
+
+	state.Refund(addr1, big.NewInt(1))
+	state.AddLog(&Log{Address: addr1})
+	snapshot := state.Snapshot()
+
+	state.AddBalance(addr1, big.NewInt(5))
+	state.SetNonce(addr1, 2)
+	state.SetCode(addr1, []byte{4, 5, 6})
+	state.SetState(addr1, common.Hash{1}, []byte{2})
+	state.SetState(addr1, common.Hash{2}, []byte{3})
+	state.Delete(addr2)
+	state.SetNonce(addr3, 1)
+	state.Refund(addr1, big.NewInt(2))
+	state.Refund(addr2, big.NewInt(3))
+	state.AddLog(&Log{Address: addr2})
+	state.RevertToSnapshot(snapshot)
+
+	if balance := state.GetBalance(addr1); balance.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("balance mismatch: have %v, want 10", balance)
+	}
+	if nonce := state.GetNonce(addr1); nonce != 1 {
+		t.Errorf("nonce mismatch: have %d, want 1", nonce)
+	}
+	if code := state.GetCode(addr1); !bytes.Equal(code, []byte{1, 2, 3}) {
+		t.Errorf("code mismatch: have %x, want 010203", code)
+	}
+	if value := state.GetState(addr1, common.Hash{1}); !bytes.Equal(value, []byte{1}) {
+		t.Errorf("storage mismatch: have %x, want 01", value)
+	}
+	if value := state.GetState(addr1, common.Hash{2}); len(value) != 0 {
+		t.Errorf("storage mismatch: have %x, want empty", value)
+	}
+	if state.IsDeleted(addr2) || state.GetBalance(addr2).Cmp(big.NewInt(20)) != 0 {
+		t.Errorf("suicide of %x not reverted", addr2)
+	}
+	if state.HasAccount(addr3) {
+		t.Errorf("creation of %x not reverted", addr3)
+	}
+	if refund := state.Refunds()[addr1.Str()]; refund == nil || refund.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("refund mismatch: have %v, want 1", refund)
+	}
+	if _, ok := state.Refunds()[addr2.Str()]; ok {
+		t.Errorf("refund of %x not reverted", addr2)
+	}
+	if logs := state.Logs(); len(logs) != 2 {
+		t.Errorf("log count mismatch: have %d, want 2", len(logs))
+	}
+
+	state.Update()
+	if state.Root() != root {
+		t.Errorf("root mismatch after revert: have %x, want %x", state.Root(), root)
+	}
+}
+
+func TestRevertToSnapshotAfterUpdate(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state := New(common.Hash{}, db)
+
+	addr := toAddr([]byte{0x01})
+	state.AddBalance(addr, big.NewInt(10))
+	snapshot := state.Snapshot()
+	state.AddBalance(addr, big.NewInt(5))
+	state.Update()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("reverting to a snapshot taken before Update didn't panic")
+		}
+	}()
+	state.RevertToSnapshot(snapshot)
+}
+
+func TestDiff(t *testing.T) {
+	mdb, _ := ethdb.NewMemDatabase()
+	db := trie.NewPreimageDatabase(mdb)
+	state := New(common.Hash{}, db)
+
+	addr1, addr2, addr3 := toAddr([]byte{0x01}), toAddr([]byte{0x02}), toAddr([]byte{0x03})
+	state.AddBalance(addr1, big.NewInt(10))
+	state.AddBalance(addr2, big.NewInt(20))
+	state.SetState(addr2, common.Hash{1}, []byte{1})
+	state.Update()
+	state.Sync()
+	root := state.Root()
+
+	state.AddBalance(addr1, big.NewInt(5))
+	state.SetState(addr2, common.Hash{1}, []byte{2})
+	state.SetNonce(addr3, 1)
+	state.Update()
+	state.Sync()
+
+	diff := New(root, db).RawDiff(New(state.Root(), db))
+	if len(diff.Accounts) != 3 {
+		t.Fatalf("expected 3 changed accounts, got %d", len(diff.Accounts))
+	}
+	if c := diff.Accounts[common.Bytes2Hex(addr1[:])].Balance; c == nil || c.From != "10" || c.To != "15" {
+		t.Errorf("balance change mismatch: %v", c)
+	}
+	if s := diff.Accounts[common.Bytes2Hex(addr2[:])].Storage; len(s) != 1 {
+		t.Errorf("expected 1 storage change, got %v", s)
+	} else if c := s[common.Bytes2Hex(common.Hash{1}.Bytes())]; c.From != "01" || c.To != "02" {
+		t.Errorf("storage change mismatch: %v", c)
+	}
+	if c := diff.Accounts[common.Bytes2Hex(addr3[:])].Nonce; c == nil || c.From != "" || c.To != "1" {
+		t.Errorf("nonce change mismatch: %v", c)
+	}
+}
+
+func TestStorageRange(t *testing.T) {
+	mdb, _ := ethdb.NewMemDatabase()
+	db := trie.NewPreimageDatabase(mdb)
+	state := New(common.Hash{}, db)
+
+	addr := toAddr([]byte{0x01})
+	for i := byte(1); i <= 10; i++ {
+		state.SetState(addr, common.Hash{i}, []byte{i})
+	}
+	state.Update()
+	state.Sync()
+
+	seen := make(map[string]bool)
+	var start []byte
+	for pages := 0; ; pages++ {
+		if pages > 4 {
+			t.Fatalf("too many pages")
+		}
+		page := state.StorageRange(addr, start, 3)
+		for key, entry := range page.Storage {
+			if entry.Key == nil {
+				t.Errorf("missing preimage for %s", key)
+			}
+			seen[key] = true
+		}
+		if page.NextKey == nil {
+			break
+		}
+		start = common.FromHex(*page.NextKey)
+	}
+	if len(seen) != 10 {
+		t.Errorf("expected 10 slots, got %d", len(seen))
+	}
+}
+
+func TestStorageWrites(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state := New(common.Hash{}, db)
+
+	addr := toAddr([]byte{0x01})
+	state.SetState(addr, common.Hash{1}, []byte{1})
+	state.SetState(addr, common.Hash{2}, []byte{2})
+	state.Update()
+	if written, skipped := state.StorageWrites(); written != 2 || skipped != 0 {
+		t.Fatalf("storage writes mismatch: have %d/%d, want 2/0", written, skipped)
+	}
+
+	// Reads and writes restoring the committed value must not hit the trie
+	state.GetState(addr, common.Hash{1})
+	state.SetState(addr, common.Hash{2}, []byte{3})
+	state.SetState(addr, common.Hash{2}, []byte{2})
+	state.SetState(addr, common.Hash{3}, nil)
+	root := state.Root()
+	state.Update()
+	if written, skipped := state.StorageWrites(); written != 2 || skipped != 2 {
+		t.Errorf("storage writes mismatch: have %d/%d, want 2/2", written, skipped)
+	}
+	if state.Root() != root {
+		t.Errorf("root changed by no-op writes: have %x, want %x", state.Root(), root)
+	}
+
+	state.SetState(addr, common.Hash{1}, nil)
+	state.Update()
+	if written, _ := state.StorageWrites(); written != 3 {
+		t.Errorf("storage writes mismatch: have %d, want 3", written)
+	}
+	if value := state.GetState(addr, common.Hash{1}); len(value) != 0 {
+		t.Errorf("deleted slot has value %x", value)
+	}
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,12 +27,21 @@ type StateDB struct {
 	thash, bhash common.Hash
 	txIndex      int
 	logs         map[common.Hash]Logs
+	transfers    map[common.Hash][]*Transfer
+
+	recordTransfers bool
+
+	// Changes since the last Update, used to revert to snapshots
+	journal journal
+
+	// Storage slots written and skipped as unchanged by Update
+	storageWrites, storageSkips int
 }
 
 // Create a new state from a given trie
 func New(root common.Hash, db common.Database) *StateDB {
 	trie := trie.NewSecure(root[:], db)
-	return &StateDB{db: db, trie: trie, stateObjects: make(map[string]*StateObject), refund: make(map[string]*big.Int), logs: make(map[common.Hash]Logs)}
+	return &StateDB{db: db, trie: trie, stateObjects: make(map[string]*StateObject), refund: make(map[string]*big.Int), logs: make(map[common.Hash]Logs), transfers: make(map[common.Hash][]*Transfer)}
 }
 
 func (self *StateDB) PrintRoot() {
@@ -48,6 +58,7 @@ func (self *StateDB) AddLog(log *Log) {
 	log.TxHash = self.thash
 	log.BlockHash = self.bhash
 	log.TxIndex = uint(self.txIndex)
+	self.journal = append(self.journal, addLogChange{self.thash})
 	self.logs[self.thash] = append(self.logs[self.thash], log)
 }
 
@@ -66,7 +77,10 @@ func (self *StateDB) Logs() Logs {
 func (self *StateDB) Refund(address common.Address, gas *big.Int) {
 	addr := address.Str()
 	if self.refund[addr] == nil {
+		self.journal = append(self.journal, refundChange{addr, nil})
 		self.refund[addr] = new(big.Int)
+	} else {
+		self.journal = append(self.journal, refundChange{addr, new(big.Int).Set(self.refund[addr])})
 	}
 	self.refund[addr].Add(self.refund[addr], gas)
 }
@@ -107,6 +121,15 @@ func (self *StateDB) GetCode(addr common.Address) []byte {
 	return nil
 }
 
+func (self *StateDB) GetCodeHash(addr common.Address) common.Hash {
+	stateObject := self.GetStateObject(addr)
+	if stateObject != nil {
+		return common.BytesToHash(stateObject.CodeHash())
+	}
+
+	return common.Hash{}
+}
+
 func (self *StateDB) GetState(a common.Address, b common.Hash) []byte {
 	stateObject := self.GetStateObject(a)
 	if stateObject != nil {
@@ -159,6 +182,7 @@ func (self *StateDB) SetState(addr common.Address, key common.Hash, value interf
 func (self *StateDB) Delete(addr common.Address) bool {
 	stateObject := self.GetStateObject(addr)
 	if stateObject != nil {
+		self.journal = append(self.journal, suicideChange{stateObject, stateObject.remove, stateObject.balance})
 		stateObject.MarkForDeletion()
 		stateObject.balance = new(big.Int)
 
@@ -213,6 +237,7 @@ func (self *StateDB) GetStateObject(addr common.Address) *StateObject {
 }
 
 func (self *StateDB) SetStateObject(object *StateObject) {
+	object.owner = self
 	self.stateObjects[object.Address().Str()] = object
 }
 
@@ -233,6 +258,7 @@ func (self *StateDB) newStateObject(addr common.Address) *StateObject {
 	}
 
 	stateObject := NewStateObject(addr, self.db)
+	stateObject.owner = self
 	self.stateObjects[addr.Str()] = stateObject
 
 	return stateObject
@@ -244,6 +270,7 @@ func (self *StateDB) CreateAccount(addr common.Address) *StateObject {
 	so := self.GetStateObject(addr)
 	// Create a new one
 	newSo := self.newStateObject(addr)
+	self.journal = append(self.journal, createObjectChange{addr, so})
 
 	// If it existed set the balance to the new account
 	if so != nil {
@@ -266,6 +293,7 @@ func (self *StateDB) Copy() *StateDB {
 	state.trie = self.trie.Copy()
 	for k, stateObject := range self.stateObjects {
 		state.stateObjects[k] = stateObject.Copy()
+		state.stateObjects[k].owner = state
 	}
 
 	for addr, refund := range self.refund {
@@ -276,6 +304,12 @@ func (self *StateDB) Copy() *StateDB {
 		state.logs[hash] = make(Logs, len(logs))
 		copy(state.logs[hash], logs)
 	}
+	for hash, transfers := range self.transfers {
+		state.transfers[hash] = make([]*Transfer, len(transfers))
+		copy(state.transfers[hash], transfers)
+	}
+	state.recordTransfers = self.recordTransfers
+	state.storageWrites, state.storageSkips = self.storageWrites, self.storageSkips
 
 	return state
 }
@@ -283,9 +317,33 @@ func (self *StateDB) Copy() *StateDB {
 func (self *StateDB) Set(state *StateDB) {
 	self.trie = state.trie
 	self.stateObjects = state.stateObjects
+	for _, stateObject := range self.stateObjects {
+		stateObject.owner = self
+	}
 
 	self.refund = state.refund
 	self.logs = state.logs
+	self.transfers = state.transfers
+	self.journal = state.journal
+	self.storageWrites, self.storageSkips = state.storageWrites, state.storageSkips
+}
+
+// Snapshot returns an identifier for the current revision of the state.
+// Snapshots are invalidated by Update, Sync and Reset.
+func (self *StateDB) Snapshot() int {
+	return len(self.journal)
+}
+
+// RevertToSnapshot undoes all changes made since the given snapshot was taken.
+// It panics if the snapshot was invalidated.
+func (self *StateDB) RevertToSnapshot(id int) {
+	if id < 0 || id > len(self.journal) {
+		panic(fmt.Sprintf("invalid snapshot id %d, journal length %d", id, len(self.journal)))
+	}
+	for i := len(self.journal) - 1; i >= id; i-- {
+		self.journal[i].undo(self)
+	}
+	self.journal = self.journal[:id]
 }
 
 func (s *StateDB) Root() common.Hash {
@@ -331,6 +389,7 @@ func (s *StateDB) Sync() {
 func (self *StateDB) Empty() {
 	self.stateObjects = make(map[string]*StateObject)
 	self.refund = make(map[string]*big.Int)
+	self.journal = nil
 }
 
 func (self *StateDB) Refunds() map[string]*big.Int {
@@ -339,13 +398,16 @@ func (self *StateDB) Refunds() map[string]*big.Int {
 
 func (self *StateDB) Update() {
 	self.refund = make(map[string]*big.Int)
+	self.journal = nil
 
 	for _, stateObject := range self.stateObjects {
 		if stateObject.dirty {
 			if stateObject.remove {
 				self.DeleteStateObject(stateObject)
 			} else {
-				stateObject.Sync()
+				written, skipped := stateObject.Sync()
+				self.storageWrites += written
+				self.storageSkips += skipped
 
 				self.UpdateStateObject(stateObject)
 			}
@@ -354,6 +416,12 @@ func (self *StateDB) Update() {
 	}
 }
 
+// StorageWrites returns the number of storage slots written to the storage
+// tries and the number of writes skipped because the slot was unchanged.
+func (self *StateDB) StorageWrites() (written, skipped int) {
+	return self.storageWrites, self.storageSkips
+}
+
 // Debug stuff
 func (self *StateDB) CreateOutputForDiff() {
 	for _, stateObject := range self.stateObjects {
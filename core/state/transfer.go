@@ -0,0 +1,43 @@
+package state
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Transfer is a value transfer made by a contract while executing a
+// transaction, as opposed to the one made by the transaction itself.
+type Transfer struct {
+	TxIndex uint
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+}
+
+// RecordTransfers enables recording the value transfers made by contracts.
+func (self *StateDB) RecordTransfers() {
+	self.recordTransfers = true
+}
+
+// AddTransfer records a value transfer of the current transaction if
+// recording is enabled. Like logs, transfers are undone when reverting to an
+// earlier snapshot.
+func (self *StateDB) AddTransfer(from, to common.Address, value *big.Int) {
+	if !self.recordTransfers {
+		return
+	}
+	self.journal = append(self.journal, addTransferChange{self.thash})
+	self.transfers[self.thash] = append(self.transfers[self.thash], &Transfer{
+		TxIndex: uint(self.txIndex),
+		From:    from,
+		To:      to,
+		Value:   new(big.Int).Set(value),
+	})
+}
+
+// GetTransfers returns the value transfers made by the transaction with the
+// given hash.
+func (self *StateDB) GetTransfers(hash common.Hash) []*Transfer {
+	return self.transfers[hash]
+}
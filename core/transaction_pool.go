@@ -87,6 +87,9 @@ func (pool *TxPool) Start() {
 	queueTimer := time.NewTicker(300 * time.Millisecond)
 	// Removal timer will tick and attempt to remove bad transactions (account.nonce>tx.nonce)
 	removalTimer := time.NewTicker(1 * time.Second)
+	// Transactions dropped from the canonical chain by a reorg are added back
+	events := pool.eventMux.Subscribe(RemovedTransactionEvent{})
+	defer events.Unsubscribe()
 done:
 	for {
 		select {
@@ -94,6 +97,10 @@ done:
 			pool.checkQueue()
 		case <-removalTimer.C:
 			pool.validatePool()
+		case ev := <-events.Chan():
+			if ev, ok := ev.(RemovedTransactionEvent); ok {
+				pool.AddTransactions(ev.Txs)
+			}
 		case <-pool.quit:
 			break done
 		}
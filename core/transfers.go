@@ -0,0 +1,86 @@
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// blockTransfersPre prefixes the internal value transfers of a block.
+var blockTransfersPre = []byte("block-transfers-")
+
+// transferProcessor is implemented by block processors which know the
+// internal value transfers of the blocks they processed.
+type transferProcessor interface {
+	Transfers(block *types.Block) ([]*state.Transfer, error)
+	RecordedTransfers(block *types.Block) ([]*state.Transfer, bool)
+}
+
+// RecordedTransfers returns the value transfers recorded when block was
+// processed, which is only done while the address index is enabled. ok is
+// false if none were recorded.
+func (sm *BlockProcessor) RecordedTransfers(block *types.Block) (transfers []*state.Transfer, ok bool) {
+	data, _ := sm.extraDb.Get(append(blockTransfersPre, block.Hash().Bytes()...))
+	if len(data) == 0 {
+		return nil, false
+	}
+	if err := rlp.DecodeBytes(data, &transfers); err != nil {
+		glog.V(logger.Error).Infof("invalid transfers of block %x: %v\n", block.Hash().Bytes()[:4], err)
+		return nil, false
+	}
+	return transfers, true
+}
+
+// Transfers returns the value transfers made by contracts in the
+// transactions of block. Blocks whose transfers weren't recorded are
+// executed again on their parent's state, without writing to the database.
+func (sm *BlockProcessor) Transfers(block *types.Block) ([]*state.Transfer, error) {
+	if transfers, ok := sm.RecordedTransfers(block); ok {
+		return transfers, nil
+	}
+
+	parent := sm.bc.GetBlock(block.ParentHash())
+	if parent == nil {
+		return nil, ParentError(block.ParentHash())
+	}
+	statedb := state.New(parent.Root(), newReplayDatabase(sm.db))
+	statedb.RecordTransfers()
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(block.GasLimit())
+
+	usedGas := new(big.Int)
+	for i, tx := range block.Transactions() {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+		if _, _, err := sm.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true); err != nil && (IsNonceErr(err) || state.IsGasLimitErr(err) || IsInvalidTxErr(err)) {
+			return nil, err
+		}
+	}
+	transfers := blockTransfers(statedb, block)
+	putTransfers(sm.extraDb, block, transfers)
+
+	return transfers, nil
+}
+
+// blockTransfers collects the transfers recorded by statedb for the
+// transactions of block, in order.
+func blockTransfers(statedb *state.StateDB, block *types.Block) []*state.Transfer {
+	transfers := []*state.Transfer{}
+	for _, tx := range block.Transactions() {
+		transfers = append(transfers, statedb.GetTransfers(tx.Hash())...)
+	}
+	return transfers
+}
+
+func putTransfers(db common.Database, block *types.Block, transfers []*state.Transfer) {
+	enc, err := rlp.EncodeToBytes(transfers)
+	if err != nil {
+		glog.V(logger.Debug).Infoln("Failed encoding transfers", err)
+		return
+	}
+	db.Put(append(blockTransfersPre, block.Hash().Bytes()...), enc)
+}
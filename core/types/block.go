@@ -99,7 +99,8 @@ type Block struct {
 	Td           *big.Int
 	queued       bool // flag for blockpool to skip TD check
 
-	ReceivedAt time.Time
+	ReceivedAt   time.Time
+	ReceivedFrom string // id of the peer the block came from, empty for local blocks
 
 	receipts Receipts
 }
@@ -26,10 +26,36 @@ func (self *Receipt) SetLogs(logs state.Logs) {
 	self.logs = logs
 }
 
+func (self *Receipt) Logs() state.Logs {
+	return self.logs
+}
+
 func (self *Receipt) EncodeRLP(w io.Writer) error {
 	return rlp.Encode(w, []interface{}{self.PostState, self.CumulativeGasUsed, self.Bloom, self.logs})
 }
 
+func (self *Receipt) DecodeRLP(s *rlp.Stream) error {
+	var r struct {
+		PostState         []byte
+		CumulativeGasUsed *big.Int
+		Bloom             Bloom
+		Logs              []struct {
+			Address common.Address
+			Topics  []common.Hash
+			Data    []byte
+		}
+	}
+	if err := s.Decode(&r); err != nil {
+		return err
+	}
+	self.PostState, self.CumulativeGasUsed, self.Bloom = r.PostState, r.CumulativeGasUsed, r.Bloom
+	self.logs = make(state.Logs, len(r.Logs))
+	for i, log := range r.Logs {
+		self.logs[i] = state.NewLog(log.Address, log.Topics, log.Data, 0)
+	}
+	return nil
+}
+
 func (self *Receipt) RlpEncode() []byte {
 	bytes, err := rlp.EncodeToBytes(self)
 	if err != nil {
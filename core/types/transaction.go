@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -27,6 +28,17 @@ type Transaction struct {
 	Payload      []byte
 	V            byte
 	R, S         *big.Int
+
+	from atomic.Value // cached sender, see From
+}
+
+// sigCache is the sender recovered from a signature, along with the values
+// it was recovered from.
+type sigCache struct {
+	hash common.Hash
+	v    byte
+	r, s big.Int
+	from common.Address
 }
 
 func NewContractCreationTx(amount, gasLimit, gasPrice *big.Int, data []byte) *Transaction {
@@ -92,7 +104,15 @@ func (self *Transaction) SetNonce(AccountNonce uint64) {
 	self.AccountNonce = AccountNonce
 }
 
+// From returns the sender of the transaction. The result of the signature
+// recovery is cached, so that verifying the senders of a block up front makes
+// later calls cheap. The cache is bypassed once the transaction changes.
 func (self *Transaction) From() (common.Address, error) {
+	hash := self.Hash()
+	if sc, ok := self.from.Load().(*sigCache); ok && sc.hash == hash && sc.v == self.V && sc.r.Cmp(self.R) == 0 && sc.s.Cmp(self.S) == 0 {
+		return sc.from, nil
+	}
+
 	pubkey := self.PublicKey()
 	if len(pubkey) == 0 || pubkey[0] != 4 {
 		return common.Address{}, errors.New("invalid public key")
@@ -100,6 +120,12 @@ func (self *Transaction) From() (common.Address, error) {
 
 	var addr common.Address
 	copy(addr[:], crypto.Sha3(pubkey[1:])[12:])
+
+	sc := &sigCache{hash: hash, v: self.V, from: addr}
+	sc.r.Set(self.R)
+	sc.s.Set(self.S)
+	self.from.Store(sc)
+
 	return addr, nil
 }
 
@@ -109,3 +109,28 @@ func TestRecipientNormal(t *testing.T) {
 		t.Error("derived address doesn't match")
 	}
 }
+
+func TestTransactionFromCache(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := common.BytesToAddress(crypto.PubkeyToAddress(key.PublicKey))
+
+	tx := NewTransactionMessage(common.Address{}, big.NewInt(0), big.NewInt(21000), big.NewInt(0), nil)
+	if err := tx.SignECDSA(key); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if from, err := tx.From(); err != nil || from != addr {
+			t.Fatalf("call %d: sender mismatch: have %x (%v), want %x", i, from, err, addr)
+		}
+	}
+
+	// the cached sender must not survive changes to the transaction
+	tx.SetNonce(1)
+	if from, _ := tx.From(); from == addr {
+		t.Errorf("stale sender returned after nonce change")
+	}
+	tx.SignECDSA(key)
+	if from, err := tx.From(); err != nil || from != addr {
+		t.Errorf("sender mismatch after re-signing: have %x (%v), want %x", from, err, addr)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"runtime"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// verifyWorkers is the number of goroutines verifying blocks during import,
+// 0 meaning GOMAXPROCS.
+var verifyWorkers = 0
+
+// blockVerifier is implemented by block processors able to check blocks
+// ahead of processing them.
+type blockVerifier interface {
+	VerifyBlocks(blocks types.Blocks, abort <-chan struct{}) <-chan error
+}
+
+// VerifyBlocks checks the seals of blocks and recovers the senders of their
+// transactions concurrently. The returned channel yields the outcome for
+// each block in order, nil entries included. Closing abort stops the
+// verification; it must be closed once the results are no longer needed.
+//
+// Blocks with a valid seal are remembered so that processing them doesn't
+// verify the seal again.
+func (sm *BlockProcessor) VerifyBlocks(blocks types.Blocks, abort <-chan struct{}) <-chan error {
+	workers := verifyWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+
+	var (
+		next = int32(-1)
+		errs = make([]error, len(blocks))
+		done = make([]chan struct{}, len(blocks))
+		out  = make(chan error, len(blocks))
+	)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for {
+				i := int(atomic.AddInt32(&next, 1))
+				if i >= len(blocks) {
+					return
+				}
+				select {
+				case <-abort:
+					return
+				default:
+				}
+				if blocks[i] != nil {
+					errs[i] = sm.verifyBlock(blocks[i], abort)
+				}
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		defer sm.forgetVerified(blocks)
+		for i := range blocks {
+			select {
+			case <-done[i]:
+				out <- errs[i]
+			case <-abort:
+				return
+			}
+		}
+		<-abort
+	}()
+
+	return out
+}
+
+// verifyBlock checks the seal of block and caches the senders of its
+// transactions. Invalid signatures are left for the state transition to
+// reject.
+func (sm *BlockProcessor) verifyBlock(block *types.Block, abort <-chan struct{}) error {
+	if !sm.Pow.Verify(block) {
+		return PowError(block.Header().Nonce)
+	}
+	for _, tx := range block.Transactions() {
+		tx.From()
+	}
+
+	sm.verifiedMu.Lock()
+	defer sm.verifiedMu.Unlock()
+	select {
+	case <-abort:
+		// forgetVerified may have run already
+	default:
+		sm.verified[block.Hash()] = true
+	}
+	return nil
+}
+
+// sealVerified reports whether the seal of the block with the given hash was
+// verified by VerifyBlocks.
+func (sm *BlockProcessor) sealVerified(hash common.Hash) bool {
+	sm.verifiedMu.Lock()
+	defer sm.verifiedMu.Unlock()
+
+	return sm.verified[hash]
+}
+
+func (sm *BlockProcessor) forgetVerified(blocks types.Blocks) {
+	sm.verifiedMu.Lock()
+	defer sm.verifiedMu.Unlock()
+
+	for _, block := range blocks {
+		if block != nil {
+			delete(sm.verified, block.Hash())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/pow"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// makeTxChain creates n blocks on top of the genesis of bp, each holding
+// txs signed value transfers, and sets their receipts and headers the way a
+// miner would.
+func makeTxChain(bp *BlockProcessor, db common.Database, n, txs int) types.Blocks {
+	key, _ := crypto.GenerateKey()
+	to := common.BytesToAddress([]byte{0xaa})
+
+	var (
+		parent = bp.bc.Genesis()
+		nonce  uint64
+		chain  types.Blocks
+	)
+	for i := 0; i < n; i++ {
+		block := newBlockFromParent(common.BytesToAddress([]byte{0xcb}), parent)
+
+		var btxs types.Transactions
+		for j := 0; j < txs; j++ {
+			tx := types.NewTransactionMessage(to, common.Big0, big.NewInt(21000), common.Big0, nil)
+			tx.SetNonce(nonce)
+			tx.SignECDSA(key)
+			btxs = append(btxs, tx)
+			nonce++
+		}
+		finishTxBlock(bp, db, block, parent, btxs)
+
+		chain = append(chain, block)
+		parent = block
+	}
+	return chain
+}
+
+// finishTxBlock executes txs in block on the state of parent and sets the
+// block's transactions, receipts and header fields.
+func finishTxBlock(bp *BlockProcessor, db common.Database, block, parent *types.Block, txs types.Transactions) {
+	block.SetTransactions(txs)
+
+	statedb := state.New(parent.Root(), db)
+	coinbase := statedb.GetOrNewStateObject(block.Coinbase())
+	coinbase.SetGasPool(block.GasLimit())
+
+	var (
+		receipts types.Receipts
+		usedGas  = new(big.Int)
+	)
+	for i, tx := range txs {
+		statedb.StartRecord(tx.Hash(), block.Hash(), i)
+		receipt, _, err := bp.ApplyTransaction(coinbase, statedb, block, tx, usedGas, true)
+		if err != nil {
+			panic(err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	block.Header().GasUsed = usedGas
+	block.SetReceipts(receipts)
+	AccumulateRewards(statedb, block)
+	statedb.Update()
+	block.SetRoot(statedb.Root())
+	statedb.Sync()
+
+	block.Td = CalculateTD(block, parent)
+}
+
+// rejectPow accepts all blocks but one.
+type rejectPow struct {
+	FakePow
+	bad common.Hash
+}
+
+func (self rejectPow) Verify(block pow.Block) bool { return block.HashNoNonce() != self.bad }
+
+func TestInsertChainInvalidSeal(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+	chain := makeTxChain(bp, db, 5, 2)
+
+	db, _ = ethdb.NewMemDatabase()
+	bp, _ = newCanonical(0, db)
+	bp.Pow = rejectPow{bad: chain[2].HashNoNonce()}
+
+	i, err := bp.bc.InsertChain(chain)
+	if err == nil || i != 2 {
+		t.Fatalf("insertion mismatch: have (%d, %v), want failure at 2", i, err)
+	}
+	if head := bp.bc.CurrentBlock(); head.Hash() != chain[1].Hash() {
+		t.Errorf("head mismatch: have #%v, want #%v", head.Number(), chain[1].Number())
+	}
+	if bp.bc.HasBlock(chain[3].Hash()) {
+		t.Errorf("block after the invalid one was imported")
+	}
+	// seals are forgotten in the background once the import is done
+	for i := 0; i < 100 && verifiedCount(bp) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := verifiedCount(bp); n != 0 {
+		t.Errorf("verified seals not forgotten: %d left", n)
+	}
+}
+
+func verifiedCount(bp *BlockProcessor) int {
+	bp.verifiedMu.Lock()
+	defer bp.verifiedMu.Unlock()
+
+	return len(bp.verified)
+}
+
+func TestInsertChainSerialVerification(t *testing.T) {
+	defer func(n int) { verifyWorkers = n }(verifyWorkers)
+	verifyWorkers = 1
+
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+	chain := makeTxChain(bp, db, 5, 2)
+
+	db, _ = ethdb.NewMemDatabase()
+	bp, _ = newCanonical(0, db)
+	if _, err := bp.bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	if head := bp.bc.CurrentBlock(); head.Hash() != chain[4].Hash() {
+		t.Errorf("head mismatch: have #%v, want #%v", head.Number(), chain[4].Number())
+	}
+}
+
+func benchmarkInsertChain(b *testing.B, workers int) {
+	defer func(n int) { verifyWorkers = n }(verifyWorkers)
+	verifyWorkers = workers
+
+	db, _ := ethdb.NewMemDatabase()
+	bp, _ := newCanonical(0, db)
+	encoded := make([][]byte, 0, 100)
+	for _, block := range makeTxChain(bp, db, 100, 50) {
+		enc, _ := rlp.EncodeToBytes(block)
+		encoded = append(encoded, enc)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Decode the blocks again as an import would, so no senders are cached
+		b.StopTimer()
+		chain := make(types.Blocks, len(encoded))
+		for j, enc := range encoded {
+			chain[j] = new(types.Block)
+			rlp.DecodeBytes(enc, chain[j])
+		}
+		db, _ := ethdb.NewMemDatabase()
+		bp, _ := newCanonical(0, db)
+		bp.Pow = SlowPow{SlowPowRounds}
+		b.StartTimer()
+
+		if _, err := bp.bc.InsertChain(chain); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInsertChain(b *testing.B)       { benchmarkInsertChain(b, 0) }
+func BenchmarkInsertChainSerial(b *testing.B) { benchmarkInsertChain(b, 1) }
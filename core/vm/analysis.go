@@ -1,36 +1,103 @@
 package vm
 
 import (
-	"math/big"
+	"container/list"
+	"sync"
 
-	"gopkg.in/fatih/set.v0"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-type destinations struct {
-	set *set.Set
-}
+// destinations is a bitmap of the valid jump destinations in a piece of
+// code, one bit per code byte.
+type destinations []byte
 
-func (d *destinations) Has(dest *big.Int) bool {
-	return d.set.Has(string(dest.Bytes()))
+func (d destinations) Has(dest uint64) bool {
+	if dest >= uint64(len(d))*8 {
+		return false
+	}
+	return d[dest/8]&(1<<(dest%8)) != 0
 }
 
-func (d *destinations) Add(dest *big.Int) {
-	d.set.Add(string(dest.Bytes()))
+func (d destinations) Add(dest uint64) {
+	d[dest/8] |= 1 << (dest % 8)
 }
 
-func analyseJumpDests(code []byte) (dests *destinations) {
-	dests = &destinations{set.New()}
+func analyseJumpDests(code []byte) destinations {
+	dests := make(destinations, (len(code)+7)/8)
 
 	for pc := uint64(0); pc < uint64(len(code)); pc++ {
 		var op OpCode = OpCode(code[pc])
-		switch op {
-		case PUSH1, PUSH2, PUSH3, PUSH4, PUSH5, PUSH6, PUSH7, PUSH8, PUSH9, PUSH10, PUSH11, PUSH12, PUSH13, PUSH14, PUSH15, PUSH16, PUSH17, PUSH18, PUSH19, PUSH20, PUSH21, PUSH22, PUSH23, PUSH24, PUSH25, PUSH26, PUSH27, PUSH28, PUSH29, PUSH30, PUSH31, PUSH32:
-			a := uint64(op) - uint64(PUSH1) + 1
+		switch {
+		case op >= PUSH1 && op <= PUSH32:
+			pc += uint64(op) - uint64(PUSH1) + 1
+		case op == JUMPDEST:
+			dests.Add(pc)
+		}
+	}
+	return dests
+}
+
+// jumpdestCacheSize is the number of analysed contracts kept in memory.
+const jumpdestCacheSize = 4096
 
-			pc += a
-		case JUMPDEST:
-			dests.Add(big.NewInt(int64(pc)))
+// jumpdests caches the analysis of recently run code across VM instances.
+var jumpdests = newDestCache(jumpdestCacheSize)
+
+// destCache is an LRU cache of jump destination analyses keyed by code hash.
+type destCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[common.Hash]*list.Element
+	order *list.List // front is the most recently used
+}
+
+type destEntry struct {
+	hash  common.Hash
+	dests destinations
+}
+
+func newDestCache(size int) *destCache {
+	return &destCache{
+		size:  size,
+		items: make(map[common.Hash]*list.Element),
+		order: list.New(),
+	}
+}
+
+// get returns the jump destinations of code, analysing it only if hash
+// hasn't been seen recently. A zero hash bypasses the cache.
+func (self *destCache) get(hash common.Hash, code []byte) destinations {
+	if hash == (common.Hash{}) {
+		return analyseJumpDests(code)
+	}
+
+	self.mu.Lock()
+	if elem, ok := self.items[hash]; ok {
+		self.order.MoveToFront(elem)
+		self.mu.Unlock()
+
+		return elem.Value.(*destEntry).dests
+	}
+	self.mu.Unlock()
+
+	dests := analyseJumpDests(code)
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if _, ok := self.items[hash]; !ok {
+		self.items[hash] = self.order.PushFront(&destEntry{hash, dests})
+		if self.order.Len() > self.size {
+			oldest := self.order.Back()
+			self.order.Remove(oldest)
+			delete(self.items, oldest.Value.(*destEntry).hash)
 		}
 	}
-	return
+	return dests
+}
+
+func (self *destCache) len() int {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	return self.order.Len()
 }
@@ -0,0 +1,78 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestJumpDestAnalysis(t *testing.T) {
+	code := []byte{
+		byte(JUMPDEST),
+		byte(PUSH2), byte(JUMPDEST), byte(JUMPDEST), // push data isn't a destination
+		byte(JUMPDEST),
+		byte(PUSH1), 0x00,
+		byte(STOP), byte(JUMPDEST),
+		byte(PUSH32), // truncated push data
+	}
+	dests := analyseJumpDests(code)
+	for pc := uint64(0); pc < 100; pc++ {
+		want := pc == 0 || pc == 4 || pc == 8
+		if dests.Has(pc) != want {
+			t.Errorf("destination %d: have %v, want %v", pc, dests.Has(pc), want)
+		}
+	}
+}
+
+func TestDestCache(t *testing.T) {
+	cache := newDestCache(2)
+	codes := [][]byte{{byte(JUMPDEST)}, {byte(STOP), byte(JUMPDEST)}, {byte(STOP), byte(STOP), byte(JUMPDEST)}}
+
+	for i, code := range codes {
+		dests := cache.get(crypto.Sha3Hash(code), code)
+		if !dests.Has(uint64(i)) {
+			t.Errorf("code %d: destination %d missing", i, i)
+		}
+	}
+	if cache.len() != 2 {
+		t.Errorf("cache size mismatch: have %d, want 2", cache.len())
+	}
+	if _, ok := cache.items[crypto.Sha3Hash(codes[0])]; ok {
+		t.Errorf("least recently used code not evicted")
+	}
+
+	// Unknown code hashes must not be cached
+	cache.get(common.Hash{}, codes[0])
+	if _, ok := cache.items[common.Hash{}]; ok {
+		t.Errorf("zero hash cached")
+	}
+}
+
+func benchmarkCode() []byte {
+	code := make([]byte, 24000)
+	for i := range code {
+		code[i] = byte(i)
+	}
+	return code
+}
+
+func BenchmarkJumpDestAnalysis(b *testing.B) {
+	code := benchmarkCode()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyseJumpDests(code)
+	}
+}
+
+func BenchmarkJumpDestCached(b *testing.B) {
+	code := benchmarkCode()
+	hash := crypto.Sha3Hash(code)
+	cache := newDestCache(jumpdestCacheSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.get(hash, code)
+	}
+}
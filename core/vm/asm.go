@@ -1,8 +1,11 @@
 package vm
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -43,3 +46,272 @@ func Disassemble(script []byte) (asm []string) {
 
 	return
 }
+
+// stringToOp maps mnemonics back to their opcodes.
+var stringToOp = make(map[string]OpCode)
+
+func init() {
+	for op, name := range opCodeToString {
+		stringToOp[name] = op
+	}
+}
+
+// asmItem is a single instruction or data section of assembly source.
+type asmItem struct {
+	line int
+	op   OpCode
+	data []byte // raw bytes of a data section
+
+	// Push arguments
+	size  int      // number of push bytes
+	auto  bool     // size derived from the value
+	value *big.Int // constant pushed
+	label string   // label whose offset is pushed
+}
+
+func (self *asmItem) isPush() bool {
+	return self.data == nil && self.op >= PUSH1 && self.op <= PUSH32
+}
+
+func (self *asmItem) len() int {
+	if self.data != nil {
+		return len(self.data)
+	}
+	return 1 + self.size
+}
+
+// Assemble compiles assembly source to bytecode. The source holds one
+// instruction per line, written as its mnemonic followed by the push value
+// if any. Comments start with ';'.
+//
+//	start:              ; labels end with a colon
+//	    PUSH1 0x60      ; explicitly sized push
+//	    PUSH 1000       ; push sized to fit its value
+//	    PUSH @start     ; push of a label's offset, sized to fit
+//	    JUMP @start     ; shorthand for PUSH @start, JUMP (also JUMPI)
+//	    DATA 0xdeadbeef ; raw bytes
+func Assemble(src string) ([]byte, error) {
+	var (
+		items  []*asmItem
+		labels = make(map[string]int) // label => index of the following item
+	)
+	for i, line := range strings.Split(src, "\n") {
+		if n := strings.Index(line, ";"); n >= 0 {
+			line = line[:n]
+		}
+		fields := strings.Fields(line)
+		for len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+			label := strings.TrimSuffix(fields[0], ":")
+			if _, ok := labels[label]; ok {
+				return nil, fmt.Errorf("line %d: label %s redefined", i+1, label)
+			}
+			labels[label] = len(items)
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		parsed, err := parseAsmLine(i+1, fields)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, parsed...)
+	}
+	for _, item := range items {
+		if _, ok := labels[item.label]; item.label != "" && !ok {
+			return nil, fmt.Errorf("line %d: undefined label %s", item.line, item.label)
+		}
+	}
+
+	// Label offsets depend on the size of the pushes referring to them. Push
+	// sizes only ever grow, so iterate until they are stable.
+	var offsets []int
+	for {
+		offsets = make([]int, len(items)+1)
+		for i, item := range items {
+			offsets[i+1] = offsets[i] + item.len()
+		}
+		stable := true
+		for _, item := range items {
+			if item.label == "" {
+				continue
+			}
+			size := byteSize(big.NewInt(int64(offsets[labels[item.label]])))
+			switch {
+			case item.auto && size > item.size:
+				item.size, item.op, stable = size, PUSH1+OpCode(size-1), false
+			case !item.auto && size > item.size:
+				return nil, fmt.Errorf("line %d: offset of %s doesn't fit in %v", item.line, item.label, item.op)
+			}
+		}
+		if stable {
+			break
+		}
+	}
+
+	code := make([]byte, 0, offsets[len(items)])
+	for _, item := range items {
+		if item.data != nil {
+			code = append(code, item.data...)
+			continue
+		}
+		code = append(code, byte(item.op))
+		if item.isPush() {
+			value := item.value
+			if item.label != "" {
+				value = big.NewInt(int64(offsets[labels[item.label]]))
+			}
+			code = append(code, common.LeftPadBytes(value.Bytes(), item.size)...)
+		}
+	}
+	return code, nil
+}
+
+func parseAsmLine(line int, fields []string) ([]*asmItem, error) {
+	mnemonic, args := strings.ToUpper(fields[0]), fields[1:]
+	if mnemonic == "DATA" {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("line %d: DATA without bytes", line)
+		}
+		item := &asmItem{line: line, data: []byte{}}
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "0x") || len(arg)%2 != 0 {
+				return nil, fmt.Errorf("line %d: invalid data %s", line, arg)
+			}
+			b, err := hex.DecodeString(arg[2:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid data %s", line, arg)
+			}
+			item.data = append(item.data, b...)
+		}
+		return []*asmItem{item}, nil
+	}
+
+	op, ok := stringToOp[mnemonic]
+	if !ok && mnemonic != "PUSH" {
+		return nil, fmt.Errorf("line %d: unknown instruction %s", line, fields[0])
+	}
+	item := &asmItem{line: line, op: op}
+	switch {
+	case mnemonic == "PUSH" || item.isPush():
+		if len(args) != 1 {
+			return nil, fmt.Errorf("line %d: %s takes one argument", line, mnemonic)
+		}
+		if err := item.setPushArg(args[0], mnemonic == "PUSH"); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		return []*asmItem{item}, nil
+
+	case (op == JUMP || op == JUMPI) && len(args) == 1:
+		push := &asmItem{line: line}
+		if err := push.setPushArg(args[0], true); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		return []*asmItem{push, item}, nil
+
+	case len(args) > 0:
+		return nil, fmt.Errorf("line %d: %s takes no arguments", line, mnemonic)
+	}
+	return []*asmItem{item}, nil
+}
+
+// setPushArg sets the label or value pushed by a push item. Auto sized
+// pushes are given the smallest size fitting the value.
+func (self *asmItem) setPushArg(arg string, auto bool) error {
+	self.auto = auto
+	if !auto {
+		self.size = int(self.op-PUSH1) + 1
+	}
+	if strings.HasPrefix(arg, "@") {
+		self.label = arg[1:]
+		if auto {
+			self.size = 1
+		}
+	} else {
+		value, ok := new(big.Int), false
+		if strings.HasPrefix(arg, "0x") {
+			value, ok = value.SetString(arg[2:], 16)
+		} else {
+			value, ok = value.SetString(arg, 10)
+		}
+		if !ok || value.Sign() < 0 {
+			return fmt.Errorf("invalid value %s", arg)
+		}
+		size := byteSize(value)
+		if auto {
+			self.size = size
+		}
+		if size > self.size {
+			return fmt.Errorf("value %s doesn't fit in %d bytes", arg, self.size)
+		}
+		self.value = value
+	}
+	self.op = PUSH1 + OpCode(self.size-1)
+	return nil
+}
+
+// byteSize returns the number of bytes needed to push x, at least one.
+func byteSize(x *big.Int) int {
+	if size := (x.BitLen() + 7) / 8; size > 0 {
+		return size
+	}
+	return 1
+}
+
+// DisassembleSource returns the source of code in the format accepted by
+// Assemble, with the offset of every instruction in a comment. JUMPDESTs are
+// labeled and pushes of constant jump targets refer to the labels. Bytes
+// which aren't valid instructions are written as data.
+func DisassembleSource(code []byte) string {
+	type instruction struct {
+		pc  int
+		op  OpCode
+		arg []byte
+	}
+	var instructions []instruction
+	for pc := 0; pc < len(code); pc++ {
+		ins := instruction{pc: pc, op: OpCode(code[pc])}
+		if ins.op >= PUSH1 && ins.op <= PUSH32 {
+			end := pc + 1 + int(ins.op-PUSH1) + 1
+			if end > len(code) {
+				end = len(code)
+			}
+			ins.arg = code[pc+1 : end]
+			pc = end - 1
+		}
+		instructions = append(instructions, ins)
+	}
+
+	var (
+		buf   bytes.Buffer
+		dests = analyseJumpDests(code)
+	)
+	for i, ins := range instructions {
+		var text string
+		switch {
+		case ins.op == JUMPDEST:
+			fmt.Fprintf(&buf, "L%04d:\n", ins.pc)
+			text = ins.op.String()
+		case ins.op >= PUSH1 && ins.op <= PUSH32:
+			if len(ins.arg) < int(ins.op-PUSH1)+1 {
+				// Truncated push data at the end of the code
+				text = fmt.Sprintf("DATA 0x%x", code[ins.pc:])
+				break
+			}
+			text = fmt.Sprintf("%v 0x%x", ins.op, ins.arg)
+			if i+1 < len(instructions) && (instructions[i+1].op == JUMP || instructions[i+1].op == JUMPI) {
+				if target := new(big.Int).SetBytes(ins.arg); target.BitLen() <= 32 && dests.Has(target.Uint64()) {
+					text = fmt.Sprintf("%v @L%04d", ins.op, target.Uint64())
+				}
+			}
+		default:
+			if _, ok := opCodeToString[ins.op]; ok {
+				text = ins.op.String()
+			} else {
+				text = fmt.Sprintf("DATA 0x%02x", byte(ins.op))
+			}
+		}
+		fmt.Fprintf(&buf, "    %-32s ; %04d\n", text, ins.pc)
+	}
+	return buf.String()
+}
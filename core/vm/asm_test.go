@@ -0,0 +1,97 @@
+package vm
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		src  string
+		code string
+	}{
+		{"PUSH1 0x60 ; comment\nPUSH1 96\nadd", "6060606001"},
+		{"PUSH 0\nPUSH 0x1234\nPUSH2 0x01", "600061123461 0001"},
+		{"start:\nJUMPDEST\nJUMP @start", "5b600056"},
+		{"JUMPI @end\nend: JUMPDEST", "6003575b"},
+		{"PUSH @data\nSTOP\ndata:\nDATA 0xdead 0xbeef", "600300deadbeef"},
+		{"PUSH32 0x01", "7f" + strings.Repeat("00", 31) + "01"},
+	}
+	for i, test := range tests {
+		code, err := Assemble(test.src)
+		if err != nil {
+			t.Errorf("test %d: assembly failed: %v", i, err)
+			continue
+		}
+		if want := common.Hex2Bytes(strings.Replace(test.code, " ", "", -1)); !bytes.Equal(code, want) {
+			t.Errorf("test %d: code mismatch: have %x, want %x", i, code, want)
+		}
+	}
+}
+
+func TestAssembleLabelSizing(t *testing.T) {
+	// A label beyond 255 bytes needs a two byte push, which moves the label
+	src := "JUMP @end\nDATA 0x" + strings.Repeat("00", 253) + "\nend: JUMPDEST"
+	code, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("assembly failed: %v", err)
+	}
+	if code[0] != byte(PUSH2) || code[1] != 0x01 || code[2] != 0x01 || code[0x101] != byte(JUMPDEST) {
+		t.Errorf("label push mismatch: %x", code[:4])
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []string{
+		"FOO",
+		"PUSH1",
+		"PUSH1 0x1234",
+		"ADD 1",
+		"JUMP @nowhere",
+		"a:\na:",
+		"DATA 0x1",
+		"PUSH1 @end\nDATA 0x" + strings.Repeat("00", 300) + "\nend:",
+	}
+	for _, src := range tests {
+		if _, err := Assemble(src); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	src := "start:\nJUMPDEST\nPUSH2 0x0001\nPUSH1 0x00\nJUMPI @start\nJUMP @start\nDATA 0xfe60"
+	code, err := Assemble(src)
+	if err != nil {
+		t.Fatalf("assembly failed: %v", err)
+	}
+	asm := DisassembleSource(code)
+	if !strings.Contains(asm, "L0000:") || !strings.Contains(asm, "PUSH1 @L0000") {
+		t.Errorf("jump targets not resolved:\n%s", asm)
+	}
+
+	// Any code must survive the round trip, including invalid and truncated
+	// instructions
+	rand := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		code := make([]byte, rand.Intn(100))
+		for j := range code {
+			code[j] = byte(rand.Intn(256))
+			if rand.Intn(4) == 0 {
+				code[j] = byte(JUMPDEST)
+			}
+		}
+		asm := DisassembleSource(code)
+		back, err := Assemble(asm)
+		if err != nil {
+			t.Fatalf("reassembly of %x failed: %v\n%s", code, err, asm)
+		}
+		if !bytes.Equal(back, code) {
+			t.Fatalf("round trip mismatch:\nhave %x\nwant %x\n%s", back, code, asm)
+		}
+	}
+}
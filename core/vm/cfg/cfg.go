@@ -0,0 +1,231 @@
+// Package cfg extracts the control flow graph of EVM bytecode.
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Instruction is a single instruction of the code.
+type Instruction struct {
+	PC  uint64
+	Op  vm.OpCode
+	Arg []byte // push data, truncated at the end of the code
+}
+
+func (self Instruction) String() string {
+	if !self.Op.IsValid() {
+		return fmt.Sprintf("%04d: INVALID 0x%02x", self.PC, byte(self.Op))
+	}
+	if self.Op.IsPush() {
+		return fmt.Sprintf("%04d: %v 0x%x", self.PC, self.Op, self.Arg)
+	}
+	return fmt.Sprintf("%04d: %v", self.PC, self.Op)
+}
+
+func (self Instruction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(self.String())
+}
+
+// Block is a basic block: a sequence of instructions only entered at its
+// first and only left after its last instruction.
+type Block struct {
+	Start        uint64        `json:"start"`
+	End          uint64        `json:"end"` // offset following the last instruction
+	Instructions []Instruction `json:"instructions"`
+	Successors   []uint64      `json:"successors"` // start offsets of the blocks control may pass to
+	Dynamic      bool          `json:"dynamic"`    // ends in a jump to a target unknown statically
+	Reachable    bool          `json:"reachable"`
+}
+
+func (self *Block) last() Instruction {
+	return self.Instructions[len(self.Instructions)-1]
+}
+
+// Graph is the control flow graph of a piece of code.
+type Graph struct {
+	Blocks []*Block `json:"blocks"` // ordered by start offset
+
+	blocks map[uint64]*Block
+}
+
+// New splits code into basic blocks and links them by their static control
+// flow.
+func New(code []byte) *Graph {
+	graph := &Graph{Blocks: []*Block{}, blocks: make(map[uint64]*Block)}
+
+	var block *Block
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		ins := Instruction{PC: pc, Op: vm.OpCode(code[pc])}
+		if ins.Op.IsPush() {
+			end := pc + 1 + uint64(ins.Op-vm.PUSH1) + 1
+			if end > uint64(len(code)) {
+				end = uint64(len(code))
+			}
+			ins.Arg = code[pc+1 : end]
+			pc = end - 1
+		}
+		// JUMPDESTs are entry points and start a new block
+		if block == nil || ins.Op == vm.JUMPDEST {
+			block = &Block{Start: ins.PC}
+			graph.Blocks = append(graph.Blocks, block)
+			graph.blocks[block.Start] = block
+		}
+		block.Instructions = append(block.Instructions, ins)
+		block.End = pc + 1
+
+		if terminates(ins.Op) {
+			block = nil
+		}
+	}
+	for _, block := range graph.Blocks {
+		graph.link(block)
+	}
+	graph.markReachable()
+
+	return graph
+}
+
+// terminates reports whether op ends a basic block.
+func terminates(op vm.OpCode) bool {
+	switch op {
+	case vm.JUMP, vm.JUMPI, vm.STOP, vm.RETURN, vm.SUICIDE:
+		return true
+	}
+	return !op.IsValid()
+}
+
+// link sets the successors of block.
+func (self *Graph) link(block *Block) {
+	last := block.last()
+	if last.Op == vm.JUMP || last.Op == vm.JUMPI {
+		if target, ok := self.staticTarget(block); ok {
+			// Jumps to anything but a JUMPDEST fail at runtime
+			if next := self.blocks[target]; next != nil && next.Instructions[0].Op == vm.JUMPDEST {
+				block.Successors = append(block.Successors, target)
+			}
+		} else {
+			block.Dynamic = true
+		}
+	}
+	if !terminates(last.Op) || last.Op == vm.JUMPI {
+		// A JUMPI may target the block it falls through to
+		_, ok := self.blocks[block.End]
+		if n := len(block.Successors); ok && (n == 0 || block.Successors[n-1] != block.End) {
+			block.Successors = append(block.Successors, block.End)
+		}
+	}
+}
+
+// staticTarget returns the destination of the jump ending block if it is
+// pushed right before the jump.
+func (self *Graph) staticTarget(block *Block) (uint64, bool) {
+	if len(block.Instructions) < 2 {
+		return 0, false
+	}
+	push := block.Instructions[len(block.Instructions)-2]
+	if !push.Op.IsPush() {
+		return 0, false
+	}
+	target := new(big.Int).SetBytes(push.Arg)
+	if target.BitLen() > 64 {
+		// Certainly not a valid destination, but known statically
+		return ^uint64(0), true
+	}
+	return target.Uint64(), true
+}
+
+// markReachable flags the blocks reachable from the start of the code. As
+// dynamic jumps may go to any JUMPDEST, all of them are considered reachable
+// once a dynamic jump is.
+func (self *Graph) markReachable() {
+	if len(self.Blocks) == 0 {
+		return
+	}
+	var (
+		queue   = []*Block{self.Blocks[0]}
+		dynamic bool
+	)
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		if block.Reachable {
+			continue
+		}
+		block.Reachable = true
+		for _, succ := range block.Successors {
+			queue = append(queue, self.blocks[succ])
+		}
+		if block.Dynamic && !dynamic {
+			dynamic = true
+			for _, dest := range self.Blocks {
+				if dest.Instructions[0].Op == vm.JUMPDEST {
+					queue = append(queue, dest)
+				}
+			}
+		}
+	}
+}
+
+// Block returns the block starting at pc, or nil if there is none.
+func (self *Graph) Block(pc uint64) *Block {
+	return self.blocks[pc]
+}
+
+// Unreachable returns the blocks control can never pass to.
+func (self *Graph) Unreachable() []*Block {
+	var blocks []*Block
+	for _, block := range self.Blocks {
+		if !block.Reachable {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// DOT returns the graph in the Graphviz DOT language. Unreachable blocks are
+// drawn dashed, dynamic jumps lead to a separate node.
+func (self *Graph) DOT() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph cfg {\n\tnode [shape=box fontname=monospace];\n")
+
+	var dynamic bool
+	for _, block := range self.Blocks {
+		var label bytes.Buffer
+		for _, ins := range block.Instructions {
+			label.WriteString(ins.String() + `\l`)
+		}
+		style := ""
+		if !block.Reachable {
+			style = " style=dashed"
+		}
+		fmt.Fprintf(&buf, "\tb%d [label=\"%s\"%s];\n", block.Start, label.String(), style)
+
+		succs := append([]uint64{}, block.Successors...)
+		sort.Sort(offsets(succs))
+		for _, succ := range succs {
+			fmt.Fprintf(&buf, "\tb%d -> b%d;\n", block.Start, succ)
+		}
+		if block.Dynamic {
+			dynamic = true
+			fmt.Fprintf(&buf, "\tb%d -> dynamic [style=dotted];\n", block.Start)
+		}
+	}
+	if dynamic {
+		buf.WriteString("\tdynamic [shape=diamond label=\"?\"];\n")
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+type offsets []uint64
+
+func (s offsets) Len() int           { return len(s) }
+func (s offsets) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s offsets) Less(i, j int) bool { return s[i] < s[j] }
@@ -0,0 +1,132 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+func assemble(t *testing.T, src string) []byte {
+	code, err := vm.Assemble(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func starts(blocks []*Block) []uint64 {
+	var pcs []uint64
+	for _, block := range blocks {
+		pcs = append(pcs, block.Start)
+	}
+	return pcs
+}
+
+func TestBlocks(t *testing.T) {
+	code := assemble(t, `
+		PUSH1 0x01
+		JUMPI @yes
+		PUSH1 0x00
+		STOP
+	yes:
+		JUMPDEST
+		PUSH1 0x02
+	loop:
+		JUMPDEST
+		JUMP @loop
+		PUSH1 0x00
+	`)
+	graph := New(code)
+
+	if exp := []uint64{0, 5, 8, 11, 15}; !reflect.DeepEqual(starts(graph.Blocks), exp) {
+		t.Fatalf("blocks: got %v, want %v", starts(graph.Blocks), exp)
+	}
+	succs := map[uint64][]uint64{
+		0:  {8, 5},
+		5:  nil,
+		8:  {11},
+		11: {11},
+		15: nil,
+	}
+	for pc, exp := range succs {
+		if got := graph.Block(pc).Successors; !reflect.DeepEqual(got, exp) {
+			t.Errorf("successors of %d: got %v, want %v", pc, got, exp)
+		}
+	}
+	if unreachable := starts(graph.Unreachable()); !reflect.DeepEqual(unreachable, []uint64{15}) {
+		t.Errorf("unreachable: got %v, want [15]", unreachable)
+	}
+}
+
+func TestJumpToFallThrough(t *testing.T) {
+	// the jump target and the fall-through are the same block
+	code := assemble(t, `
+		PUSH1 0x01
+		JUMPI @next
+	next:
+		JUMPDEST
+		STOP
+	`)
+	graph := New(code)
+
+	if got := graph.Block(0).Successors; !reflect.DeepEqual(got, []uint64{5}) {
+		t.Errorf("successors of 0: got %v, want [5]", got)
+	}
+	if dot := graph.DOT(); strings.Count(dot, "b0 -> b5") != 1 {
+		t.Errorf("expected a single edge b0 -> b5:\n%s", dot)
+	}
+}
+
+func TestDynamicJump(t *testing.T) {
+	code := assemble(t, `
+		CALLDATASIZE
+		JUMP
+		PUSH1 0x00
+	dest:
+		JUMPDEST
+		STOP
+	`)
+	graph := New(code)
+
+	if !graph.Block(0).Dynamic {
+		t.Error("jump not dynamic")
+	}
+	// the PUSH after the jump can never run, the JUMPDEST may
+	if unreachable := starts(graph.Unreachable()); !reflect.DeepEqual(unreachable, []uint64{2}) {
+		t.Errorf("unreachable: got %v, want [2]", unreachable)
+	}
+	if dot := graph.DOT(); !strings.Contains(dot, "b0 -> dynamic") || !strings.Contains(dot, "b2 [label=\"0002: PUSH1 0x00\\l\" style=dashed]") {
+		t.Errorf("unexpected DOT output:\n%s", dot)
+	}
+}
+
+func TestInvalidTargets(t *testing.T) {
+	// jumps into push data and past the code have no successors
+	code := assemble(t, `
+		PUSH1 0x05
+		JUMP
+		PUSH1 0x5b
+		PUSH2 0xffff
+		JUMP
+	`)
+	graph := New(code)
+
+	for _, block := range graph.Blocks {
+		if len(block.Successors) != 0 || block.Dynamic {
+			t.Errorf("block %d: successors %v, dynamic %v", block.Start, block.Successors, block.Dynamic)
+		}
+	}
+}
+
+func TestTruncatedPush(t *testing.T) {
+	graph := New([]byte{byte(vm.PUSH2), 0x01})
+
+	if len(graph.Blocks) != 1 || len(graph.Blocks[0].Instructions) != 1 {
+		t.Fatalf("unexpected blocks %v", graph.Blocks)
+	}
+	if ins := graph.Blocks[0].Instructions[0]; ins.String() != "0000: PUSH2 0x01" {
+		t.Errorf("got %q", ins.String())
+	}
+}
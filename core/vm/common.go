@@ -46,12 +46,25 @@ func NewVm(env Environment) VirtualMachine {
 	}
 }
 
-func calcMemSize(off, l *big.Int) *big.Int {
-	if l.Cmp(common.Big0) == 0 {
-		return common.Big0
+// maxMemSize is the largest memory size for which the memory gas cost fits
+// in 64 bits. Larger sizes can never be paid for.
+const maxMemSize = 0x1FFFFFFFE0
+
+// calcMemSize returns the memory size required to access l bytes at offset
+// off. ok is false if the size exceeds maxMemSize.
+func calcMemSize(off, l *word) (size uint64, ok bool) {
+	if l.IsZero() {
+		return 0, true
+	}
+	if !off.IsUint64() || !l.IsUint64() {
+		return 0, false
+	}
+	size = off[0] + l[0]
+	if size < off[0] || size > maxMemSize {
+		return 0, false
 	}
 
-	return new(big.Int).Add(off, l)
+	return size, true
 }
 
 // Simple helper
@@ -70,12 +83,28 @@ func toValue(val *big.Int) interface{} {
 	return val
 }
 
-func getData(data []byte, start, size *big.Int) []byte {
-	dlen := big.NewInt(int64(len(data)))
+// getData returns size bytes of data starting at start, padded with zeros
+// beyond the end of data.
+func getData(data []byte, start, size uint64) []byte {
+	dlen := uint64(len(data))
 
-	s := common.BigMin(start, dlen)
-	e := common.BigMin(new(big.Int).Add(s, size), dlen)
-	return common.RightPadBytes(data[s.Uint64():e.Uint64()], int(size.Uint64()))
+	s := start
+	if s > dlen {
+		s = dlen
+	}
+	e := s + size
+	if e > dlen || e < s {
+		e = dlen
+	}
+	return common.RightPadBytes(data[s:e], int(size))
+}
+
+// clampUint64 returns x, or the largest uint64 if x doesn't fit.
+func clampUint64(x *word) uint64 {
+	if !x.IsUint64() {
+		return math.MaxUint64
+	}
+	return x[0]
 }
 
 func UseGas(gas, amount *big.Int) bool {
@@ -17,11 +17,14 @@ type Context struct {
 	self   ContextRef
 
 	Code     []byte
+	CodeHash common.Hash // zero if unknown, disables the analysis cache
 	CodeAddr *common.Address
 
 	value, Gas, UsedGas, Price *big.Int
 
 	Args []byte
+
+	jumpdests destinations
 }
 
 // Create a new context for the given data items
@@ -40,13 +43,13 @@ func NewContext(caller ContextRef, object ContextRef, value, gas, price *big.Int
 	return c
 }
 
-func (c *Context) GetOp(n *big.Int) OpCode {
+func (c *Context) GetOp(n uint64) OpCode {
 	return OpCode(c.GetByte(n))
 }
 
-func (c *Context) GetByte(n *big.Int) byte {
-	if n.Cmp(big.NewInt(int64(len(c.Code)))) < 0 {
-		return c.Code[n.Int64()]
+func (c *Context) GetByte(n uint64) byte {
+	if n < uint64(len(c.Code)) {
+		return c.Code[n]
 	}
 
 	return 0
@@ -86,9 +89,11 @@ func (c *Context) Address() common.Address {
 
 func (self *Context) SetCode(code []byte) {
 	self.Code = code
+	self.CodeHash = common.Hash{}
 }
 
-func (self *Context) SetCallCode(addr *common.Address, code []byte) {
+func (self *Context) SetCallCode(addr *common.Address, hash common.Hash, code []byte) {
 	self.Code = code
+	self.CodeHash = hash
 	self.CodeAddr = addr
 }
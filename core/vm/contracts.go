@@ -0,0 +1,159 @@
+package vm
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PrecompiledContract is a contract implemented natively instead of in EVM
+// bytecode.
+type PrecompiledContract interface {
+	RequiredGas(input []byte) *big.Int // gas needed to run the contract on input
+	Run(input []byte) []byte
+}
+
+// PrecompiledRegistry holds the precompiled contracts of a chain. Contracts
+// become active at a fork block and stay active until they are replaced or
+// removed at a later one. A registry must not be modified while in use.
+type PrecompiledRegistry struct {
+	contracts map[common.Address][]precompiledFork
+}
+
+type precompiledFork struct {
+	block    *big.Int
+	contract PrecompiledContract // nil if removed at block
+}
+
+func NewPrecompiledRegistry() *PrecompiledRegistry {
+	return &PrecompiledRegistry{contracts: make(map[common.Address][]precompiledFork)}
+}
+
+// Register activates contract at addr from block on. A nil contract removes
+// the one previously active.
+func (self *PrecompiledRegistry) Register(block *big.Int, addr common.Address, contract PrecompiledContract) {
+	forks := self.contracts[addr]
+	i := sort.Search(len(forks), func(i int) bool { return forks[i].block.Cmp(block) >= 0 })
+	if i < len(forks) && forks[i].block.Cmp(block) == 0 {
+		forks[i].contract = contract
+		return
+	}
+	forks = append(forks, precompiledFork{})
+	copy(forks[i+1:], forks[i:])
+	forks[i] = precompiledFork{new(big.Int).Set(block), contract}
+
+	self.contracts[addr] = forks
+}
+
+// Get returns the contract active at addr in block number, or nil if there
+// is none.
+func (self *PrecompiledRegistry) Get(addr common.Address, number *big.Int) PrecompiledContract {
+	forks := self.contracts[addr]
+	i := sort.Search(len(forks), func(i int) bool { return forks[i].block.Cmp(number) > 0 })
+	if i == 0 {
+		return nil
+	}
+	return forks[i-1].contract
+}
+
+// frontierPrecompiled is used by environments which don't configure a registry.
+var frontierPrecompiled = PrecompiledContracts()
+
+// precompiled returns the registry of env, or the frontier contracts if env
+// doesn't configure one.
+func precompiled(env Environment) *PrecompiledRegistry {
+	if registry := env.Precompiled(); registry != nil {
+		return registry
+	}
+	return frontierPrecompiled
+}
+
+// PrecompiledContracts returns a registry holding the frontier contracts,
+// active from the genesis block.
+func PrecompiledContracts() *PrecompiledRegistry {
+	registry := NewPrecompiledRegistry()
+	registry.Register(common.Big0, common.BytesToAddress([]byte{1}), ecrecover{})
+	registry.Register(common.Big0, common.BytesToAddress([]byte{2}), sha256{})
+	registry.Register(common.Big0, common.BytesToAddress([]byte{3}), ripemd160{})
+	registry.Register(common.Big0, common.BytesToAddress([]byte{4}), identity{})
+
+	return registry
+}
+
+// wordGas returns the gas of a contract charging base plus perWord for every
+// 32 byte word of input.
+func wordGas(input []byte, base, perWord *big.Int) *big.Int {
+	n := big.NewInt(int64(len(input)+31) / 32)
+	n.Mul(n, perWord)
+	return n.Add(n, base)
+}
+
+type ecrecover struct{}
+
+func (ecrecover) RequiredGas(input []byte) *big.Int {
+	return params.EcrecoverGas
+}
+
+const ecRecoverInputLength = 128
+
+func (ecrecover) Run(in []byte) []byte {
+	// "in" is (hash, v, r, s), each 32 bytes
+	// but for ecrecover we want (r, s, v)
+	if len(in) < ecRecoverInputLength {
+		return nil
+	}
+
+	// Treat V as a 256bit integer
+	v := new(big.Int).Sub(common.Bytes2Big(in[32:64]), big.NewInt(27))
+	// Ethereum requires V to be either 0 or 1 => (27 || 28)
+	if !(v.Cmp(Zero) == 0 || v.Cmp(One) == 0) {
+		return nil
+	}
+
+	// v needs to be moved to the end
+	rsv := append(in[64:128], byte(v.Uint64()))
+	pubKey, err := crypto.Ecrecover(in[:32], rsv)
+	// make sure the public key is a valid one
+	if err != nil {
+		glog.V(logger.Error).Infof("EC RECOVER FAIL: %v", err)
+		return nil
+	}
+
+	// the first byte of pubkey is bitcoin heritage
+	return common.LeftPadBytes(crypto.Sha3(pubKey[1:])[12:], 32)
+}
+
+type sha256 struct{}
+
+func (sha256) RequiredGas(input []byte) *big.Int {
+	return wordGas(input, params.Sha256Gas, params.Sha256WordGas)
+}
+
+func (sha256) Run(in []byte) []byte {
+	return crypto.Sha256(in)
+}
+
+type ripemd160 struct{}
+
+func (ripemd160) RequiredGas(input []byte) *big.Int {
+	return wordGas(input, params.Ripemd160Gas, params.Ripemd160WordGas)
+}
+
+func (ripemd160) Run(in []byte) []byte {
+	return common.LeftPadBytes(crypto.Ripemd160(in), 32)
+}
+
+type identity struct{}
+
+func (identity) RequiredGas(input []byte) *big.Int {
+	return wordGas(input, params.IdentityGas, params.IdentityWordGas)
+}
+
+func (identity) Run(in []byte) []byte {
+	return in
+}
@@ -0,0 +1,60 @@
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type testContract struct{ out byte }
+
+func (testContract) RequiredGas(input []byte) *big.Int { return big.NewInt(1) }
+func (c testContract) Run(input []byte) []byte         { return []byte{c.out} }
+
+func TestPrecompiledRegistry(t *testing.T) {
+	addr := common.BytesToAddress([]byte{0x10})
+	registry := NewPrecompiledRegistry()
+	registry.Register(big.NewInt(20), addr, nil)
+	registry.Register(big.NewInt(10), addr, testContract{1})
+	registry.Register(big.NewInt(15), addr, testContract{2})
+
+	tests := []struct {
+		number int64
+		want   byte // 0 if no contract is active
+	}{{0, 0}, {9, 0}, {10, 1}, {14, 1}, {15, 2}, {19, 2}, {20, 0}, {100, 0}}
+	for _, test := range tests {
+		contract := registry.Get(addr, big.NewInt(test.number))
+		switch {
+		case contract == nil && test.want != 0:
+			t.Errorf("block %d: no contract, want %d", test.number, test.want)
+		case contract != nil && !bytes.Equal(contract.Run(nil), []byte{test.want}):
+			t.Errorf("block %d: have contract %x, want %d", test.number, contract.Run(nil), test.want)
+		}
+	}
+	if registry.Get(common.BytesToAddress([]byte{0x11}), big.NewInt(15)) != nil {
+		t.Errorf("unregistered address has a contract")
+	}
+}
+
+func TestFrontierPrecompiled(t *testing.T) {
+	registry := PrecompiledContracts()
+	for i := byte(1); i <= 4; i++ {
+		if registry.Get(common.BytesToAddress([]byte{i}), common.Big0) == nil {
+			t.Errorf("contract %d missing", i)
+		}
+	}
+	if registry.Get(common.BytesToAddress([]byte{5}), common.Big0) != nil {
+		t.Errorf("unexpected contract 5")
+	}
+
+	input := []byte("hello")
+	identity := registry.Get(common.BytesToAddress([]byte{4}), big.NewInt(1))
+	if out := identity.Run(input); !bytes.Equal(out, input) {
+		t.Errorf("identity output mismatch: have %x, want %x", out, input)
+	}
+	if gas := identity.RequiredGas(input); gas.Cmp(big.NewInt(18)) != 0 {
+		t.Errorf("identity gas mismatch: have %v, want 18", gas)
+	}
+}
@@ -25,6 +25,8 @@ type Environment interface {
 	AddLog(*state.Log)
 
 	VmType() Type
+	Profiler() *Profiler               // nil if execution isn't profiled
+	Precompiled() *PrecompiledRegistry // nil for the frontier contracts
 
 	Depth() int
 	SetDepth(i int)
@@ -1,11 +1,16 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/params"
 	"math/big"
 )
 
+// errGasUintOverflow is returned if the gas or memory required by an
+// operation doesn't fit in 64 bits, which can never be paid for.
+var errGasUintOverflow = errors.New("gas uint64 overflow")
+
 type OutOfGasError struct {
 	req, has *big.Int
 }
@@ -1,7 +1,6 @@
 package vm
 
 import (
-	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/params"
@@ -21,106 +20,114 @@ var (
 	GasContractByte = big.NewInt(200)
 )
 
-func baseCheck(op OpCode, stack *stack, gas *big.Int) error {
-	// PUSH and DUP are a bit special. They all cost the same but we do want to have checking on stack push limit
-	// PUSH is also allowed to calculate the same price for all PUSHes
-	// DUP requirements are handled elsewhere (except for the stack limit check)
-	if op >= PUSH1 && op <= PUSH32 {
-		op = PUSH1
+// toWordSize returns the number of 32 byte words needed to hold size bytes.
+func toWordSize(size uint64) uint64 {
+	return (size + 31) / 32
+}
+
+// memoryGasCost returns the total gas cost of a memory of the given number
+// of words.
+func memoryGasCost(words uint64) uint64 {
+	return words*params.MemoryGas.Uint64() + words*words/params.QuadCoeffDiv.Uint64()
+}
+
+// safeAdd returns x + y and whether the sum did not overflow.
+func safeAdd(x, y uint64) (uint64, bool) {
+	return x + y, x+y >= x
+}
+
+// safeMul returns x * y and whether the product did not overflow.
+func safeMul(x, y uint64) (uint64, bool) {
+	if x == 0 || y == 0 {
+		return 0, true
 	}
-	if op >= DUP1 && op <= DUP16 {
-		op = DUP1
+	return x * y, x*y/y == x
+}
+
+// gasFunc returns the dynamic gas cost of an operation, excluding memory
+// expansion. ok is false if the cost does not fit in a uint64.
+type gasFunc func(self *Vm, context *Context, stack *stack) (gas uint64, ok bool)
+
+func gasExp(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return uint64((stack.back(1).BitLen()+7)/8) * params.ExpByteGas.Uint64(), true
+}
+
+func gasSha3(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return safeMul(toWordSize(stack.back(1).Uint64()), params.Sha3WordGas.Uint64())
+}
+
+func gasCalldataCopy(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return safeMul(toWordSize(stack.back(2).Uint64()), params.CopyGas.Uint64())
+}
+
+func gasCodeCopy(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return safeMul(toWordSize(stack.back(2).Uint64()), params.CopyGas.Uint64())
+}
+
+func gasExtCodeCopy(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return safeMul(toWordSize(stack.back(3).Uint64()), params.CopyGas.Uint64())
+}
+
+func gasSStore(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	var (
+		statedb = self.env.State()
+		x, y    = stack.back(0), stack.back(1)
+		val     = statedb.GetState(context.Address(), x.Hash())
+	)
+	if len(val) == 0 && !y.IsZero() {
+		// 0 => non 0
+		return params.SstoreSetGas.Uint64(), true
+	} else if len(val) > 0 && y.IsZero() {
+		statedb.Refund(self.env.Origin(), params.SstoreRefundGas)
+
+		return params.SstoreClearGas.Uint64(), true
 	}
+	// non 0 => non 0 (or 0 => 0)
+	return params.SstoreClearGas.Uint64(), true
+}
 
-	if r, ok := _baseCheck[op]; ok {
-		err := stack.require(r.stackPop)
-		if err != nil {
-			return err
-		}
+// makeGasLog returns the gas function of a LOG with n topics.
+func makeGasLog(n uint64) gasFunc {
+	return func(self *Vm, context *Context, stack *stack) (uint64, bool) {
+		gas := params.LogGas.Uint64() + n*params.LogTopicGas.Uint64()
 
-		if r.stackPush > 0 && len(stack.data)-r.stackPop+r.stackPush > int(params.StackLimit.Int64())+1 {
-			return fmt.Errorf("stack limit reached %d (%d)", len(stack.data), params.StackLimit.Int64())
+		dataGas, ok := safeMul(stack.back(1).Uint64(), params.LogDataGas.Uint64())
+		if !ok {
+			return 0, false
 		}
+		return safeAdd(gas, dataGas)
+	}
+}
 
-		gas.Add(gas, r.gas)
+func gasCall(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	gas, ok := callGas(self, stack)
+	if ok && self.env.State().GetStateObject(stack.back(1).Address()) == nil {
+		gas, ok = safeAdd(gas, params.CallNewAccountGas.Uint64())
 	}
-	return nil
+	return gas, ok
 }
 
-func toWordSize(size *big.Int) *big.Int {
-	tmp := new(big.Int)
-	tmp.Add(size, u256(31))
-	tmp.Div(tmp, u256(32))
-	return tmp
+func gasCallCode(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	return callGas(self, stack)
 }
 
-type req struct {
-	stackPop  int
-	gas       *big.Int
-	stackPush int
+// callGas returns the gas shared by CALL and CALLCODE: the gas handed to the
+// callee and the value transfer fee.
+func callGas(self *Vm, stack *stack) (uint64, bool) {
+	if !stack.back(0).IsUint64() {
+		return 0, false
+	}
+	gas := stack.back(0).Uint64()
+	if !stack.back(2).IsZero() {
+		return safeAdd(gas, params.CallValueTransferGas.Uint64())
+	}
+	return gas, true
 }
 
-var _baseCheck = map[OpCode]req{
-	// opcode  |  stack pop | gas price | stack push
-	ADD:          {2, GasFastestStep, 1},
-	LT:           {2, GasFastestStep, 1},
-	GT:           {2, GasFastestStep, 1},
-	SLT:          {2, GasFastestStep, 1},
-	SGT:          {2, GasFastestStep, 1},
-	EQ:           {2, GasFastestStep, 1},
-	ISZERO:       {1, GasFastestStep, 1},
-	SUB:          {2, GasFastestStep, 1},
-	AND:          {2, GasFastestStep, 1},
-	OR:           {2, GasFastestStep, 1},
-	XOR:          {2, GasFastestStep, 1},
-	NOT:          {1, GasFastestStep, 1},
-	BYTE:         {2, GasFastestStep, 1},
-	CALLDATALOAD: {1, GasFastestStep, 1},
-	CALLDATACOPY: {3, GasFastestStep, 1},
-	MLOAD:        {1, GasFastestStep, 1},
-	MSTORE:       {2, GasFastestStep, 0},
-	MSTORE8:      {2, GasFastestStep, 0},
-	CODECOPY:     {3, GasFastestStep, 0},
-	MUL:          {2, GasFastStep, 1},
-	DIV:          {2, GasFastStep, 1},
-	SDIV:         {2, GasFastStep, 1},
-	MOD:          {2, GasFastStep, 1},
-	SMOD:         {2, GasFastStep, 1},
-	SIGNEXTEND:   {2, GasFastStep, 1},
-	ADDMOD:       {3, GasMidStep, 1},
-	MULMOD:       {3, GasMidStep, 1},
-	JUMP:         {1, GasMidStep, 0},
-	JUMPI:        {2, GasSlowStep, 0},
-	EXP:          {2, GasSlowStep, 1},
-	ADDRESS:      {0, GasQuickStep, 1},
-	ORIGIN:       {0, GasQuickStep, 1},
-	CALLER:       {0, GasQuickStep, 1},
-	CALLVALUE:    {0, GasQuickStep, 1},
-	CODESIZE:     {0, GasQuickStep, 1},
-	GASPRICE:     {0, GasQuickStep, 1},
-	COINBASE:     {0, GasQuickStep, 1},
-	TIMESTAMP:    {0, GasQuickStep, 1},
-	NUMBER:       {0, GasQuickStep, 1},
-	CALLDATASIZE: {0, GasQuickStep, 1},
-	DIFFICULTY:   {0, GasQuickStep, 1},
-	GASLIMIT:     {0, GasQuickStep, 1},
-	POP:          {1, GasQuickStep, 0},
-	PC:           {0, GasQuickStep, 1},
-	MSIZE:        {0, GasQuickStep, 1},
-	GAS:          {0, GasQuickStep, 1},
-	BLOCKHASH:    {1, GasExtStep, 1},
-	BALANCE:      {1, GasExtStep, 1},
-	EXTCODESIZE:  {1, GasExtStep, 1},
-	EXTCODECOPY:  {4, GasExtStep, 0},
-	SLOAD:        {1, params.SloadGas, 1},
-	SSTORE:       {2, Zero, 0},
-	SHA3:         {2, params.Sha3Gas, 1},
-	CREATE:       {3, params.CreateGas, 1},
-	CALL:         {7, params.CallGas, 1},
-	CALLCODE:     {7, params.CallGas, 1},
-	JUMPDEST:     {0, params.JumpdestGas, 0},
-	SUICIDE:      {1, Zero, 0},
-	RETURN:       {2, Zero, 0},
-	PUSH1:        {0, GasFastestStep, 1},
-	DUP1:         {0, Zero, 1},
+func gasSuicide(self *Vm, context *Context, stack *stack) (uint64, bool) {
+	statedb := self.env.State()
+	if !statedb.IsDeleted(context.Address()) {
+		statedb.Refund(self.env.Origin(), params.SuicideRefundGas)
+	}
+	return 0, true
 }
@@ -0,0 +1,670 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// 0x0 range - arithmetic ops
+
+func opAdd(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v + %v", y, x)
+
+	y.Add(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opSub(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v - %v", y, x)
+
+	y.Sub(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opMul(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v * %v", y, x)
+
+	y.Mul(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opDiv(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v / %v", x, y)
+
+	y.Div(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opSdiv(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v / %v", x, y)
+
+	y.SDiv(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opMod(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v %% %v", x, y)
+
+	y.Mod(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opSmod(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v %% %v", x, y)
+
+	y.SMod(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opAddmod(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y, z := stack.pop(), stack.pop(), stack.peek()
+
+	z.AddMod(x, y, z)
+
+	self.Printf(" %v + %v %% %v", x, y, z)
+	return nil, nil
+}
+
+func opMulmod(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y, z := stack.pop(), stack.pop(), stack.peek()
+
+	z.MulMod(x, y, z)
+
+	self.Printf(" %v * %v %% %v", x, y, z)
+	return nil, nil
+}
+
+func opExp(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v ** %v", x, y)
+
+	y.Exp(x, y)
+
+	self.Printf(" = %v", y)
+	return nil, nil
+}
+
+func opSignExtend(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	back, num := stack.pop(), stack.peek()
+
+	num.SignExtend(back, num)
+
+	self.Printf(" = %v", num)
+	return nil, nil
+}
+
+// 0x10 range - comparison and bit ops
+
+func opLt(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v < %v", x, y)
+
+	setBool(y, x.Cmp(y) < 0)
+	return nil, nil
+}
+
+func opGt(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v > %v", x, y)
+
+	setBool(y, x.Cmp(y) > 0)
+	return nil, nil
+}
+
+func opSlt(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v < %v", x, y)
+
+	setBool(y, x.SCmp(y) < 0)
+	return nil, nil
+}
+
+func opSgt(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v > %v", x, y)
+
+	setBool(y, x.SCmp(y) > 0)
+	return nil, nil
+}
+
+func opEq(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v == %v", y, x)
+
+	setBool(y, x.Cmp(y) == 0)
+	return nil, nil
+}
+
+func opIszero(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+
+	setBool(x, x.IsZero())
+	return nil, nil
+}
+
+func opAnd(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v & %v", y, x)
+
+	y.And(x, y)
+	return nil, nil
+}
+
+func opOr(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v | %v", x, y)
+
+	y.Or(x, y)
+	return nil, nil
+}
+
+func opXor(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x, y := stack.pop(), stack.peek()
+	self.Printf(" %v ^ %v", x, y)
+
+	y.Xor(x, y)
+	return nil, nil
+}
+
+func opNot(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+	x.Not(x)
+	return nil, nil
+}
+
+func opByte(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	th, val := stack.pop(), stack.peek()
+
+	val.Byte(th, val)
+
+	self.Printf(" => 0x%x", val.Bytes())
+	return nil, nil
+}
+
+// 0x20 range - crypto
+
+func opSha3(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	offset, size := stack.pop(), stack.peek()
+	data := crypto.Sha3(mem.Get(offset.Uint64(), size.Uint64()))
+
+	size.SetBytes(data)
+
+	self.Printf(" => %x", data)
+	return nil, nil
+}
+
+// 0x30 range - closure state
+
+func opAddress(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	addr := context.Address()
+	stack.push(new(word).SetBytes(addr[:]))
+
+	self.Printf(" => %x", addr)
+	return nil, nil
+}
+
+func opBalance(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+	addr := x.Address()
+	balance := self.env.State().GetBalance(addr)
+
+	x.SetBig(balance)
+
+	self.Printf(" => %v (%x)", balance, addr)
+	return nil, nil
+}
+
+func opOrigin(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	origin := self.env.Origin()
+	stack.push(new(word).SetBytes(origin[:]))
+
+	self.Printf(" => %x", origin)
+	return nil, nil
+}
+
+func opCaller(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	caller := context.caller.Address()
+	stack.push(new(word).SetBytes(caller[:]))
+
+	self.Printf(" => %x", caller)
+	return nil, nil
+}
+
+func opCallValue(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.push(new(word).SetBig(context.value))
+
+	self.Printf(" => %v", context.value)
+	return nil, nil
+}
+
+func opCalldataLoad(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+	data := getData(context.Args, clampUint64(x), 32)
+
+	self.Printf(" => 0x%x", data)
+
+	x.SetBytes(data)
+	return nil, nil
+}
+
+func opCalldataSize(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	l := uint64(len(context.Args))
+	stack.push(new(word).SetUint64(l))
+
+	self.Printf(" => %d", l)
+	return nil, nil
+}
+
+func opCalldataCopy(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	var (
+		mOff = stack.pop()
+		cOff = stack.pop()
+		l    = stack.pop()
+	)
+	data := getData(context.Args, clampUint64(cOff), l.Uint64())
+
+	mem.Set(mOff.Uint64(), l.Uint64(), data)
+
+	self.Printf(" => [%v, %v, %v]", mOff, cOff, l)
+	return nil, nil
+}
+
+func opCodeSize(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	l := uint64(len(context.Code))
+	stack.push(new(word).SetUint64(l))
+
+	self.Printf(" => %d", l)
+	return nil, nil
+}
+
+func opCodeCopy(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, codeCopy(self, context.Code, mem, stack)
+}
+
+func opGasprice(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.push(new(word).SetBig(context.Price))
+
+	self.Printf(" => %x", context.Price)
+	return nil, nil
+}
+
+func opExtCodeSize(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+	l := uint64(len(self.env.State().GetCode(x.Address())))
+	x.SetUint64(l)
+
+	self.Printf(" => %d", l)
+	return nil, nil
+}
+
+func opExtCodeCopy(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	addr := stack.pop().Address()
+	return nil, codeCopy(self, self.env.State().GetCode(addr), mem, stack)
+}
+
+func codeCopy(self *Vm, code []byte, mem *Memory, stack *stack) error {
+	var (
+		mOff = stack.pop()
+		cOff = stack.pop()
+		l    = stack.pop()
+	)
+	codeCopy := getData(code, clampUint64(cOff), l.Uint64())
+
+	mem.Set(mOff.Uint64(), l.Uint64(), codeCopy)
+
+	self.Printf(" => [%v, %v, %v] %x", mOff, cOff, l, codeCopy)
+	return nil
+}
+
+// 0x40 range - block operations
+
+func opBlockhash(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	num := stack.peek()
+
+	n := new(big.Int).Sub(self.env.BlockNumber(), common.Big257)
+	if num.IsUint64() && num.Big().Cmp(n) > 0 && num.Big().Cmp(self.env.BlockNumber()) < 0 {
+		hash := self.env.GetHash(num.Uint64())
+		num.SetBytes(hash[:])
+	} else {
+		num.Clear()
+	}
+
+	self.Printf(" => 0x%x", num.Bytes())
+	return nil, nil
+}
+
+func opCoinbase(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	coinbase := self.env.Coinbase()
+	stack.push(new(word).SetBytes(coinbase[:]))
+
+	self.Printf(" => 0x%x", coinbase)
+	return nil, nil
+}
+
+func opTimestamp(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	time := self.env.Time()
+	stack.push(new(word).SetBig(big.NewInt(time)))
+
+	self.Printf(" => 0x%x", time)
+	return nil, nil
+}
+
+func opNumber(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	number := self.env.BlockNumber()
+	stack.push(new(word).SetBig(number))
+
+	self.Printf(" => 0x%x", number.Bytes())
+	return nil, nil
+}
+
+func opDifficulty(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	difficulty := self.env.Difficulty()
+	stack.push(new(word).SetBig(difficulty))
+
+	self.Printf(" => 0x%x", difficulty.Bytes())
+	return nil, nil
+}
+
+func opGasLimit(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	self.Printf(" => %v", self.env.GasLimit())
+
+	stack.push(new(word).SetBig(self.env.GasLimit()))
+	return nil, nil
+}
+
+// 0x50 range - 'storage' and execution
+
+func opPop(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.pop()
+	return nil, nil
+}
+
+func opMload(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	offset := stack.peek()
+	offset.SetBytes(mem.Get(offset.Uint64(), 32))
+
+	self.Printf(" => 0x%x", offset.Bytes())
+	return nil, nil
+}
+
+func opMstore(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	// pop value of the stack
+	mStart, val := stack.pop(), stack.pop()
+	b := val.Bytes32()
+	mem.Set(mStart.Uint64(), 32, b[:])
+
+	self.Printf(" => 0x%x", val)
+	return nil, nil
+}
+
+func opMstore8(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	off, val := stack.pop().Uint64(), stack.pop().Uint64()
+
+	mem.store[off] = byte(val & 0xff)
+
+	self.Printf(" => [%v] 0x%x", off, mem.store[off])
+	return nil, nil
+}
+
+func opSload(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	x := stack.peek()
+	loc := x.Hash()
+	x.SetBytes(self.env.State().GetState(context.Address(), loc))
+
+	self.Printf(" {0x%x : 0x%x}", loc, x.Bytes())
+	return nil, nil
+}
+
+func opSstore(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	loc := stack.pop().Hash()
+	val := stack.pop()
+
+	self.env.State().SetState(context.Address(), loc, val.Bytes())
+
+	self.Printf(" {0x%x : 0x%x}", loc, val.Bytes())
+	return nil, nil
+}
+
+func opJump(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, self.jump(pc, context, stack.pop())
+}
+
+func opJumpi(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	pos, cond := stack.pop(), stack.pop()
+
+	if !cond.IsZero() {
+		return nil, self.jump(pc, context, pos)
+	}
+
+	self.Printf(" ~> false")
+	*pc++
+	return nil, nil
+}
+
+func opJumpdest(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, nil
+}
+
+func opPc(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.push(new(word).SetUint64(*pc))
+	return nil, nil
+}
+
+func opMsize(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.push(new(word).SetUint64(uint64(mem.Len())))
+	return nil, nil
+}
+
+func opGas(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	stack.push(new(word).SetBig(context.Gas))
+
+	self.Printf(" => %x", context.Gas)
+	return nil, nil
+}
+
+// 0x60 range - pushes, dups and swaps
+
+// makePush returns the execution function of a PUSH of size bytes.
+func makePush(size uint64) executionFunc {
+	return func(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+		byts := getData(context.Code, *pc+1, size)
+		// push value to stack
+		stack.push(new(word).SetBytes(byts))
+		*pc += size
+
+		self.Printf(" => 0x%x", byts)
+		return nil, nil
+	}
+}
+
+// makeDup returns the execution function of a DUP of the n'th stack item.
+func makeDup(n int) executionFunc {
+	return func(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+		stack.dup(n)
+
+		self.Printf(" => [%d] 0x%x", n, stack.peek().Bytes())
+		return nil, nil
+	}
+}
+
+// makeSwap returns the execution function of a SWAP of the top and the n'th
+// stack item.
+func makeSwap(n int) executionFunc {
+	return func(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+		stack.swap(n)
+
+		self.Printf(" => [%d]", n)
+		return nil, nil
+	}
+}
+
+// 0xa0 range - logging
+
+// makeLog returns the execution function of a LOG with n topics.
+func makeLog(n int) executionFunc {
+	return func(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+		topics := make([]common.Hash, n)
+		mStart, mSize := stack.pop(), stack.pop()
+		data := mem.Get(mStart.Uint64(), mSize.Uint64())
+		for i := 0; i < n; i++ {
+			topics[i] = stack.pop().Hash()
+		}
+
+		log := state.NewLog(context.Address(), topics, data, self.env.BlockNumber().Uint64())
+		self.env.AddLog(log)
+
+		self.Printf(" => %v", log)
+		return nil, nil
+	}
+}
+
+// 0xf0 range - closures
+
+func opCreate(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	var (
+		value        = stack.pop().Big()
+		offset, size = stack.pop(), stack.pop()
+		input        = mem.Get(offset.Uint64(), size.Uint64())
+		gas          = new(big.Int).Set(context.Gas)
+	)
+	self.Endl()
+
+	context.UseGas(context.Gas)
+	ret, suberr, ref := self.env.Create(context, input, gas, context.Price, value)
+	if suberr != nil {
+		stack.push(new(word))
+
+		self.Printf(" (*) 0x0 %v", suberr)
+	} else {
+		// gas < len(ret) * CreateDataGas == NO_CODE
+		dataGas := big.NewInt(int64(len(ret)))
+		dataGas.Mul(dataGas, params.CreateDataGas)
+		if context.UseGas(dataGas) {
+			ref.SetCode(ret)
+		}
+		addr := ref.Address()
+
+		stack.push(new(word).SetBytes(addr[:]))
+	}
+	return nil, nil
+}
+
+func opCall(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, call(self, CALL, context, mem, stack)
+}
+
+func opCallCode(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, call(self, CALLCODE, context, mem, stack)
+}
+
+func call(self *Vm, op OpCode, context *Context, mem *Memory, stack *stack) error {
+	gas := stack.pop().Big()
+	// pop gas and value of the stack.
+	address, value := stack.pop().Address(), stack.pop().Big()
+	// pop input size and offset
+	inOffset, inSize := stack.pop(), stack.pop()
+	// pop return size and offset
+	retOffset, retSize := stack.pop().Uint64(), stack.pop().Uint64()
+
+	self.Printf(" => %x", address).Endl()
+
+	// Get the arguments from the memory
+	args := mem.Get(inOffset.Uint64(), inSize.Uint64())
+
+	if len(value.Bytes()) > 0 {
+		gas.Add(gas, params.CallStipend)
+	}
+
+	var (
+		ret []byte
+		err error
+	)
+	if op == CALLCODE {
+		ret, err = self.env.CallCode(context, address, args, gas, context.Price, value)
+	} else {
+		ret, err = self.env.Call(context, address, args, gas, context.Price, value)
+	}
+
+	if err != nil {
+		stack.push(new(word))
+
+		self.Printf("%v", err).Endl()
+	} else {
+		stack.push(new(word).SetUint64(1))
+
+		mem.Set(retOffset, retSize, ret)
+	}
+	self.Printf("resume %x (%v)", context.Address(), context.Gas)
+	return nil
+}
+
+func opReturn(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	offset, size := stack.pop(), stack.pop()
+	ret := mem.Get(offset.Uint64(), size.Uint64())
+
+	self.Printf(" => [%v, %v] (%d) 0x%x", offset, size, len(ret), ret)
+	return ret, nil
+}
+
+func opSuicide(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	statedb := self.env.State()
+	receiver := statedb.GetOrNewStateObject(stack.pop().Address())
+	balance := statedb.GetBalance(context.Address())
+
+	self.Printf(" => (%x) %v", receiver.Address().Bytes()[:4], balance)
+
+	receiver.AddBalance(balance)
+
+	statedb.Delete(context.Address())
+	return nil, nil
+}
+
+func opStop(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error) {
+	return nil, nil
+}
+
+// jump moves the pc to the given destination, which must be a JUMPDEST.
+func (self *Vm) jump(pc *uint64, context *Context, to *word) error {
+	if !to.IsUint64() || !context.jumpdests.Has(to.Uint64()) {
+		return fmt.Errorf("invalid jump destination (%v) %v", context.GetOp(clampUint64(to)), to)
+	}
+
+	self.Printf(" ~> %v", to)
+	*pc = to.Uint64()
+
+	return nil
+}
+
+func setBool(x *word, b bool) {
+	if b {
+		x.SetUint64(1)
+	} else {
+		x.Clear()
+	}
+}
@@ -0,0 +1,305 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// executionFunc executes a single operation. Operations that move the pc
+// themselves (jumps and pushes) do so through pc.
+type executionFunc func(pc *uint64, self *Vm, context *Context, mem *Memory, stack *stack) ([]byte, error)
+
+type operation struct {
+	execute     executionFunc
+	constantGas uint64
+	dynamicGas  gasFunc        // nil if the cost is constant
+	memorySize  memorySizeFunc // nil if the operation doesn't touch memory
+
+	minStack int // minimum number of stack items required
+	maxStack int // maximum stack size before the operation
+
+	halts  bool // stops the execution and returns
+	jumps  bool // sets the pc, the interpreter doesn't advance it
+	writes bool // modifies the state
+	valid  bool // defined opcode
+}
+
+// InstructionSet maps every opcode to its operation. Fork specific rule sets
+// are expressed as different instruction sets.
+type InstructionSet [256]operation
+
+var FrontierInstructionSet = newFrontierInstructionSet()
+
+// validateStack checks the stack against the requirements of the operation.
+func (op *operation) validateStack(stack *stack) error {
+	if err := stack.require(op.minStack); err != nil {
+		return err
+	}
+	// NOTE the limit is checked against the allocated slots rather than the
+	// live items, which is what the consensus tests expect.
+	if len(stack.data) > op.maxStack {
+		return fmt.Errorf("stack limit reached %d (%d)", len(stack.data), params.StackLimit.Int64())
+	}
+	return nil
+}
+
+// maxStack returns the largest stack size allowing an operation that pops and
+// pushes the given number of items to run without exceeding the stack limit.
+func maxStack(pop, push int) int {
+	if push == 0 {
+		return int(^uint(0) >> 1)
+	}
+	return int(params.StackLimit.Int64()) + 1 + pop - push
+}
+
+func newFrontierInstructionSet() *InstructionSet {
+	set := &InstructionSet{
+		STOP: {
+			execute:  opStop,
+			maxStack: maxStack(0, 0),
+			halts:    true,
+			valid:    true,
+		},
+		ADD:        basicOp(opAdd, 2, GasFastestStep),
+		MUL:        basicOp(opMul, 2, GasFastStep),
+		SUB:        basicOp(opSub, 2, GasFastestStep),
+		DIV:        basicOp(opDiv, 2, GasFastStep),
+		SDIV:       basicOp(opSdiv, 2, GasFastStep),
+		MOD:        basicOp(opMod, 2, GasFastStep),
+		SMOD:       basicOp(opSmod, 2, GasFastStep),
+		ADDMOD:     basicOp(opAddmod, 3, GasMidStep),
+		MULMOD:     basicOp(opMulmod, 3, GasMidStep),
+		SIGNEXTEND: basicOp(opSignExtend, 2, GasFastStep),
+		EXP: {
+			execute:     opExp,
+			constantGas: GasSlowStep.Uint64(),
+			dynamicGas:  gasExp,
+			minStack:    2,
+			maxStack:    maxStack(2, 1),
+			valid:       true,
+		},
+		LT:     basicOp(opLt, 2, GasFastestStep),
+		GT:     basicOp(opGt, 2, GasFastestStep),
+		SLT:    basicOp(opSlt, 2, GasFastestStep),
+		SGT:    basicOp(opSgt, 2, GasFastestStep),
+		EQ:     basicOp(opEq, 2, GasFastestStep),
+		ISZERO: basicOp(opIszero, 1, GasFastestStep),
+		AND:    basicOp(opAnd, 2, GasFastestStep),
+		OR:     basicOp(opOr, 2, GasFastestStep),
+		XOR:    basicOp(opXor, 2, GasFastestStep),
+		NOT:    basicOp(opNot, 1, GasFastestStep),
+		BYTE:   basicOp(opByte, 2, GasFastestStep),
+		SHA3: {
+			execute:     opSha3,
+			constantGas: params.Sha3Gas.Uint64(),
+			dynamicGas:  gasSha3,
+			memorySize:  memorySha3,
+			minStack:    2,
+			maxStack:    maxStack(2, 1),
+			valid:       true,
+		},
+		ADDRESS:      basicOp(opAddress, 0, GasQuickStep),
+		BALANCE:      basicOp(opBalance, 1, GasExtStep),
+		ORIGIN:       basicOp(opOrigin, 0, GasQuickStep),
+		CALLER:       basicOp(opCaller, 0, GasQuickStep),
+		CALLVALUE:    basicOp(opCallValue, 0, GasQuickStep),
+		CALLDATALOAD: basicOp(opCalldataLoad, 1, GasFastestStep),
+		CALLDATASIZE: basicOp(opCalldataSize, 0, GasQuickStep),
+		CALLDATACOPY: {
+			execute:     opCalldataCopy,
+			constantGas: GasFastestStep.Uint64(),
+			dynamicGas:  gasCalldataCopy,
+			memorySize:  memoryCalldataCopy,
+			minStack:    3,
+			maxStack:    maxStack(3, 1),
+			valid:       true,
+		},
+		CODESIZE: basicOp(opCodeSize, 0, GasQuickStep),
+		CODECOPY: {
+			execute:     opCodeCopy,
+			constantGas: GasFastestStep.Uint64(),
+			dynamicGas:  gasCodeCopy,
+			memorySize:  memoryCodeCopy,
+			minStack:    3,
+			maxStack:    maxStack(3, 0),
+			valid:       true,
+		},
+		GASPRICE:    basicOp(opGasprice, 0, GasQuickStep),
+		EXTCODESIZE: basicOp(opExtCodeSize, 1, GasExtStep),
+		EXTCODECOPY: {
+			execute:     opExtCodeCopy,
+			constantGas: GasExtStep.Uint64(),
+			dynamicGas:  gasExtCodeCopy,
+			memorySize:  memoryExtCodeCopy,
+			minStack:    4,
+			maxStack:    maxStack(4, 0),
+			valid:       true,
+		},
+		BLOCKHASH:  basicOp(opBlockhash, 1, GasExtStep),
+		COINBASE:   basicOp(opCoinbase, 0, GasQuickStep),
+		TIMESTAMP:  basicOp(opTimestamp, 0, GasQuickStep),
+		NUMBER:     basicOp(opNumber, 0, GasQuickStep),
+		DIFFICULTY: basicOp(opDifficulty, 0, GasQuickStep),
+		GASLIMIT:   basicOp(opGasLimit, 0, GasQuickStep),
+		POP: {
+			execute:     opPop,
+			constantGas: GasQuickStep.Uint64(),
+			minStack:    1,
+			maxStack:    maxStack(1, 0),
+			valid:       true,
+		},
+		MLOAD: {
+			execute:     opMload,
+			constantGas: GasFastestStep.Uint64(),
+			memorySize:  memoryMLoad,
+			minStack:    1,
+			maxStack:    maxStack(1, 1),
+			valid:       true,
+		},
+		MSTORE: {
+			execute:     opMstore,
+			constantGas: GasFastestStep.Uint64(),
+			memorySize:  memoryMStore,
+			minStack:    2,
+			maxStack:    maxStack(2, 0),
+			valid:       true,
+		},
+		MSTORE8: {
+			execute:     opMstore8,
+			constantGas: GasFastestStep.Uint64(),
+			memorySize:  memoryMStore8,
+			minStack:    2,
+			maxStack:    maxStack(2, 0),
+			valid:       true,
+		},
+		SLOAD: basicOp(opSload, 1, params.SloadGas),
+		SSTORE: {
+			execute:    opSstore,
+			dynamicGas: gasSStore,
+			minStack:   2,
+			maxStack:   maxStack(2, 0),
+			writes:     true,
+			valid:      true,
+		},
+		JUMP: {
+			execute:     opJump,
+			constantGas: GasMidStep.Uint64(),
+			minStack:    1,
+			maxStack:    maxStack(1, 0),
+			jumps:       true,
+			valid:       true,
+		},
+		JUMPI: {
+			execute:     opJumpi,
+			constantGas: GasSlowStep.Uint64(),
+			minStack:    2,
+			maxStack:    maxStack(2, 0),
+			jumps:       true,
+			valid:       true,
+		},
+		PC:    basicOp(opPc, 0, GasQuickStep),
+		MSIZE: basicOp(opMsize, 0, GasQuickStep),
+		GAS:   basicOp(opGas, 0, GasQuickStep),
+		JUMPDEST: {
+			execute:     opJumpdest,
+			constantGas: params.JumpdestGas.Uint64(),
+			maxStack:    maxStack(0, 0),
+			valid:       true,
+		},
+		CREATE: {
+			execute:     opCreate,
+			constantGas: params.CreateGas.Uint64(),
+			memorySize:  memoryCreate,
+			minStack:    3,
+			maxStack:    maxStack(3, 1),
+			writes:      true,
+			valid:       true,
+		},
+		CALL: {
+			execute:     opCall,
+			constantGas: params.CallGas.Uint64(),
+			dynamicGas:  gasCall,
+			memorySize:  memoryCall,
+			minStack:    7,
+			maxStack:    maxStack(7, 1),
+			valid:       true,
+		},
+		CALLCODE: {
+			execute:     opCallCode,
+			constantGas: params.CallGas.Uint64(),
+			dynamicGas:  gasCallCode,
+			memorySize:  memoryCall,
+			minStack:    7,
+			maxStack:    maxStack(7, 1),
+			valid:       true,
+		},
+		RETURN: {
+			execute:    opReturn,
+			memorySize: memoryReturn,
+			minStack:   2,
+			maxStack:   maxStack(2, 0),
+			halts:      true,
+			valid:      true,
+		},
+		SUICIDE: {
+			execute:    opSuicide,
+			dynamicGas: gasSuicide,
+			minStack:   1,
+			maxStack:   maxStack(1, 0),
+			halts:      true,
+			writes:     true,
+			valid:      true,
+		},
+	}
+	for i := 0; i < 32; i++ {
+		set[PUSH1+OpCode(i)] = operation{
+			execute:     makePush(uint64(i + 1)),
+			constantGas: GasFastestStep.Uint64(),
+			maxStack:    maxStack(0, 1),
+			valid:       true,
+		}
+	}
+	for i := 0; i < 16; i++ {
+		// The DUP and SWAP stack requirements depend on the depth they reach
+		set[DUP1+OpCode(i)] = operation{
+			execute:     makeDup(i + 1),
+			constantGas: GasFastestStep.Uint64(),
+			minStack:    i + 1,
+			maxStack:    maxStack(0, 1),
+			valid:       true,
+		}
+		set[SWAP1+OpCode(i)] = operation{
+			execute:     makeSwap(i + 2),
+			constantGas: GasFastestStep.Uint64(),
+			minStack:    i + 2,
+			maxStack:    maxStack(0, 0),
+			valid:       true,
+		}
+	}
+	for i := 0; i <= 4; i++ {
+		set[LOG0+OpCode(i)] = operation{
+			execute:    makeLog(i),
+			dynamicGas: makeGasLog(uint64(i)),
+			memorySize: memoryLog,
+			minStack:   i + 2,
+			maxStack:   maxStack(0, 0),
+			writes:     true,
+			valid:      true,
+		}
+	}
+	return set
+}
+
+// basicOp returns an operation of constant gas popping the given number of
+// items and pushing its result.
+func basicOp(execute executionFunc, pop int, gas *big.Int) operation {
+	return operation{
+		execute:     execute,
+		constantGas: gas.Uint64(),
+		minStack:    pop,
+		maxStack:    maxStack(pop, 1),
+		valid:       true,
+	}
+}
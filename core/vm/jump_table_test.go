@@ -0,0 +1,19 @@
+package vm
+
+import "testing"
+
+func TestFrontierInstructionSet(t *testing.T) {
+	for i, operation := range FrontierInstructionSet {
+		op := OpCode(i)
+		_, named := opCodeToString[op]
+		if operation.valid != named {
+			t.Errorf("%v: valid %v, named %v", op, operation.valid, named)
+		}
+		if operation.valid && operation.execute == nil {
+			t.Errorf("%v: missing execute function", op)
+		}
+		if operation.halts && operation.jumps {
+			t.Errorf("%v: both halts and jumps", op)
+		}
+	}
+}
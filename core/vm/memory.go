@@ -34,12 +34,12 @@ func (m *Memory) Resize(size uint64) {
 	}
 }
 
-func (self *Memory) Get(offset, size int64) (cpy []byte) {
+func (self *Memory) Get(offset, size uint64) (cpy []byte) {
 	if size == 0 {
 		return nil
 	}
 
-	if len(self.store) > int(offset) {
+	if uint64(len(self.store)) > offset {
 		cpy = make([]byte, size)
 		copy(cpy, self.store[offset:offset+size])
 
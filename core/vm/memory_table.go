@@ -0,0 +1,62 @@
+package vm
+
+// memorySizeFunc returns the memory size required by an operation, before
+// rounding up to whole words. ok is false if the size exceeds maxMemSize.
+type memorySizeFunc func(stack *stack) (size uint64, ok bool)
+
+func memoryMLoad(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), &word{32})
+}
+
+func memoryMStore8(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), &word{1})
+}
+
+func memoryMStore(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), &word{32})
+}
+
+func memoryReturn(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), stack.back(1))
+}
+
+func memorySha3(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), stack.back(1))
+}
+
+func memoryLog(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), stack.back(1))
+}
+
+func memoryCalldataCopy(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), stack.back(2))
+}
+
+func memoryCodeCopy(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(0), stack.back(2))
+}
+
+func memoryExtCodeCopy(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(1), stack.back(3))
+}
+
+func memoryCreate(stack *stack) (uint64, bool) {
+	return calcMemSize(stack.back(1), stack.back(2))
+}
+
+// memoryCall returns the larger of the return and input areas of a CALL or
+// CALLCODE.
+func memoryCall(stack *stack) (uint64, bool) {
+	ret, ok := calcMemSize(stack.back(5), stack.back(6))
+	if !ok {
+		return 0, false
+	}
+	in, ok := calcMemSize(stack.back(3), stack.back(4))
+	if !ok {
+		return 0, false
+	}
+	if ret > in {
+		return ret, true
+	}
+	return in, true
+}
@@ -0,0 +1,89 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OpStats holds the aggregated cost of an opcode or of a single instruction.
+type OpStats struct {
+	Count     uint64
+	Gas       uint64 // total gas, including memory expansion
+	MemoryGas uint64 // memory expansion part of Gas
+}
+
+func (self *OpStats) add(gas, memGas uint64) {
+	self.Count++
+	self.Gas += gas
+	self.MemoryGas += memGas
+}
+
+// CodeProfile holds the per instruction statistics of a piece of code.
+type CodeProfile struct {
+	Address common.Address
+	Init    bool // contract creation code
+	Code    []byte
+	PCs     map[uint64]*OpStats
+}
+
+type codeKey struct {
+	addr common.Address
+	init bool
+}
+
+// Profiler aggregates where the interpreter spends gas, per opcode and per
+// program counter of every executed piece of code. A profiler is attached to
+// an Environment and must not be shared between concurrent executions.
+type Profiler struct {
+	Ops   [256]OpStats
+	codes map[codeKey]*CodeProfile
+	gas   uint64 // total gas recorded so far
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{codes: make(map[codeKey]*CodeProfile)}
+}
+
+// Codes returns the profiles of all executed code.
+func (self *Profiler) Codes() []*CodeProfile {
+	codes := make([]*CodeProfile, 0, len(self.codes))
+	for _, code := range self.codes {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// record accounts an executed instruction. gas is the amount charged up front,
+// gasLeft and recorded are the gas of the context and the total recorded by the
+// profiler just before the instruction ran. The gas a CALL, CALLCODE or CREATE
+// hands to its callee is recorded by the callee's instructions, so only the
+// part consumed by the instruction itself is attributed to it.
+func (self *Profiler) record(context *Context, pc uint64, op OpCode, gas, memGas uint64, gasLeft *big.Int, recorded uint64) {
+	own := new(big.Int).Sub(gasLeft, context.Gas)
+	own.Add(own, new(big.Int).SetUint64(gas))
+	own.Sub(own, new(big.Int).SetUint64(self.gas-recorded))
+	if own.Sign() < 0 {
+		own.SetUint64(0)
+	}
+	gas = own.Uint64()
+	self.gas += gas
+
+	self.Ops[op].add(gas, memGas)
+
+	key := codeKey{context.Address(), context.CodeAddr == nil}
+	if context.CodeAddr != nil {
+		key.addr = *context.CodeAddr
+	}
+	code := self.codes[key]
+	if code == nil {
+		code = &CodeProfile{Address: key.addr, Init: key.init, Code: context.Code, PCs: make(map[uint64]*OpStats)}
+		self.codes[key] = code
+	}
+	stats := code.PCs[pc]
+	if stats == nil {
+		stats = new(OpStats)
+		code.PCs[pc] = stats
+	}
+	stats.add(gas, memGas)
+}
@@ -1,34 +1,33 @@
 package vm
 
-import (
-	"fmt"
-	"math/big"
-)
+import "fmt"
 
 func newStack() *stack {
 	return &stack{}
 }
 
+// stack holds the items of the VM stack by value. Slots are reused between
+// pushes so that stack operations don't allocate.
 type stack struct {
-	data []*big.Int
+	data []word
 	ptr  int
 }
 
-func (st *stack) push(d *big.Int) {
-	// NOTE push limit (1024) is checked in baseCheck
-	stackItem := new(big.Int).Set(d)
+func (st *stack) push(d *word) {
+	// NOTE push limit (1024) is checked by the instruction set
 	if len(st.data) > st.ptr {
-		st.data[st.ptr] = stackItem
+		st.data[st.ptr] = *d
 	} else {
-		st.data = append(st.data, stackItem)
+		st.data = append(st.data, *d)
 	}
 	st.ptr++
 }
 
-func (st *stack) pop() (ret *big.Int) {
+// pop removes the top item of the stack. The returned pointer refers to the
+// freed slot and is only valid until the next push.
+func (st *stack) pop() *word {
 	st.ptr--
-	ret = st.data[st.ptr]
-	return
+	return &st.data[st.ptr]
 }
 
 func (st *stack) len() int {
@@ -40,11 +39,17 @@ func (st *stack) swap(n int) {
 }
 
 func (st *stack) dup(n int) {
-	st.push(st.data[st.len()-n])
+	st.push(&st.data[st.len()-n])
 }
 
-func (st *stack) peek() *big.Int {
-	return st.data[st.len()-1]
+func (st *stack) peek() *word {
+	return &st.data[st.len()-1]
+}
+
+// back returns the n'th item from the top of the stack, back(0) being the
+// top item.
+func (st *stack) back(n int) *word {
+	return &st.data[st.len()-n-1]
 }
 
 func (st *stack) require(n int) error {
@@ -57,8 +62,8 @@ func (st *stack) require(n int) error {
 func (st *stack) Print() {
 	fmt.Println("### stack ###")
 	if len(st.data) > 0 {
-		for i, val := range st.data {
-			fmt.Printf("%-3d  %v\n", i, val)
+		for i := range st.data {
+			fmt.Printf("%-3d  %v\n", i, &st.data[i])
 		}
 	} else {
 		fmt.Println("-- empty --")
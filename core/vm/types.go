@@ -332,3 +332,14 @@ func (o OpCode) String() string {
 
 	return str
 }
+
+// IsPush reports whether the opcode is one of PUSH1 to PUSH32.
+func (o OpCode) IsPush() bool {
+	return o >= PUSH1 && o <= PUSH32
+}
+
+// IsValid reports whether the opcode is a defined instruction.
+func (o OpCode) IsValid() bool {
+	_, ok := opCodeToString[o]
+	return ok
+}
@@ -151,7 +151,7 @@ func (self *JitVm) Run(me, caller ContextRef, code []byte, value, gas, price *bi
 	self.env.SetDepth(self.env.Depth() + 1)
 
 	// TODO: Move it to Env.Call() or sth
-	if Precompiled[string(me.Address())] != nil {
+	if precompiled(self.env).Get(me.Address(), self.env.BlockNumber()) != nil {
 		// if it's address of precopiled contract
 		// fallback to standard VM
 		stdVm := New(self.env)
@@ -0,0 +1,390 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// word is a 256 bit unsigned integer stored as four 64 bit limbs, least
+// significant limb first. All arithmetic wraps around modulo 2^256, signed
+// operations interpret the value as two's complement.
+type word [4]uint64
+
+func (z *word) Clear() *word {
+	*z = word{}
+	return z
+}
+
+func (z *word) Set(x *word) *word {
+	*z = *x
+	return z
+}
+
+func (z *word) SetUint64(x uint64) *word {
+	*z = word{x}
+	return z
+}
+
+// SetBytes interprets b as a big endian unsigned integer. Only the last 32
+// bytes are used if b is longer.
+func (z *word) SetBytes(b []byte) *word {
+	if len(b) > 32 {
+		b = b[len(b)-32:]
+	}
+	*z = word{}
+	for i, j := len(b)-1, uint(0); i >= 0; i, j = i-1, j+8 {
+		z[j/64] |= uint64(b[i]) << (j % 64)
+	}
+	return z
+}
+
+// bigWordBits is the size of a math/big limb, which allows converting
+// directly between limbs on 64 bit platforms.
+const bigWordBits = 32 << (uint64(^big.Word(0)) >> 63)
+
+// SetBig sets z to x modulo 2^256. Negative values are stored in two's
+// complement.
+func (z *word) SetBig(x *big.Int) *word {
+	if x.Sign() >= 0 && x.BitLen() <= 64 {
+		return z.SetUint64(x.Uint64())
+	}
+	if bigWordBits == 64 {
+		*z = word{}
+		for i, limb := range x.Bits() {
+			if i == 4 {
+				break
+			}
+			z[i] = uint64(limb)
+		}
+	} else {
+		z.SetBytes(x.Bytes())
+	}
+	if x.Sign() < 0 {
+		z.Neg(z)
+	}
+	return z
+}
+
+func (x *word) Big() *big.Int {
+	if x.IsUint64() {
+		return new(big.Int).SetUint64(x[0])
+	}
+	if bigWordBits == 64 {
+		return new(big.Int).SetBits([]big.Word{big.Word(x[0]), big.Word(x[1]), big.Word(x[2]), big.Word(x[3])})
+	}
+	b := x.Bytes32()
+	return new(big.Int).SetBytes(b[:])
+}
+
+// Bytes32 returns the big endian representation of x padded to 32 bytes.
+func (x *word) Bytes32() (b [32]byte) {
+	for i := 0; i < 32; i++ {
+		b[31-i] = byte(x[i/8] >> (uint(i%8) * 8))
+	}
+	return b
+}
+
+// Bytes returns the minimal big endian representation of x, like
+// big.Int.Bytes does.
+func (x *word) Bytes() []byte {
+	b := x.Bytes32()
+	return common.CopyBytes(b[32-(x.BitLen()+7)/8:])
+}
+
+func (x *word) Address() common.Address {
+	b := x.Bytes32()
+	return common.BytesToAddress(b[12:])
+}
+
+func (x *word) Hash() common.Hash {
+	return common.Hash(x.Bytes32())
+}
+
+func (x *word) String() string {
+	return x.Big().String()
+}
+
+func (x *word) IsZero() bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+func (x *word) IsUint64() bool {
+	return x[1]|x[2]|x[3] == 0
+}
+
+func (x *word) Uint64() uint64 {
+	return x[0]
+}
+
+// isNeg reports whether x is negative when interpreted as two's complement.
+func (x *word) isNeg() bool {
+	return x[3]>>63 == 1
+}
+
+func (x *word) BitLen() int {
+	for i := 3; i >= 0; i-- {
+		if x[i] != 0 {
+			n := 0
+			for v := x[i]; v != 0; v >>= 1 {
+				n++
+			}
+			return i*64 + n
+		}
+	}
+	return 0
+}
+
+func (x *word) bit(i int) uint64 {
+	return (x[i/64] >> uint(i%64)) & 1
+}
+
+// Cmp compares x and y as unsigned integers.
+func (x *word) Cmp(y *word) int {
+	for i := 3; i >= 0; i-- {
+		switch {
+		case x[i] < y[i]:
+			return -1
+		case x[i] > y[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// SCmp compares x and y as two's complement signed integers.
+func (x *word) SCmp(y *word) int {
+	xneg, yneg := x.isNeg(), y.isNeg()
+	switch {
+	case xneg && !yneg:
+		return -1
+	case !xneg && yneg:
+		return 1
+	}
+	return x.Cmp(y)
+}
+
+func (z *word) Add(x, y *word) *word {
+	var c uint64
+	z[0], c = add64(x[0], y[0], 0)
+	z[1], c = add64(x[1], y[1], c)
+	z[2], c = add64(x[2], y[2], c)
+	z[3], _ = add64(x[3], y[3], c)
+	return z
+}
+
+func (z *word) Sub(x, y *word) *word {
+	var b uint64
+	z[0], b = sub64(x[0], y[0], 0)
+	z[1], b = sub64(x[1], y[1], b)
+	z[2], b = sub64(x[2], y[2], b)
+	z[3], _ = sub64(x[3], y[3], b)
+	return z
+}
+
+func (z *word) Neg(x *word) *word {
+	return z.Sub(&word{}, x)
+}
+
+func (z *word) Mul(x, y *word) *word {
+	var res word
+	for i := 0; i < 4; i++ {
+		if x[i] == 0 {
+			continue
+		}
+		var carry uint64
+		for j := 0; i+j < 4; j++ {
+			hi, lo := mul64(x[i], y[j])
+			var c uint64
+			lo, c = add64(lo, res[i+j], 0)
+			hi += c
+			lo, c = add64(lo, carry, 0)
+			hi += c
+			res[i+j], carry = lo, hi
+		}
+	}
+	*z = res
+	return z
+}
+
+// Div sets z to x / y, or zero if y is zero.
+func (z *word) Div(x, y *word) *word {
+	q, _ := divmod(x, y)
+	*z = q
+	return z
+}
+
+// Mod sets z to x % y, or zero if y is zero.
+func (z *word) Mod(x, y *word) *word {
+	_, r := divmod(x, y)
+	*z = r
+	return z
+}
+
+// SDiv sets z to the signed quotient x / y rounded towards zero, or zero if
+// y is zero.
+func (z *word) SDiv(x, y *word) *word {
+	var ax, ay word
+	ax.abs(x)
+	ay.abs(y)
+	neg := x.isNeg() != y.isNeg()
+	z.Div(&ax, &ay)
+	if neg {
+		z.Neg(z)
+	}
+	return z
+}
+
+// SMod sets z to the signed remainder of x / y, taking the sign of x, or
+// zero if y is zero.
+func (z *word) SMod(x, y *word) *word {
+	var ax, ay word
+	ax.abs(x)
+	ay.abs(y)
+	neg := x.isNeg()
+	z.Mod(&ax, &ay)
+	if neg {
+		z.Neg(z)
+	}
+	return z
+}
+
+func (z *word) abs(x *word) *word {
+	if x.isNeg() {
+		return z.Neg(x)
+	}
+	return z.Set(x)
+}
+
+// AddMod sets z to (x + y) % m without wrapping the intermediate sum, or zero
+// if m is zero.
+func (z *word) AddMod(x, y, m *word) *word {
+	if m.IsZero() {
+		return z.Clear()
+	}
+	sum := new(big.Int).Add(x.Big(), y.Big())
+	return z.SetBig(sum.Mod(sum, m.Big()))
+}
+
+// MulMod sets z to (x * y) % m without wrapping the intermediate product, or
+// zero if m is zero.
+func (z *word) MulMod(x, y, m *word) *word {
+	if m.IsZero() {
+		return z.Clear()
+	}
+	prod := new(big.Int).Mul(x.Big(), y.Big())
+	return z.SetBig(prod.Mod(prod, m.Big()))
+}
+
+// Exp sets z to x**y modulo 2^256.
+func (z *word) Exp(x, y *word) *word {
+	base, res := *x, word{1}
+	for i, n := 0, y.BitLen(); i < n; i++ {
+		if y.bit(i) == 1 {
+			res.Mul(&res, &base)
+		}
+		base.Mul(&base, &base)
+	}
+	*z = res
+	return z
+}
+
+// SignExtend extends the sign of x from the byte at position back, counted
+// from the least significant byte. x is returned unchanged if back >= 31.
+func (z *word) SignExtend(back, x *word) *word {
+	if !back.IsUint64() || back[0] >= 31 {
+		return z.Set(x)
+	}
+	bit := uint(back[0]*8 + 7)
+	limb, shift := bit/64, bit%64
+	*z = *x
+	if x.bit(int(bit)) == 1 {
+		z[limb] |= ^uint64(0) << shift
+		for i := limb + 1; i < 4; i++ {
+			z[i] = ^uint64(0)
+		}
+	} else {
+		z[limb] &= (uint64(1) << shift << 1) - 1
+		for i := limb + 1; i < 4; i++ {
+			z[i] = 0
+		}
+	}
+	return z
+}
+
+func (z *word) Not(x *word) *word {
+	z[0], z[1], z[2], z[3] = ^x[0], ^x[1], ^x[2], ^x[3]
+	return z
+}
+
+func (z *word) And(x, y *word) *word {
+	z[0], z[1], z[2], z[3] = x[0]&y[0], x[1]&y[1], x[2]&y[2], x[3]&y[3]
+	return z
+}
+
+func (z *word) Or(x, y *word) *word {
+	z[0], z[1], z[2], z[3] = x[0]|y[0], x[1]|y[1], x[2]|y[2], x[3]|y[3]
+	return z
+}
+
+func (z *word) Xor(x, y *word) *word {
+	z[0], z[1], z[2], z[3] = x[0]^y[0], x[1]^y[1], x[2]^y[2], x[3]^y[3]
+	return z
+}
+
+// Byte sets z to the n'th byte of x, counted from the most significant
+// byte, or zero if n >= 32.
+func (z *word) Byte(n, x *word) *word {
+	if !n.IsUint64() || n[0] >= 32 {
+		return z.Clear()
+	}
+	i := 31 - n[0]
+	return z.SetUint64((x[i/8] >> ((i % 8) * 8)) & 0xff)
+}
+
+// divmod returns the quotient and remainder of x / y. Both are zero if y is
+// zero.
+func divmod(x, y *word) (q, r word) {
+	if y.IsZero() {
+		return q, r
+	}
+	if x.Cmp(y) < 0 {
+		return q, *x
+	}
+	if x.IsUint64() {
+		return word{x[0] / y[0]}, word{x[0] % y[0]}
+	}
+
+	// Wide divisions are rare, leave them to math/big
+	bq, br := new(big.Int).QuoRem(x.Big(), y.Big(), new(big.Int))
+	q.SetBig(bq)
+	r.SetBig(br)
+
+	return q, r
+}
+
+func add64(x, y, carry uint64) (sum, carryOut uint64) {
+	sum = x + y + carry
+	carryOut = ((x & y) | ((x | y) &^ sum)) >> 63
+	return sum, carryOut
+}
+
+func sub64(x, y, borrow uint64) (diff, borrowOut uint64) {
+	diff = x - y - borrow
+	borrowOut = ((^x & y) | (^(x ^ y) & diff)) >> 63
+	return diff, borrowOut
+}
+
+// mul64 returns the 128 bit product of x and y.
+func mul64(x, y uint64) (hi, lo uint64) {
+	const mask32 = 1<<32 - 1
+	x0, x1 := x&mask32, x>>32
+	y0, y1 := y&mask32, y>>32
+	w0 := x0 * y0
+	t := x1*y0 + w0>>32
+	w1, w2 := t&mask32, t>>32
+	w1 += x0 * y1
+	hi = x1*y1 + w2 + w1>>32
+	lo = x * y
+	return hi, lo
+}
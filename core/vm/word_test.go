@@ -0,0 +1,223 @@
+package vm
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var tt256 = new(big.Int).Lsh(common.Big1, 256)
+
+// randWord returns a random word, biased towards edge cases such as small
+// values, values with the top bit set and all ones.
+func randWord(r *rand.Rand) *word {
+	var w word
+	switch r.Intn(5) {
+	case 0:
+		w.SetUint64(uint64(r.Intn(4)))
+	case 1:
+		w.Not(&w)
+	case 2:
+		w[0] = r.Uint64()
+	default:
+		for i := range w {
+			if r.Intn(3) > 0 {
+				w[i] = r.Uint64()
+			}
+		}
+	}
+	return &w
+}
+
+func signed(x *big.Int) *big.Int {
+	return common.S256(new(big.Int).Set(x))
+}
+
+// bigOps implements the word operations on big integers as the reference.
+var bigOps = map[string]func(x, y, m *big.Int) *big.Int{
+	"Add": func(x, y, m *big.Int) *big.Int { return new(big.Int).Add(x, y) },
+	"Sub": func(x, y, m *big.Int) *big.Int { return new(big.Int).Sub(x, y) },
+	"Mul": func(x, y, m *big.Int) *big.Int { return new(big.Int).Mul(x, y) },
+	"Div": func(x, y, m *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Div(x, y)
+	},
+	"Mod": func(x, y, m *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Mod(x, y)
+	},
+	"SDiv": func(x, y, m *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Quo(signed(x), signed(y))
+	},
+	"SMod": func(x, y, m *big.Int) *big.Int {
+		if y.Sign() == 0 {
+			return new(big.Int)
+		}
+		return new(big.Int).Rem(signed(x), signed(y))
+	},
+	"Exp": func(x, y, m *big.Int) *big.Int { return new(big.Int).Exp(x, y, tt256) },
+	"AddMod": func(x, y, m *big.Int) *big.Int {
+		if m.Sign() == 0 {
+			return new(big.Int)
+		}
+		sum := new(big.Int).Add(x, y)
+		return sum.Mod(sum, m)
+	},
+	"MulMod": func(x, y, m *big.Int) *big.Int {
+		if m.Sign() == 0 {
+			return new(big.Int)
+		}
+		prod := new(big.Int).Mul(x, y)
+		return prod.Mod(prod, m)
+	},
+	"And": func(x, y, m *big.Int) *big.Int { return new(big.Int).And(x, y) },
+	"Or":  func(x, y, m *big.Int) *big.Int { return new(big.Int).Or(x, y) },
+	"Xor": func(x, y, m *big.Int) *big.Int { return new(big.Int).Xor(x, y) },
+	"Not": func(x, y, m *big.Int) *big.Int { return new(big.Int).Not(x) },
+}
+
+var wordOps = map[string]func(z, x, y, m *word){
+	"Add":    func(z, x, y, m *word) { z.Add(x, y) },
+	"Sub":    func(z, x, y, m *word) { z.Sub(x, y) },
+	"Mul":    func(z, x, y, m *word) { z.Mul(x, y) },
+	"Div":    func(z, x, y, m *word) { z.Div(x, y) },
+	"Mod":    func(z, x, y, m *word) { z.Mod(x, y) },
+	"SDiv":   func(z, x, y, m *word) { z.SDiv(x, y) },
+	"SMod":   func(z, x, y, m *word) { z.SMod(x, y) },
+	"Exp":    func(z, x, y, m *word) { z.Exp(x, y) },
+	"AddMod": func(z, x, y, m *word) { z.AddMod(x, y, m) },
+	"MulMod": func(z, x, y, m *word) { z.MulMod(x, y, m) },
+	"And":    func(z, x, y, m *word) { z.And(x, y) },
+	"Or":     func(z, x, y, m *word) { z.Or(x, y) },
+	"Xor":    func(z, x, y, m *word) { z.Xor(x, y) },
+	"Not":    func(z, x, y, m *word) { z.Not(x) },
+}
+
+func TestWordOps(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for name, op := range wordOps {
+		for i := 0; i < 2000; i++ {
+			x, y, m := randWord(r), randWord(r), randWord(r)
+
+			var z word
+			op(&z, x, y, m)
+			want := common.U256(bigOps[name](x.Big(), y.Big(), m.Big()))
+			if z.Big().Cmp(want) != 0 {
+				t.Fatalf("%s(%v, %v, %v) = %v, want %v", name, x, y, m, &z, want)
+			}
+		}
+	}
+}
+
+func TestWordCmp(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x, y := randWord(r), randWord(r)
+		if have, want := x.Cmp(y), x.Big().Cmp(y.Big()); have != want {
+			t.Fatalf("Cmp(%v, %v) = %d, want %d", x, y, have, want)
+		}
+		if have, want := x.SCmp(y), signed(x.Big()).Cmp(signed(y.Big())); have != want {
+			t.Fatalf("SCmp(%v, %v) = %d, want %d", x, y, have, want)
+		}
+	}
+}
+
+func TestWordSignExtend(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x := randWord(r)
+		back := uint64(r.Intn(34))
+
+		var z word
+		z.SignExtend(new(word).SetUint64(back), x)
+
+		want := x.Big()
+		if back < 31 {
+			bit := uint(back*8 + 7)
+			mask := new(big.Int).Sub(new(big.Int).Lsh(common.Big1, bit), common.Big1)
+			if want.Bit(int(bit)) == 1 {
+				want.Or(want, mask.Not(mask))
+			} else {
+				want.And(want, mask)
+			}
+			common.U256(want)
+		}
+		if z.Big().Cmp(want) != 0 {
+			t.Fatalf("SignExtend(%d, %v) = %v, want %v", back, x, &z, want)
+		}
+	}
+}
+
+func TestWordConversion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x := randWord(r)
+		if have := new(word).SetBytes(x.Bytes()); *have != *x {
+			t.Fatalf("SetBytes(%x) = %v, want %v", x.Bytes(), have, x)
+		}
+		if have := new(word).SetBig(x.Big()); *have != *x {
+			t.Fatalf("SetBig(%v) = %v, want %v", x.Big(), have, x)
+		}
+		if have, want := x.Bytes(), x.Big().Bytes(); string(have) != string(want) {
+			t.Fatalf("Bytes() = %x, want %x", have, want)
+		}
+		if have, want := x.BitLen(), x.Big().BitLen(); have != want {
+			t.Fatalf("BitLen() = %d, want %d", have, want)
+		}
+		n := uint64(r.Intn(34))
+		want := uint64(common.LeftPadBytes(x.Bytes(), 32)[n%32])
+		if n >= 32 {
+			want = 0
+		}
+		if have := new(word).Byte(new(word).SetUint64(n), x); !have.IsUint64() || have.Uint64() != want {
+			t.Fatalf("Byte(%d, %v) = %v, want %d", n, x, have, want)
+		}
+	}
+	if have := new(word).SetBig(big.NewInt(-1)); *have != (word{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}) {
+		t.Fatalf("SetBig(-1) = %v", have)
+	}
+}
+
+func benchmarkWordOp(b *testing.B, name string) {
+	r := rand.New(rand.NewSource(1))
+	x, y, m := randWord(r), randWord(r), randWord(r)
+	x.Not(&word{})
+	op := wordOps[name]
+
+	var z word
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(&z, x, y, m)
+	}
+}
+
+func benchmarkBigOp(b *testing.B, name string) {
+	r := rand.New(rand.NewSource(1))
+	x, y, m := randWord(r), randWord(r), randWord(r)
+	x.Not(&word{})
+	bx, by, bm := x.Big(), y.Big(), m.Big()
+	op := bigOps[name]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		common.U256(op(bx, by, bm))
+	}
+}
+
+func BenchmarkWordAdd(b *testing.B) { benchmarkWordOp(b, "Add") }
+func BenchmarkBigAdd(b *testing.B)  { benchmarkBigOp(b, "Add") }
+func BenchmarkWordMul(b *testing.B) { benchmarkWordOp(b, "Mul") }
+func BenchmarkBigMul(b *testing.B)  { benchmarkBigOp(b, "Mul") }
+func BenchmarkWordDiv(b *testing.B) { benchmarkWordOp(b, "Div") }
+func BenchmarkBigDiv(b *testing.B)  { benchmarkBigOp(b, "Div") }
+func BenchmarkWordExp(b *testing.B) { benchmarkWordOp(b, "Exp") }
+func BenchmarkBigExp(b *testing.B)  { benchmarkBigOp(b, "Exp") }
@@ -16,6 +16,8 @@ type VMEnv struct {
 	depth int
 	chain *ChainManager
 	typ   vm.Type
+
+	profiler *vm.Profiler
 }
 
 func NewEnv(state *state.StateDB, chain *ChainManager, msg Message, block *types.Block) *VMEnv {
@@ -40,6 +42,16 @@ func (self *VMEnv) Depth() int               { return self.depth }
 func (self *VMEnv) SetDepth(i int)           { self.depth = i }
 func (self *VMEnv) VmType() vm.Type          { return self.typ }
 func (self *VMEnv) SetVmType(t vm.Type)      { self.typ = t }
+func (self *VMEnv) Profiler() *vm.Profiler   { return self.profiler }
+func (self *VMEnv) SetProfiler(p *vm.Profiler) {
+	self.profiler = p
+}
+func (self *VMEnv) Precompiled() *vm.PrecompiledRegistry {
+	if self.chain == nil {
+		return nil
+	}
+	return self.chain.Precompiled()
+}
 func (self *VMEnv) GetHash(n uint64) common.Hash {
 	if block := self.chain.GetBlockByNumber(n); block != nil {
 		return block.Hash()
@@ -54,9 +66,16 @@ func (self *VMEnv) Transfer(from, to vm.Account, amount *big.Int) error {
 	return vm.Transfer(from, to, amount)
 }
 
+// Call and Create record value transfers made by contracts, those of the
+// transaction itself happen at depth 0.
 func (self *VMEnv) Call(me vm.ContextRef, addr common.Address, data []byte, gas, price, value *big.Int) ([]byte, error) {
+	depth := self.depth
 	exe := NewExecution(self, &addr, data, gas, price, value)
-	return exe.Call(addr, me)
+	ret, err := exe.Call(addr, me)
+	if err == nil && depth > 0 && value.Sign() > 0 {
+		self.state.AddTransfer(me.Address(), addr, value)
+	}
+	return ret, err
 }
 func (self *VMEnv) CallCode(me vm.ContextRef, addr common.Address, data []byte, gas, price, value *big.Int) ([]byte, error) {
 	maddr := me.Address()
@@ -65,6 +84,11 @@ func (self *VMEnv) CallCode(me vm.ContextRef, addr common.Address, data []byte,
 }
 
 func (self *VMEnv) Create(me vm.ContextRef, data []byte, gas, price, value *big.Int) ([]byte, error, vm.ContextRef) {
+	depth := self.depth
 	exe := NewExecution(self, nil, data, gas, price, value)
-	return exe.Create(me)
+	ret, err, ref := exe.Create(me)
+	if err == nil && depth > 0 && value.Sign() > 0 && ref != nil {
+		self.state.AddTransfer(me.Address(), ref.Address(), value)
+	}
+	return ret, err, ref
 }
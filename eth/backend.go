@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/whisper"
 )
 
@@ -61,6 +62,18 @@ type Config struct {
 	VmDebug   bool
 	NatSpec   bool
 
+	// Preimages enables recording of secure trie key preimages
+	// in the state database.
+	Preimages bool
+
+	// AddressIndex enables indexing the transactions of each address
+	// in the extra database.
+	AddressIndex bool
+
+	// Precompiled holds the native contracts of the chain.
+	// If nil, the frontier contracts are used.
+	Precompiled *vm.PrecompiledRegistry
+
 	MaxPeers        int
 	MaxPendingPeers int
 	Port            string
@@ -177,6 +190,7 @@ type Ethereum struct {
 	blockProcessor  *core.BlockProcessor
 	txPool          *core.TxPool
 	chainManager    *core.ChainManager
+	bloomIndexer    *core.BloomIndexer
 	accountManager  *accounts.Manager
 	whisper         *whisper.Whisper
 	pow             *ethash.Ethash
@@ -219,6 +233,9 @@ func New(config *Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if config.Preimages {
+		stateDb = trie.NewPreimageDatabase(stateDb)
+	}
 	extraDb, err := newdb(path.Join(config.DataDir, "extra"))
 	if err != nil {
 		return nil, err
@@ -267,6 +284,12 @@ func New(config *Config) (*Ethereum, error) {
 	eth.txPool = core.NewTxPool(eth.EventMux(), eth.chainManager.State, eth.chainManager.GasLimit)
 	eth.blockProcessor = core.NewBlockProcessor(stateDb, extraDb, eth.pow, eth.txPool, eth.chainManager, eth.EventMux())
 	eth.chainManager.SetProcessor(eth.blockProcessor)
+	eth.chainManager.SetPrecompiled(config.Precompiled)
+	if config.AddressIndex {
+		eth.chainManager.SetAddressIndex(core.NewAddressIndex(extraDb))
+	}
+	eth.bloomIndexer = core.NewBloomIndexer(extraDb, eth.chainManager, eth.EventMux(), core.BloomBitsSection)
+	eth.chainManager.SetBloomIndexer(eth.bloomIndexer)
 	eth.miner = miner.New(eth, eth.pow, config.MinerThreads)
 	eth.miner.SetGasPrice(config.GasPrice)
 
@@ -437,6 +460,7 @@ func (s *Ethereum) Start() error {
 
 	// Start services
 	go s.txPool.Start()
+	s.bloomIndexer.Start()
 	s.protocolManager.Start()
 
 	if s.whisper != nil {
@@ -507,6 +531,7 @@ func (s *Ethereum) Stop() {
 	s.txSub.Unsubscribe() // quits txBroadcastLoop
 
 	s.protocolManager.Stop()
+	s.bloomIndexer.Stop()
 	s.chainManager.Stop()
 	s.txPool.Stop()
 	s.eventMux.Stop()
@@ -98,6 +98,11 @@ func (d *Downloader) Stats() (current int, max int) {
 	return d.queue.Size()
 }
 
+// Synchronising reports whether a synchronisation is running.
+func (d *Downloader) Synchronising() bool {
+	return atomic.LoadInt32(&d.synchronising) > 0
+}
+
 func (d *Downloader) RegisterPeer(id string, hash common.Hash, getHashes hashFetcherFn, getBlocks blockFetcherFn) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
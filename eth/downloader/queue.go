@@ -306,6 +306,7 @@ func (q *queue) Deliver(id string, blocks []*types.Block) (err error) {
 			continue
 		}
 		// Otherwise merge the block and mark the hash block
+		block.ReceivedFrom = id
 		q.blockCache[index] = block
 
 		delete(request.Hashes, hash)
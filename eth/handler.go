@@ -275,6 +275,7 @@ func (self *ProtocolManager) handleMsg(p *peer) error {
 			return errResp(ErrDecode, "block validation %v: %v", msg, err)
 		}
 		request.Block.ReceivedAt = msg.ReceivedAt
+		request.Block.ReceivedFrom = p.id
 
 		hash := request.Block.Hash()
 		// Add the block hash as a known hash to the peer. This will later be used to determine
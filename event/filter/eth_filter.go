@@ -67,6 +67,7 @@ func (self *FilterManager) filterLoop() {
 		//core.PendingBlockEvent{},
 		core.ChainEvent{},
 		core.TxPreEvent{},
+		core.RemovedLogsEvent{},
 		state.Logs(nil))
 
 out:
@@ -95,16 +96,25 @@ out:
 				self.filterMu.RUnlock()
 
 			case state.Logs:
-				self.filterMu.RLock()
-				for _, filter := range self.filters {
-					if filter.LogsCallback != nil {
-						msgs := filter.FilterLogs(event)
-						if len(msgs) > 0 {
-							filter.LogsCallback(msgs)
-						}
-					}
-				}
-				self.filterMu.RUnlock()
+				self.deliverLogs(event)
+
+			case core.RemovedLogsEvent:
+				self.deliverLogs(event.Logs)
+			}
+		}
+	}
+}
+
+// deliverLogs passes the logs matching each filter to its callback.
+func (self *FilterManager) deliverLogs(logs state.Logs) {
+	self.filterMu.RLock()
+	defer self.filterMu.RUnlock()
+
+	for _, filter := range self.filters {
+		if filter.LogsCallback != nil {
+			msgs := filter.FilterLogs(logs)
+			if len(msgs) > 0 {
+				filter.LogsCallback(msgs)
 			}
 		}
 	}
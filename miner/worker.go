@@ -429,10 +429,9 @@ func (self *worker) commitTransactions(transactions types.Transactions) {
 }
 
 func (self *worker) commitTransaction(tx *types.Transaction) error {
-	snap := self.current.state.Copy()
+	// ApplyTransaction reverts the state itself if the transaction is invalid
 	receipt, _, err := self.proc.ApplyTransaction(self.current.coinbase, self.current.state, self.current.block, tx, self.current.totalUsedGas, true)
 	if err != nil && (core.IsNonceErr(err) || state.IsGasLimitErr(err) || core.IsInvalidTxErr(err)) {
-		self.current.state.Set(snap)
 		return err
 	}
 
@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/xeth"
+)
+
+// AdminApi serves the node administration methods of the admin and miner
+// namespaces in addition to the methods of EthereumApi. It must only be
+// exposed to the owner of the node, as on the IPC endpoint.
+type AdminApi struct {
+	*EthereumApi
+	ethereum *eth.Ethereum
+}
+
+func NewAdminApi(ethereum *eth.Ethereum, pipe *xeth.XEth) *AdminApi {
+	api := &AdminApi{NewEthereumApi(pipe), ethereum}
+	api.RegisterName("admin", &adminService{ethereum})
+	api.RegisterName("miner", &minerService{ethereum})
+
+	return api
+}
+
+type adminService struct {
+	ethereum *eth.Ethereum
+}
+
+func (self *adminService) AddPeer(url string) (bool, error) {
+	if err := self.ethereum.AddPeer(url); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (self *adminService) Peers() []*eth.PeerInfo {
+	return self.ethereum.PeersInfo()
+}
+
+func (self *adminService) NodeInfo() *eth.NodeInfo {
+	return self.ethereum.NodeInfo()
+}
+
+func (self *adminService) NewAccount(passphrase string) (string, error) {
+	acct, err := self.ethereum.AccountManager().NewAccount(passphrase)
+	if err != nil {
+		return "", err
+	}
+	return common.ToHex(acct.Address), nil
+}
+
+// Unlock unlocks the account for duration seconds, until the node stops if
+// duration is omitted or zero.
+func (self *adminService) Unlock(address, passphrase string, duration *Number) (bool, error) {
+	var seconds int64
+	if duration != nil {
+		seconds = int64(*duration)
+	}
+	if seconds < 0 {
+		return false, NewValidationError("duration", "cannot be negative")
+	}
+	am := self.ethereum.AccountManager()
+	if err := am.TimedUnlock(common.FromHex(address), passphrase, time.Duration(seconds)*time.Second); err != nil {
+		return false, fmt.Errorf("unlock account failed: %v", err)
+	}
+	return true, nil
+}
+
+func (self *adminService) Verbosity(level Number) bool {
+	glog.SetV(int(level))
+	return true
+}
+
+func (self *adminService) Progress() string {
+	current, max := self.ethereum.Downloader().Stats()
+	return fmt.Sprintf("%d/%d", current, max)
+}
+
+func (self *adminService) StartRPC(address string, port Number, corsDomain *string) (bool, error) {
+	if port < 0 || port > 65535 {
+		return false, NewValidationError("port", "must be between 0 and 65535")
+	}
+	config := RpcConfig{
+		ListenAddress: address,
+		ListenPort:    uint(port),
+	}
+	if corsDomain != nil {
+		config.CorsDomain = *corsDomain
+	}
+	if err := Start(xeth.New(self.ethereum, nil), config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (self *adminService) StopRPC() bool {
+	return Stop() == nil
+}
+
+type minerService struct {
+	ethereum *eth.Ethereum
+}
+
+// Start starts mining. The number of threads is ignored.
+func (self *minerService) Start(threads *Number) (bool, error) {
+	if err := self.ethereum.StartMining(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (self *minerService) Stop() bool {
+	self.ethereum.StopMining()
+	return true
+}
+
+func (self *minerService) Hashrate() int64 {
+	return self.ethereum.Miner().HashRate()
+}
+
+func (self *minerService) SetExtra(data string) (bool, error) {
+	if len(data) > 1024 {
+		return false, NewValidationError("data", "cannot exceed 1024 bytes")
+	}
+	self.ethereum.Miner().SetExtra([]byte(data))
+	return true, nil
+}
+
+func (self *minerService) SetGasPrice(price Number) bool {
+	self.ethereum.Miner().SetGasPrice(big.NewInt(int64(price)))
+	return true
+}
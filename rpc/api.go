@@ -1,25 +1,37 @@
 package rpc
 
 import (
-	"encoding/json"
 	"math/big"
-	// "sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/cfg"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/logger"
-	"github.com/ethereum/go-ethereum/logger/glog"
 	"github.com/ethereum/go-ethereum/xeth"
 )
 
+// Spec at https://github.com/ethereum/wiki/wiki/JSON-RPC
+
+// EthereumApi serves the web3, net, eth, db, shh and debug namespaces.
 type EthereumApi struct {
+	*Server
 	eth *xeth.XEth
 }
 
 func NewEthereumApi(xeth *xeth.XEth) *EthereumApi {
 	api := &EthereumApi{
-		eth: xeth,
+		Server: NewServer(),
+		eth:    xeth,
 	}
+	api.RegisterName("web3", &web3Service{xeth})
+	api.RegisterName("net", &netService{xeth})
+	api.RegisterName("eth", &ethService{xeth})
+	api.RegisterName("db", &dbService{xeth})
+	api.RegisterName("shh", &shhService{xeth})
+	api.RegisterName("debug", &debugService{xeth})
 
 	return api
 }
@@ -28,546 +40,513 @@ func (api *EthereumApi) xeth() *xeth.XEth {
 	return api.eth
 }
 
-func (api *EthereumApi) xethAtStateNum(num int64) *xeth.XEth {
-	return api.xeth().AtStateNum(num)
-}
-
-func (api *EthereumApi) GetRequestReply(req *RpcRequest, reply *interface{}) error {
-	// Spec at https://github.com/ethereum/wiki/wiki/JSON-RPC
-	glog.V(logger.Debug).Infof("%s %s", req.Method, req.Params)
-
-	switch req.Method {
-	case "web3_sha3":
-		args := new(Sha3Args)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = common.ToHex(crypto.Sha3(common.FromHex(args.Data)))
-	case "web3_clientVersion":
-		*reply = api.xeth().ClientVersion()
-	case "net_version":
-		*reply = api.xeth().NetworkVersion()
-	case "net_listening":
-		*reply = api.xeth().IsListening()
-	case "net_peerCount":
-		*reply = newHexNum(api.xeth().PeerCount())
-	case "eth_protocolVersion":
-		*reply = api.xeth().EthVersion()
-	case "eth_coinbase":
-		*reply = newHexData(api.xeth().Coinbase())
-	case "eth_mining":
-		*reply = api.xeth().IsMining()
-	case "eth_gasPrice":
-		v := xeth.DefaultGasPrice()
-		*reply = newHexNum(v.Bytes())
-	case "eth_accounts":
-		*reply = api.xeth().Accounts()
-	case "eth_blockNumber":
-		v := api.xeth().CurrentBlock().Number()
-		*reply = newHexNum(v.Bytes())
-	case "eth_getBalance":
-		args := new(GetBalanceArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		*reply = api.xethAtStateNum(args.BlockNumber).BalanceAt(args.Address)
-		//v := api.xethAtStateNum(args.BlockNumber).State().SafeGet(args.Address).Balance()
-		//*reply = common.ToHex(v.Bytes())
-	case "eth_getStorage", "eth_storageAt":
-		args := new(GetStorageArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		*reply = api.xethAtStateNum(args.BlockNumber).State().SafeGet(args.Address).Storage()
-	case "eth_getStorageAt":
-		args := new(GetStorageAtArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		*reply = api.xethAtStateNum(args.BlockNumber).StorageAt(args.Address, args.Key)
-	case "eth_getTransactionCount":
-		args := new(GetTxCountArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		count := api.xethAtStateNum(args.BlockNumber).TxCountAt(args.Address)
-		*reply = newHexNum(big.NewInt(int64(count)).Bytes())
-	case "eth_getBlockTransactionCountByHash":
-		args := new(HashArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := NewBlockRes(api.xeth().EthBlockByHash(args.Hash), false)
-		if block == nil {
-			*reply = nil
-		} else {
-			*reply = newHexNum(big.NewInt(int64(len(block.Transactions))).Bytes())
-		}
-	case "eth_getBlockTransactionCountByNumber":
-		args := new(BlockNumArg)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := NewBlockRes(api.xeth().EthBlockByNumber(args.BlockNumber), false)
-		if block == nil {
-			*reply = nil
-			break
-		}
-
-		*reply = newHexNum(big.NewInt(int64(len(block.Transactions))).Bytes())
-	case "eth_getUncleCountByBlockHash":
-		args := new(HashArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByHash(args.Hash)
-		br := NewBlockRes(block, false)
-		if br == nil {
-			*reply = nil
-			break
-		}
-
-		*reply = newHexNum(big.NewInt(int64(len(br.Uncles))).Bytes())
-	case "eth_getUncleCountByBlockNumber":
-		args := new(BlockNumArg)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByNumber(args.BlockNumber)
-		br := NewBlockRes(block, false)
-		if br == nil {
-			*reply = nil
-			break
-		}
-
-		*reply = newHexNum(big.NewInt(int64(len(br.Uncles))).Bytes())
-
-	case "eth_getData", "eth_getCode":
-		args := new(GetDataArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		v := api.xethAtStateNum(args.BlockNumber).CodeAtBytes(args.Address)
-		*reply = newHexData(v)
-
-	case "eth_sign":
-		args := new(NewSigArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		v, err := api.xeth().Sign(args.From, args.Data, false)
-		if err != nil {
-			return err
-		}
-		*reply = v
-
-	case "eth_sendTransaction", "eth_transact":
-		args := new(NewTxArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		// nonce may be nil ("guess" mode)
-		var nonce string
-		if args.Nonce != nil {
-			nonce = args.Nonce.String()
-		}
-
-		v, err := api.xeth().Transact(args.From, args.To, nonce, args.Value.String(), args.Gas.String(), args.GasPrice.String(), args.Data)
-		if err != nil {
-			return err
-		}
-		*reply = v
-	case "eth_call":
-		args := new(CallArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		v, err := api.xethAtStateNum(args.BlockNumber).Call(args.From, args.To, args.Value.String(), args.Gas.String(), args.GasPrice.String(), args.Data)
-		if err != nil {
-			return err
-		}
-		// TODO unwrap the parent method's ToHex call
-		if v == "0x0" {
-			*reply = newHexData([]byte{})
-		} else {
-			*reply = newHexData(common.FromHex(v))
-		}
-	case "eth_flush":
-		return NewNotImplementedError(req.Method)
-	case "eth_getBlockByHash":
-		args := new(GetBlockByHashArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByHash(args.BlockHash)
-		br := NewBlockRes(block, args.IncludeTxs)
-
-		*reply = br
-	case "eth_getBlockByNumber":
-		args := new(GetBlockByNumberArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByNumber(args.BlockNumber)
-		br := NewBlockRes(block, args.IncludeTxs)
-
-		*reply = br
-	case "eth_getTransactionByHash":
-		args := new(HashArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		tx, bhash, bnum, txi := api.xeth().EthTransactionByHash(args.Hash)
-		if tx != nil {
-			v := NewTransactionRes(tx)
-			v.BlockHash = newHexData(bhash)
-			v.BlockNumber = newHexNum(bnum)
-			v.TxIndex = newHexNum(txi)
-			*reply = v
-		}
-	case "eth_getTransactionByBlockHashAndIndex":
-		args := new(HashIndexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByHash(args.Hash)
-		br := NewBlockRes(block, true)
-		if br == nil {
-			*reply = nil
-			break
-		}
-
-		if args.Index >= int64(len(br.Transactions)) || args.Index < 0 {
-			// return NewValidationError("Index", "does not exist")
-			*reply = nil
-		} else {
-			*reply = br.Transactions[args.Index]
-		}
-	case "eth_getTransactionByBlockNumberAndIndex":
-		args := new(BlockNumIndexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByNumber(args.BlockNumber)
-		v := NewBlockRes(block, true)
-		if v == nil {
-			*reply = nil
-			break
-		}
-
-		if args.Index >= int64(len(v.Transactions)) || args.Index < 0 {
-			// return NewValidationError("Index", "does not exist")
-			*reply = nil
-		} else {
-			*reply = v.Transactions[args.Index]
-		}
-	case "eth_getUncleByBlockHashAndIndex":
-		args := new(HashIndexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		br := NewBlockRes(api.xeth().EthBlockByHash(args.Hash), false)
-		if br == nil {
-			*reply = nil
-			return nil
-		}
-
-		if args.Index >= int64(len(br.Uncles)) || args.Index < 0 {
-			// return NewValidationError("Index", "does not exist")
-			*reply = nil
-		} else {
-			*reply = br.Uncles[args.Index]
-		}
-	case "eth_getUncleByBlockNumberAndIndex":
-		args := new(BlockNumIndexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		block := api.xeth().EthBlockByNumber(args.BlockNumber)
-		v := NewBlockRes(block, true)
-
-		if v == nil {
-			*reply = nil
-			return nil
-		}
-
-		if args.Index >= int64(len(v.Uncles)) || args.Index < 0 {
-			// return NewValidationError("Index", "does not exist")
-			*reply = nil
-		} else {
-			*reply = v.Uncles[args.Index]
-		}
-
-	case "eth_getCompilers":
-		var lang string
-		if solc, _ := api.xeth().Solc(); solc != nil {
-			lang = "Solidity"
-		}
-		c := []string{lang}
-		*reply = c
-
-	case "eth_compileLLL", "eth_compileSerpent":
-		return NewNotImplementedError(req.Method)
-
-	case "eth_compileSolidity":
-
-		solc, _ := api.xeth().Solc()
-		if solc == nil {
-			return NewNotImplementedError(req.Method)
-		}
-
-		args := new(SourceArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		contract, err := solc.Compile(args.Source)
-		if err != nil {
-			return err
-		}
-		*reply = contract
-
-	case "eth_newFilter":
-		args := new(BlockFilterArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		id := api.xeth().NewLogFilter(args.Earliest, args.Latest, args.Skip, args.Max, args.Address, args.Topics)
-		*reply = newHexNum(big.NewInt(int64(id)).Bytes())
-
-	case "eth_newBlockFilter":
-		*reply = newHexNum(api.xeth().NewBlockFilter())
-	case "eth_newPendingTransactionFilter":
-		*reply = newHexNum(api.xeth().NewTransactionFilter())
-	case "eth_uninstallFilter":
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().UninstallFilter(args.Id)
-	case "eth_getFilterChanges":
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		switch api.xeth().GetFilterType(args.Id) {
-		case xeth.BlockFilterTy:
-			*reply = NewHashesRes(api.xeth().BlockFilterChanged(args.Id))
-		case xeth.TransactionFilterTy:
-			*reply = NewHashesRes(api.xeth().TransactionFilterChanged(args.Id))
-		case xeth.LogFilterTy:
-			*reply = NewLogsRes(api.xeth().LogFilterChanged(args.Id))
-		default:
-			*reply = []string{} // reply empty string slice
-		}
-	case "eth_getFilterLogs":
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = NewLogsRes(api.xeth().Logs(args.Id))
-	case "eth_getLogs":
-		args := new(BlockFilterArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = NewLogsRes(api.xeth().AllLogs(args.Earliest, args.Latest, args.Skip, args.Max, args.Address, args.Topics))
-	case "eth_getWork":
-		api.xeth().SetMining(true)
-		*reply = api.xeth().RemoteMining().GetWork()
-	case "eth_submitWork":
-		args := new(SubmitWorkArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().RemoteMining().SubmitWork(args.Nonce, common.HexToHash(args.Digest), common.HexToHash(args.Header))
-	case "db_putString":
-		args := new(DbArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		if err := args.requirements(); err != nil {
-			return err
-		}
-
-		api.xeth().DbPut([]byte(args.Database+args.Key), args.Value)
-
-		*reply = true
-	case "db_getString":
-		args := new(DbArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		if err := args.requirements(); err != nil {
-			return err
-		}
-
-		res, _ := api.xeth().DbGet([]byte(args.Database + args.Key))
-		*reply = string(res)
-	case "db_putHex":
-		args := new(DbHexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		if err := args.requirements(); err != nil {
-			return err
-		}
-
-		api.xeth().DbPut([]byte(args.Database+args.Key), args.Value)
-		*reply = true
-	case "db_getHex":
-		args := new(DbHexArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-
-		if err := args.requirements(); err != nil {
-			return err
-		}
-
-		res, _ := api.xeth().DbGet([]byte(args.Database + args.Key))
-		*reply = newHexData(res)
-
-	case "shh_version":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Retrieves the currently running whisper protocol version
-		*reply = api.xeth().WhisperVersion()
-
-	case "shh_post":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Injects a new message into the whisper network
-		args := new(WhisperMessageArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		err := api.xeth().Whisper().Post(args.Payload, args.To, args.From, args.Topics, args.Priority, args.Ttl)
-		if err != nil {
-			return err
-		}
-		*reply = true
-
-	case "shh_newIdentity":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Creates a new whisper identity to use for sending/receiving messages
-		*reply = api.xeth().Whisper().NewIdentity()
-
-	case "shh_hasIdentity":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Checks if an identity if owned or not
-		args := new(WhisperIdentityArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().Whisper().HasIdentity(args.Identity)
-
-	case "shh_newFilter":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Create a new filter to watch and match messages with
-		args := new(WhisperFilterArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		id := api.xeth().NewWhisperFilter(args.To, args.From, args.Topics)
-		*reply = newHexNum(big.NewInt(int64(id)).Bytes())
-
-	case "shh_uninstallFilter":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Remove an existing filter watching messages
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().UninstallWhisperFilter(args.Id)
-
-	case "shh_getFilterChanges":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Retrieve all the new messages arrived since the last request
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().WhisperMessagesChanged(args.Id)
-
-	case "shh_getMessages":
-		// Short circuit if whisper is not running
-		if api.xeth().Whisper() == nil {
-			return NewNotAvailableError(req.Method, "whisper offline")
-		}
-		// Retrieve all the cached messages matching a specific, existing filter
-		args := new(FilterIdArgs)
-		if err := json.Unmarshal(req.Params, &args); err != nil {
-			return err
-		}
-		*reply = api.xeth().WhisperMessages(args.Id)
-
-	case "eth_hashrate":
-		*reply = newHexNum(api.xeth().HashRate())
-
-	// case "eth_register":
-	// 	// Placeholder for actual type
-	// 	args := new(HashIndexArgs)
-	// 	if err := json.Unmarshal(req.Params, &args); err != nil {
-	// 		return err
-	// 	}
-	// 	*reply = api.xeth().Register(args.Hash)
-	// case "eth_unregister":
-	// 	args := new(HashIndexArgs)
-	// 	if err := json.Unmarshal(req.Params, &args); err != nil {
-	// 		return err
-	// 	}
-	// 	*reply = api.xeth().Unregister(args.Hash)
-	// case "eth_watchTx":
-	// 	args := new(HashIndexArgs)
-	// 	if err := json.Unmarshal(req.Params, &args); err != nil {
-	// 		return err
-	// 	}
-	// 	*reply = api.xeth().PullWatchTx(args.Hash)
-	default:
-		return NewNotImplementedError(req.Method)
-	}
-
-	glog.V(logger.Detail).Infof("Reply: %T %s\n", reply, reply)
-	return nil
+type web3Service struct {
+	pipe *xeth.XEth
+}
+
+func (self *web3Service) ClientVersion() string {
+	return self.pipe.ClientVersion()
+}
+
+func (self *web3Service) Sha3(data string) string {
+	return common.ToHex(crypto.Sha3(common.FromHex(data)))
+}
+
+type netService struct {
+	pipe *xeth.XEth
+}
+
+func (self *netService) Version() string {
+	return self.pipe.NetworkVersion()
+}
+
+func (self *netService) Listening() bool {
+	return self.pipe.IsListening()
+}
+
+func (self *netService) PeerCount() *hexnum {
+	return newHexNum(self.pipe.PeerCount())
+}
+
+type ethService struct {
+	pipe *xeth.XEth
+}
+
+func (self *ethService) ProtocolVersion() string {
+	return self.pipe.EthVersion()
+}
+
+func (self *ethService) Coinbase() *hexdata {
+	return newHexData(self.pipe.Coinbase())
+}
+
+func (self *ethService) Mining() bool {
+	return self.pipe.IsMining()
+}
+
+func (self *ethService) Hashrate() *hexnum {
+	return newHexNum(self.pipe.HashRate())
+}
+
+func (self *ethService) GasPrice() *hexnum {
+	return newHexNum(xeth.DefaultGasPrice().Bytes())
+}
+
+func (self *ethService) Accounts() []string {
+	return self.pipe.Accounts()
+}
+
+func (self *ethService) BlockNumber() *hexnum {
+	return newHexNum(self.pipe.CurrentBlock().Number().Bytes())
+}
+
+func (self *ethService) GetBalance(address string, block *BlockNumber) string {
+	return self.pipe.AtStateNum(blockNumber(block)).BalanceAt(address)
+}
+
+func (self *ethService) GetStorage(address string, block *BlockNumber) map[string]string {
+	return self.pipe.AtStateNum(blockNumber(block)).State().SafeGet(address).Storage()
+}
+
+// StorageAt is the deprecated name of GetStorage.
+func (self *ethService) StorageAt(address string, block *BlockNumber) map[string]string {
+	return self.GetStorage(address, block)
+}
+
+func (self *ethService) GetStorageAt(address, key string, block *BlockNumber) string {
+	return self.pipe.AtStateNum(blockNumber(block)).StorageAt(address, key)
+}
+
+func (self *ethService) GetTransactionCount(address string, block *BlockNumber) *hexnum {
+	count := self.pipe.AtStateNum(blockNumber(block)).TxCountAt(address)
+	return newHexNum(big.NewInt(int64(count)).Bytes())
+}
+
+func (self *ethService) GetBlockTransactionCountByHash(hash string) *hexnum {
+	block := NewBlockRes(self.pipe.EthBlockByHash(hash), false)
+	if block == nil {
+		return nil
+	}
+	return newHexNum(big.NewInt(int64(len(block.Transactions))).Bytes())
+}
+
+func (self *ethService) GetBlockTransactionCountByNumber(number BlockNumber) *hexnum {
+	block := NewBlockRes(self.pipe.EthBlockByNumber(int64(number)), false)
+	if block == nil {
+		return nil
+	}
+	return newHexNum(big.NewInt(int64(len(block.Transactions))).Bytes())
+}
+
+func (self *ethService) GetUncleCountByBlockHash(hash string) *hexnum {
+	block := NewBlockRes(self.pipe.EthBlockByHash(hash), false)
+	if block == nil {
+		return nil
+	}
+	return newHexNum(big.NewInt(int64(len(block.Uncles))).Bytes())
+}
+
+func (self *ethService) GetUncleCountByBlockNumber(number BlockNumber) *hexnum {
+	block := NewBlockRes(self.pipe.EthBlockByNumber(int64(number)), false)
+	if block == nil {
+		return nil
+	}
+	return newHexNum(big.NewInt(int64(len(block.Uncles))).Bytes())
+}
+
+func (self *ethService) GetCode(address string, block *BlockNumber) *hexdata {
+	return newHexData(self.pipe.AtStateNum(blockNumber(block)).CodeAtBytes(address))
+}
+
+// GetData is the deprecated name of GetCode.
+func (self *ethService) GetData(address string, block *BlockNumber) *hexdata {
+	return self.GetCode(address, block)
+}
+
+func (self *ethService) Sign(args NewSigArgs) (string, error) {
+	return self.pipe.Sign(args.From, args.Data, false)
+}
+
+func (self *ethService) SendTransaction(args NewTxArgs) (string, error) {
+	// nonce may be nil ("guess" mode)
+	var nonce string
+	if args.Nonce != nil {
+		nonce = args.Nonce.String()
+	}
+	return self.pipe.Transact(args.From, args.To, nonce, args.Value.String(), args.Gas.String(), args.GasPrice.String(), args.Data)
+}
+
+// Transact is the deprecated name of SendTransaction.
+func (self *ethService) Transact(args NewTxArgs) (string, error) {
+	return self.SendTransaction(args)
+}
+
+func (self *ethService) Call(args CallArgs, block *BlockNumber) (*hexdata, error) {
+	v, err := self.pipe.AtStateNum(blockNumber(block)).Call(args.From, args.To, args.Value.String(), args.Gas.String(), args.GasPrice.String(), args.Data)
+	if err != nil {
+		return nil, err
+	}
+	// TODO unwrap the parent method's ToHex call
+	if v == "0x0" {
+		return newHexData([]byte{}), nil
+	}
+	return newHexData(common.FromHex(v)), nil
+}
+
+func (self *ethService) Flush() error {
+	return NewNotImplementedError("eth_flush")
+}
+
+func (self *ethService) GetBlockByHash(hash string, includeTxs bool) *BlockRes {
+	return NewBlockRes(self.pipe.EthBlockByHash(hash), includeTxs)
+}
+
+func (self *ethService) GetBlockByNumber(number BlockNumber, includeTxs bool) *BlockRes {
+	return NewBlockRes(self.pipe.EthBlockByNumber(int64(number)), includeTxs)
+}
+
+func (self *ethService) GetTransactionByHash(hash string) *TransactionRes {
+	tx, bhash, bnum, txi := self.pipe.EthTransactionByHash(hash)
+	if tx == nil {
+		return nil
+	}
+	v := NewTransactionRes(tx)
+	v.BlockHash = newHexData(bhash)
+	v.BlockNumber = newHexNum(bnum)
+	v.TxIndex = newHexNum(txi)
+	return v
+}
+
+func (self *ethService) GetTransactionsByAddress(address string, offset, limit *Number) (*AddressTxsRes, error) {
+	var skip, max int64 = 0, 100
+	if offset != nil {
+		skip = int64(*offset)
+	}
+	if limit != nil {
+		max = int64(*limit)
+	}
+	if skip < 0 {
+		return nil, NewValidationError("offset", "cannot be negative")
+	}
+	if max < 1 || max > 1000 {
+		return nil, NewValidationError("limit", "must be between 1 and 1000")
+	}
+
+	txs, blocks, total, err := self.pipe.TransactionsByAddress(address, uint64(skip), uint64(max))
+	if err != nil {
+		return nil, NewNotAvailableError("eth_getTransactionsByAddress", err.Error())
+	}
+	return NewAddressTxsRes(txs, blocks, total), nil
+}
+
+func (self *ethService) GetTransactionByBlockHashAndIndex(hash string, index Number) *TransactionRes {
+	return blockTransaction(NewBlockRes(self.pipe.EthBlockByHash(hash), true), int64(index))
+}
+
+func (self *ethService) GetTransactionByBlockNumberAndIndex(number BlockNumber, index Number) *TransactionRes {
+	return blockTransaction(NewBlockRes(self.pipe.EthBlockByNumber(int64(number)), true), int64(index))
+}
+
+func (self *ethService) GetUncleByBlockHashAndIndex(hash string, index Number) *UncleRes {
+	return blockUncle(NewBlockRes(self.pipe.EthBlockByHash(hash), false), int64(index))
+}
+
+func (self *ethService) GetUncleByBlockNumberAndIndex(number BlockNumber, index Number) *UncleRes {
+	return blockUncle(NewBlockRes(self.pipe.EthBlockByNumber(int64(number)), true), int64(index))
+}
+
+// blockTransaction returns the transaction at index of block, nil if there is
+// no such block or transaction.
+func blockTransaction(block *BlockRes, index int64) *TransactionRes {
+	if block == nil || index < 0 || index >= int64(len(block.Transactions)) {
+		return nil
+	}
+	return block.Transactions[index]
+}
+
+func blockUncle(block *BlockRes, index int64) *UncleRes {
+	if block == nil || index < 0 || index >= int64(len(block.Uncles)) {
+		return nil
+	}
+	return block.Uncles[index]
+}
+
+func (self *ethService) GetCompilers() []string {
+	var lang string
+	if solc, _ := self.pipe.Solc(); solc != nil {
+		lang = "Solidity"
+	}
+	return []string{lang}
+}
+
+func (self *ethService) CompileLLL(source string) error {
+	return NewNotImplementedError("eth_compileLLL")
+}
+
+func (self *ethService) CompileSerpent(source string) error {
+	return NewNotImplementedError("eth_compileSerpent")
+}
+
+func (self *ethService) CompileSolidity(source string) (*compiler.Contract, error) {
+	solc, _ := self.pipe.Solc()
+	if solc == nil {
+		return nil, NewNotImplementedError("eth_compileSolidity")
+	}
+	return solc.Compile(source)
+}
+
+func (self *ethService) NewFilter(args BlockFilterArgs) *hexnum {
+	id := self.pipe.NewLogFilter(args.Earliest, args.Latest, args.Skip, args.Max, args.Address, args.Topics)
+	return newHexNum(big.NewInt(int64(id)).Bytes())
+}
+
+func (self *ethService) NewBlockFilter() *hexnum {
+	return newHexNum(self.pipe.NewBlockFilter())
+}
+
+func (self *ethService) NewPendingTransactionFilter() *hexnum {
+	return newHexNum(self.pipe.NewTransactionFilter())
+}
+
+func (self *ethService) UninstallFilter(id Number) bool {
+	return self.pipe.UninstallFilter(int(id))
+}
+
+func (self *ethService) GetFilterChanges(id Number) interface{} {
+	switch self.pipe.GetFilterType(int(id)) {
+	case xeth.BlockFilterTy:
+		return NewHashesRes(self.pipe.BlockFilterChanged(int(id)))
+	case xeth.TransactionFilterTy:
+		return NewHashesRes(self.pipe.TransactionFilterChanged(int(id)))
+	case xeth.LogFilterTy:
+		return NewLogsRes(self.pipe.LogFilterChanged(int(id)))
+	}
+	return []string{} // reply empty string slice
+}
+
+func (self *ethService) GetFilterLogs(id Number) []LogRes {
+	return NewLogsRes(self.pipe.Logs(int(id)))
+}
+
+func (self *ethService) GetLogs(args BlockFilterArgs) []LogRes {
+	return NewLogsRes(self.pipe.AllLogs(args.Earliest, args.Latest, args.Skip, args.Max, args.Address, args.Topics))
+}
+
+func (self *ethService) GetWork() [3]string {
+	self.pipe.SetMining(true)
+	return self.pipe.RemoteMining().GetWork()
+}
+
+func (self *ethService) SubmitWork(nonce, header, digest string) bool {
+	return self.pipe.RemoteMining().SubmitWork(common.String2Big(nonce).Uint64(), common.HexToHash(digest), common.HexToHash(header))
+}
+
+type dbService struct {
+	pipe *xeth.XEth
+}
+
+func dbKey(database, key string) ([]byte, error) {
+	if len(database) == 0 {
+		return nil, NewValidationError("Database", "cannot be blank")
+	}
+	if len(key) == 0 {
+		return nil, NewValidationError("Key", "cannot be blank")
+	}
+	return []byte(database + key), nil
+}
+
+func (self *dbService) PutString(database, key, value string) (bool, error) {
+	k, err := dbKey(database, key)
+	if err != nil {
+		return false, err
+	}
+	self.pipe.DbPut(k, []byte(value))
+	return true, nil
+}
+
+func (self *dbService) GetString(database, key string) (string, error) {
+	k, err := dbKey(database, key)
+	if err != nil {
+		return "", err
+	}
+	res, _ := self.pipe.DbGet(k)
+	return string(res), nil
+}
+
+func (self *dbService) PutHex(database, key, value string) (bool, error) {
+	k, err := dbKey(database, key)
+	if err != nil {
+		return false, err
+	}
+	self.pipe.DbPut(k, common.FromHex(value))
+	return true, nil
+}
+
+func (self *dbService) GetHex(database, key string) (*hexdata, error) {
+	k, err := dbKey(database, key)
+	if err != nil {
+		return nil, err
+	}
+	res, _ := self.pipe.DbGet(k)
+	return newHexData(res), nil
+}
+
+// shhService serves the whisper methods, which are unavailable if whisper
+// isn't running.
+type shhService struct {
+	pipe *xeth.XEth
+}
+
+func (self *shhService) whisper(method string) (*xeth.Whisper, error) {
+	shh := self.pipe.Whisper()
+	if shh == nil {
+		return nil, NewNotAvailableError(method, "whisper offline")
+	}
+	return shh, nil
+}
+
+func (self *shhService) Version() (string, error) {
+	if _, err := self.whisper("shh_version"); err != nil {
+		return "", err
+	}
+	return self.pipe.WhisperVersion(), nil
+}
+
+func (self *shhService) Post(args WhisperMessageArgs) (bool, error) {
+	shh, err := self.whisper("shh_post")
+	if err != nil {
+		return false, err
+	}
+	if err := shh.Post(args.Payload, args.To, args.From, args.Topics, args.Priority, args.Ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (self *shhService) NewIdentity() (string, error) {
+	shh, err := self.whisper("shh_newIdentity")
+	if err != nil {
+		return "", err
+	}
+	return shh.NewIdentity(), nil
+}
+
+func (self *shhService) HasIdentity(identity string) (bool, error) {
+	shh, err := self.whisper("shh_hasIdentity")
+	if err != nil {
+		return false, err
+	}
+	return shh.HasIdentity(identity), nil
+}
+
+func (self *shhService) NewFilter(args WhisperFilterArgs) (*hexnum, error) {
+	if _, err := self.whisper("shh_newFilter"); err != nil {
+		return nil, err
+	}
+	id := self.pipe.NewWhisperFilter(args.To, args.From, args.Topics)
+	return newHexNum(big.NewInt(int64(id)).Bytes()), nil
+}
+
+func (self *shhService) UninstallFilter(id Number) (bool, error) {
+	if _, err := self.whisper("shh_uninstallFilter"); err != nil {
+		return false, err
+	}
+	return self.pipe.UninstallWhisperFilter(int(id)), nil
+}
+
+func (self *shhService) GetFilterChanges(id Number) ([]xeth.WhisperMessage, error) {
+	if _, err := self.whisper("shh_getFilterChanges"); err != nil {
+		return nil, err
+	}
+	return self.pipe.WhisperMessagesChanged(int(id)), nil
+}
+
+func (self *shhService) GetMessages(id Number) ([]xeth.WhisperMessage, error) {
+	if _, err := self.whisper("shh_getMessages"); err != nil {
+		return nil, err
+	}
+	return self.pipe.WhisperMessages(int(id)), nil
+}
+
+type debugService struct {
+	pipe *xeth.XEth
+}
+
+func (self *debugService) block(ref BlockRef) (*types.Block, error) {
+	var block *types.Block
+	if len(ref.Hash) > 0 {
+		block = self.pipe.EthBlockByHash(ref.Hash)
+	} else {
+		block = self.pipe.EthBlockByNumber(ref.Number)
+	}
+	if block == nil {
+		return nil, NewValidationError("block", "not found")
+	}
+	return block, nil
+}
+
+func (self *debugService) StateDiff(from, to BlockRef) (state.WorldDiff, error) {
+	fromBlock, err := self.block(from)
+	if err != nil {
+		return state.WorldDiff{}, err
+	}
+	toBlock, err := self.block(to)
+	if err != nil {
+		return state.WorldDiff{}, err
+	}
+	return self.pipe.StateDiff(fromBlock, toBlock), nil
+}
+
+func (self *debugService) StorageRangeAt(ref BlockRef, address, startKey string, limit Number) (state.StorageRange, error) {
+	if limit < 1 || limit > 1024 {
+		return state.StorageRange{}, NewValidationError("limit", "must be between 1 and 1024")
+	}
+	block, err := self.block(ref)
+	if err != nil {
+		return state.StorageRange{}, err
+	}
+	return self.pipe.StorageRangeAt(block, address, common.FromHex(startKey), int(limit)), nil
+}
+
+func (self *debugService) ProfileTransaction(hash string) (*core.ProfileReport, error) {
+	return self.pipe.ProfileTransaction(hash)
+}
+
+func (self *debugService) ReplayBlock(hash string) (*core.ReplayReport, error) {
+	return self.pipe.ReplayBlock(hash)
+}
+
+func (self *debugService) StorageStats() core.StorageStats {
+	return self.pipe.StorageStats()
+}
+
+func (self *debugService) GetBadBlocks() []BadBlockRes {
+	return NewBadBlocksRes(self.pipe.BadBlocks())
+}
+
+func (self *debugService) ChainForks(depth *Number) ([]*core.ForkBlock, error) {
+	var d int64 = 16
+	if depth != nil {
+		d = int64(*depth)
+	}
+	if d < 0 || d > 4096 {
+		return nil, NewValidationError("depth", "must be between 0 and 4096")
+	}
+	return self.pipe.ChainForks(uint64(d)), nil
+}
+
+func (self *debugService) BlocksAtHeight(number BlockNumber) []*core.ForkBlock {
+	return self.pipe.BlocksAt(int64(number))
+}
+
+func (self *debugService) CommonAncestor(a, b string) *BlockRes {
+	block := self.pipe.CommonAncestor(a, b)
+	if block == nil {
+		return nil
+	}
+	return NewBlockRes(block, false)
+}
+
+func (self *debugService) GetContractCFG(address string, block *BlockNumber) *cfg.Graph {
+	return self.pipe.AtStateNum(blockNumber(block)).ContractCFG(address)
 }
@@ -8,6 +8,7 @@ import (
 	// "fmt"
 	"io/ioutil"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common/compiler"
 	"github.com/ethereum/go-ethereum/xeth"
@@ -17,7 +18,7 @@ func TestWeb3Sha3(t *testing.T) {
 	jsonstr := `{"jsonrpc":"2.0","method":"web3_sha3","params":["0x68656c6c6f20776f726c64"],"id":64}`
 	expected := "0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad"
 
-	api := &EthereumApi{}
+	api := NewEthereumApi(nil)
 
 	var req RpcRequest
 	json.Unmarshal([]byte(jsonstr), &req)
@@ -30,6 +31,145 @@ func TestWeb3Sha3(t *testing.T) {
 	}
 }
 
+func TestApiMethods(t *testing.T) {
+	expected := []string{
+		"web3_sha3", "web3_clientVersion", "net_version", "net_listening", "net_peerCount",
+		"eth_protocolVersion", "eth_coinbase", "eth_mining", "eth_hashrate", "eth_gasPrice",
+		"eth_accounts", "eth_blockNumber", "eth_getBalance", "eth_getStorage", "eth_storageAt",
+		"eth_getStorageAt", "eth_getTransactionCount", "eth_getBlockTransactionCountByHash",
+		"eth_getBlockTransactionCountByNumber", "eth_getUncleCountByBlockHash",
+		"eth_getUncleCountByBlockNumber", "eth_getData", "eth_getCode", "eth_sign",
+		"eth_sendTransaction", "eth_transact", "eth_call", "eth_flush", "eth_getBlockByHash",
+		"eth_getBlockByNumber", "eth_getTransactionByHash", "eth_getTransactionsByAddress",
+		"eth_getTransactionByBlockHashAndIndex", "eth_getTransactionByBlockNumberAndIndex",
+		"eth_getUncleByBlockHashAndIndex", "eth_getUncleByBlockNumberAndIndex",
+		"eth_getCompilers", "eth_compileLLL", "eth_compileSerpent", "eth_compileSolidity",
+		"eth_newFilter", "eth_newBlockFilter", "eth_newPendingTransactionFilter",
+		"eth_uninstallFilter", "eth_getFilterChanges", "eth_getFilterLogs", "eth_getLogs",
+		"eth_getWork", "eth_submitWork", "db_putString", "db_getString", "db_putHex", "db_getHex",
+		"shh_version", "shh_post", "shh_newIdentity", "shh_hasIdentity", "shh_newFilter",
+		"shh_uninstallFilter", "shh_getFilterChanges", "shh_getMessages",
+		"debug_stateDiff", "debug_storageRangeAt", "debug_profileTransaction", "debug_replayBlock",
+		"debug_storageStats", "debug_getBadBlocks", "debug_chainForks", "debug_blocksAtHeight", "debug_commonAncestor",
+		"debug_getContractCFG", "rpc_modules",
+	}
+	admin := []string{
+		"admin_addPeer", "admin_peers", "admin_nodeInfo", "admin_newAccount", "admin_unlock",
+		"admin_verbosity", "admin_progress", "admin_startRPC", "admin_stopRPC",
+		"miner_start", "miner_stop", "miner_hashrate", "miner_setExtra", "miner_setGasPrice",
+	}
+
+	check := func(methods []string, expected []string, unexpected []string) {
+		served := make(map[string]bool)
+		for _, method := range methods {
+			served[method] = true
+		}
+		for _, method := range expected {
+			if !served[method] {
+				t.Errorf("%s not served", method)
+			}
+		}
+		for _, method := range unexpected {
+			if served[method] {
+				t.Errorf("%s served without admin access", method)
+			}
+		}
+	}
+	check(NewEthereumApi(nil).Methods(), expected, admin)
+	check(NewAdminApi(nil, nil).Methods(), append(expected, admin...), nil)
+}
+
+func TestApiParamErrors(t *testing.T) {
+	const address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
+	tests := []struct {
+		method, params string
+		expect         func(error) string
+	}{
+		{"web3_sha3", `{}`, ExpectDecodeParamError},
+		{"web3_sha3", `[]`, ExpectInsufficientParamsError},
+		{"web3_sha3", `[4]`, ExpectInvalidTypeError},
+		{"eth_getBalance", `6`, ExpectDecodeParamError},
+		{"eth_getBalance", `[]`, ExpectInsufficientParamsError},
+		{"eth_getBalance", `[3]`, ExpectInvalidTypeError},
+		{"eth_getBalance", `["` + address + `", false]`, ExpectInvalidTypeError},
+		{"eth_getBalance", `["` + address + `", "foo"]`, ExpectInvalidTypeError},
+		{"eth_getStorage", `[]`, ExpectInsufficientParamsError},
+		{"eth_getStorage", `["` + address + `", true]`, ExpectInvalidTypeError},
+		{"eth_getStorageAt", `["` + address + `"]`, ExpectInsufficientParamsError},
+		{"eth_getStorageAt", `["` + address + `", 0]`, ExpectInvalidTypeError},
+		{"eth_getStorageAt", `["` + address + `", "0x0", false]`, ExpectInvalidTypeError},
+		{"eth_getTransactionCount", `[false]`, ExpectInvalidTypeError},
+		{"eth_getTransactionCount", `["` + address + `", {}]`, ExpectInvalidTypeError},
+		{"eth_getCode", `{}`, ExpectDecodeParamError},
+		{"eth_getCode", `["` + address + `", false]`, ExpectInvalidTypeError},
+		{"eth_getTransactionsByAddress", `["` + address + `", true]`, ExpectInvalidTypeError},
+		{"eth_getTransactionsByAddress", `["` + address + `", -1]`, ExpectValidationError},
+		{"eth_getTransactionsByAddress", `["` + address + `", 0, 0]`, ExpectValidationError},
+		{"eth_getTransactionsByAddress", `["` + address + `", 0, 1001]`, ExpectValidationError},
+		{"eth_getBlockByHash", `[]`, ExpectInsufficientParamsError},
+		{"eth_getBlockByHash", `["0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"]`, ExpectInsufficientParamsError},
+		{"eth_getBlockByHash", `[8, true]`, ExpectInvalidTypeError},
+		{"eth_getBlockByHash", `["0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b", "true"]`, ExpectInvalidTypeError},
+		{"eth_getBlockByNumber", `["0x1"]`, ExpectInsufficientParamsError},
+		{"eth_getBlockByNumber", `[true, true]`, ExpectInvalidTypeError},
+		{"eth_getBlockByNumber", `[{}, true]`, ExpectInvalidTypeError},
+		{"eth_getBlockTransactionCountByNumber", `[]`, ExpectInsufficientParamsError},
+		{"eth_getBlockTransactionCountByNumber", `[true]`, ExpectInvalidTypeError},
+		{"eth_getTransactionByBlockHashAndIndex", `[1, 1]`, ExpectInvalidTypeError},
+		{"eth_getTransactionByBlockHashAndIndex", `["0x1", false]`, ExpectInvalidTypeError},
+		{"eth_getTransactionByBlockNumberAndIndex", `["0x1"]`, ExpectInsufficientParamsError},
+		{"eth_getTransactionByBlockNumberAndIndex", `["foo", 1]`, ExpectInvalidTypeError},
+		{"eth_getUncleByBlockNumberAndIndex", `["0x1", {}]`, ExpectInvalidTypeError},
+		{"eth_sendTransaction", `[]`, ExpectInsufficientParamsError},
+		{"eth_sendTransaction", `[{"to": "` + address + `"}]`, ExpectValidationError},
+		{"eth_sign", `[{"from": "` + address + `"}]`, ExpectValidationError},
+		{"eth_call", `[{"from": "` + address + `"}]`, ExpectValidationError},
+		{"eth_call", `[{"to": "` + address + `"}, false]`, ExpectInvalidTypeError},
+		{"eth_newFilter", `[{"fromBlock": true}]`, ExpectInvalidTypeError},
+		{"eth_getLogs", `[]`, ExpectInsufficientParamsError},
+		{"eth_uninstallFilter", `[true]`, ExpectInvalidTypeError},
+		{"eth_getFilterChanges", `[]`, ExpectInsufficientParamsError},
+		{"eth_submitWork", `["0x1"]`, ExpectInsufficientParamsError},
+		{"eth_submitWork", `[1, "0x2", "0x3"]`, ExpectInvalidTypeError},
+		{"eth_compileSolidity", `[true]`, ExpectInvalidTypeError},
+		{"db_putString", `["db"]`, ExpectInsufficientParamsError},
+		{"db_putString", `[1, "key", "value"]`, ExpectInvalidTypeError},
+		{"db_putString", `["", "key", "value"]`, ExpectValidationError},
+		{"db_putString", `["db", "", "value"]`, ExpectValidationError},
+		{"db_getString", `["db", ""]`, ExpectValidationError},
+		{"db_putHex", `["db", "key", 5]`, ExpectInvalidTypeError},
+		{"db_getHex", `["", "key"]`, ExpectValidationError},
+		{"shh_post", `[{"ttl": true}]`, ExpectInvalidTypeError},
+		{"shh_hasIdentity", `[5]`, ExpectInvalidTypeError},
+		{"shh_newFilter", `[{"to": false}]`, ExpectInvalidTypeError},
+		{"shh_getMessages", `[]`, ExpectInsufficientParamsError},
+		{"debug_stateDiff", `["0x1"]`, ExpectInsufficientParamsError},
+		{"debug_stateDiff", `["0x1", true]`, ExpectInvalidTypeError},
+		{"debug_storageRangeAt", `["0x1", "` + address + `", "0x"]`, ExpectInsufficientParamsError},
+		{"debug_storageRangeAt", `["0x1", "` + address + `", "0x", 0]`, ExpectValidationError},
+		{"debug_storageRangeAt", `["0x1", "` + address + `", "0x", 1025]`, ExpectValidationError},
+		{"debug_chainForks", `[-1]`, ExpectValidationError},
+		{"debug_chainForks", `[4097]`, ExpectValidationError},
+		{"debug_chainForks", `[true]`, ExpectInvalidTypeError},
+		{"debug_commonAncestor", `["0x1"]`, ExpectInsufficientParamsError},
+		{"admin_unlock", `["` + address + `"]`, ExpectInsufficientParamsError},
+		{"admin_unlock", `["` + address + `", "secret", -1]`, ExpectValidationError},
+		{"admin_verbosity", `[true]`, ExpectInvalidTypeError},
+		{"admin_startRPC", `["127.0.0.1", 70000]`, ExpectValidationError},
+		{"miner_setExtra", `["` + strings.Repeat("x", 1025) + `"]`, ExpectValidationError},
+		{"miner_setGasPrice", `[]`, ExpectInsufficientParamsError},
+	}
+
+	api := NewAdminApi(nil, nil)
+	for _, test := range tests {
+		req := &RpcRequest{Id: 1, Jsonrpc: jsonrpcver, Method: test.method, Params: json.RawMessage(test.params)}
+		var reply interface{}
+		if str := test.expect(api.GetRequestReply(req, &reply)); len(str) > 0 {
+			t.Errorf("%s %s: %s", test.method, test.params, str)
+		}
+	}
+}
+
 func TestCompileSolidity(t *testing.T) {
 
 	solc, err := compiler.New("")
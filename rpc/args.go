@@ -53,6 +53,22 @@ func blockHeight(raw interface{}, number *int64) error {
 	return nil
 }
 
+// BlockRef identifies a block either by hash or by number. Hash is empty if
+// the block was given by number.
+type BlockRef struct {
+	Hash   string
+	Number int64
+}
+
+func blockRef(raw interface{}, ref *BlockRef) error {
+	if str, ok := raw.(string); ok && common.HasHexPrefix(str) && len(str) == 66 {
+		ref.Hash = str
+		return nil
+	}
+
+	return blockHeight(raw, &ref.Number)
+}
+
 func numString(raw interface{}) (*big.Int, error) {
 	var number *big.Int
 	// Parse as integer
@@ -72,6 +88,52 @@ func numString(raw interface{}) (*big.Int, error) {
 	return nil, NewInvalidTypeError("", "not a number or string")
 }
 
+// BlockNumber is a block parameter given as number, hex string or one of
+// "earliest", "latest" and "pending".
+type BlockNumber int64
+
+func (self *BlockNumber) UnmarshalJSON(b []byte) error {
+	var number int64
+	if err := blockHeightFromJson(b, &number); err != nil {
+		return err
+	}
+	*self = BlockNumber(number)
+	return nil
+}
+
+// blockNumber returns the number of an optional block parameter, the latest
+// block if it was omitted.
+func blockNumber(number *BlockNumber) int64 {
+	if number == nil {
+		return -1
+	}
+	return int64(*number)
+}
+
+// Number is an integer parameter given as number, decimal or hex string.
+type Number int64
+
+func (self *Number) UnmarshalJSON(b []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return NewDecodeParamError(err.Error())
+	}
+	num, err := numString(raw)
+	if err != nil {
+		return err
+	}
+	*self = Number(num.Int64())
+	return nil
+}
+
+func (self *BlockRef) UnmarshalJSON(b []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return NewDecodeParamError(err.Error())
+	}
+	return blockRef(raw, self)
+}
+
 // func toNumber(v interface{}) (int64, error) {
 // 	var str string
 // 	if v != nil {
@@ -103,57 +165,6 @@ func numString(raw interface{}) (*big.Int, error) {
 // 	return nil
 // }
 
-type GetBlockByHashArgs struct {
-	BlockHash  string
-	IncludeTxs bool
-}
-
-func (args *GetBlockByHashArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	argstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("blockHash", "not a string")
-	}
-	args.BlockHash = argstr
-
-	args.IncludeTxs = obj[1].(bool)
-
-	return nil
-}
-
-type GetBlockByNumberArgs struct {
-	BlockNumber int64
-	IncludeTxs  bool
-}
-
-func (args *GetBlockByNumberArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	if err := blockHeight(obj[0], &args.BlockNumber); err != nil {
-		return err
-	}
-
-	args.IncludeTxs = obj[1].(bool)
-
-	return nil
-}
-
 type NewTxArgs struct {
 	From     string
 	To       string
@@ -162,8 +173,6 @@ type NewTxArgs struct {
 	Gas      *big.Int
 	GasPrice *big.Int
 	Data     string
-
-	BlockNumber int64
 }
 
 type NewSigArgs struct {
@@ -172,24 +181,12 @@ type NewSigArgs struct {
 }
 
 func (args *NewSigArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []json.RawMessage
 	var ext struct {
 		From string
 		Data string
 	}
 
-	// Decode byte slice to array of RawMessages
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	// Check for sufficient params
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	// Decode 0th RawMessage to temporary struct
-	if err := json.Unmarshal(obj[0], &ext); err != nil {
+	if err := json.Unmarshal(b, &ext); err != nil {
 		return NewDecodeParamError(err.Error())
 	}
 
@@ -207,7 +204,6 @@ func (args *NewSigArgs) UnmarshalJSON(b []byte) (err error) {
 }
 
 func (args *NewTxArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []json.RawMessage
 	var ext struct {
 		From     string
 		To       string
@@ -218,18 +214,7 @@ func (args *NewTxArgs) UnmarshalJSON(b []byte) (err error) {
 		Data     string
 	}
 
-	// Decode byte slice to array of RawMessages
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	// Check for sufficient params
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	// Decode 0th RawMessage to temporary struct
-	if err := json.Unmarshal(obj[0], &ext); err != nil {
+	if err := json.Unmarshal(b, &ext); err != nil {
 		return NewDecodeParamError(err.Error())
 	}
 
@@ -280,15 +265,6 @@ func (args *NewTxArgs) UnmarshalJSON(b []byte) (err error) {
 	}
 	args.GasPrice = num
 
-	// Check for optional BlockNumber param
-	if len(obj) > 1 {
-		if err := blockHeightFromJson(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
 	return nil
 }
 
@@ -299,12 +275,9 @@ type CallArgs struct {
 	Gas      *big.Int
 	GasPrice *big.Int
 	Data     string
-
-	BlockNumber int64
 }
 
 func (args *CallArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []json.RawMessage
 	var ext struct {
 		From     string
 		To       string
@@ -314,18 +287,7 @@ func (args *CallArgs) UnmarshalJSON(b []byte) (err error) {
 		Data     string
 	}
 
-	// Decode byte slice to array of RawMessages
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	// Check for sufficient params
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	// Decode 0th RawMessage to temporary struct
-	if err := json.Unmarshal(obj[0], &ext); err != nil {
+	if err := json.Unmarshal(b, &ext); err != nil {
 		return NewDecodeParamError(err.Error())
 	}
 
@@ -366,306 +328,6 @@ func (args *CallArgs) UnmarshalJSON(b []byte) (err error) {
 
 	args.Data = ext.Data
 
-	// Check for optional BlockNumber param
-	if len(obj) > 1 {
-		if err := blockHeightFromJson(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type GetStorageArgs struct {
-	Address     string
-	BlockNumber int64
-}
-
-func (args *GetStorageArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	addstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("address", "not a string")
-	}
-	args.Address = addstr
-
-	if len(obj) > 1 {
-		if err := blockHeight(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type GetStorageAtArgs struct {
-	Address     string
-	Key         string
-	BlockNumber int64
-}
-
-func (args *GetStorageAtArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	addstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("address", "not a string")
-	}
-	args.Address = addstr
-
-	keystr, ok := obj[1].(string)
-	if !ok {
-		return NewInvalidTypeError("key", "not a string")
-	}
-	args.Key = keystr
-
-	if len(obj) > 2 {
-		if err := blockHeight(obj[2], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type GetTxCountArgs struct {
-	Address     string
-	BlockNumber int64
-}
-
-func (args *GetTxCountArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	addstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("address", "not a string")
-	}
-	args.Address = addstr
-
-	if len(obj) > 1 {
-		if err := blockHeight(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type GetBalanceArgs struct {
-	Address     string
-	BlockNumber int64
-}
-
-func (args *GetBalanceArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	addstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("address", "not a string")
-	}
-	args.Address = addstr
-
-	if len(obj) > 1 {
-		if err := blockHeight(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type GetDataArgs struct {
-	Address     string
-	BlockNumber int64
-}
-
-func (args *GetDataArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	addstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("address", "not a string")
-	}
-	args.Address = addstr
-
-	if len(obj) > 1 {
-		if err := blockHeight(obj[1], &args.BlockNumber); err != nil {
-			return err
-		}
-	} else {
-		args.BlockNumber = -1
-	}
-
-	return nil
-}
-
-type BlockNumArg struct {
-	BlockNumber int64
-}
-
-func (args *BlockNumArg) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	if err := blockHeight(obj[0], &args.BlockNumber); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-type BlockNumIndexArgs struct {
-	BlockNumber int64
-	Index       int64
-}
-
-func (args *BlockNumIndexArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	if err := blockHeight(obj[0], &args.BlockNumber); err != nil {
-		return err
-	}
-
-	var arg1 *big.Int
-	if arg1, err = numString(obj[1]); err != nil {
-		return err
-	}
-	args.Index = arg1.Int64()
-
-	return nil
-}
-
-type HashArgs struct {
-	Hash string
-}
-
-func (args *HashArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	arg0, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("hash", "not a string")
-	}
-	args.Hash = arg0
-
-	return nil
-}
-
-type HashIndexArgs struct {
-	Hash  string
-	Index int64
-}
-
-func (args *HashIndexArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	arg0, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("hash", "not a string")
-	}
-	args.Hash = arg0
-
-	arg1, ok := obj[1].(string)
-	if !ok {
-		return NewInvalidTypeError("index", "not a string")
-	}
-	args.Index = common.Big(arg1).Int64()
-
-	return nil
-}
-
-type Sha3Args struct {
-	Data string
-}
-
-func (args *Sha3Args) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	argstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("data", "is not a string")
-	}
-	args.Data = argstr
 	return nil
 }
 
@@ -679,7 +341,7 @@ type BlockFilterArgs struct {
 }
 
 func (args *BlockFilterArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []struct {
+	var obj struct {
 		FromBlock interface{} `json:"fromBlock"`
 		ToBlock   interface{} `json:"toBlock"`
 		Limit     interface{} `json:"limit"`
@@ -692,15 +354,11 @@ func (args *BlockFilterArgs) UnmarshalJSON(b []byte) (err error) {
 		return NewDecodeParamError(err.Error())
 	}
 
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	// args.Earliest, err = toNumber(obj[0].ToBlock)
+	// args.Earliest, err = toNumber(obj.ToBlock)
 	// if err != nil {
 	// 	return NewDecodeParamError(fmt.Sprintf("FromBlock %v", err))
 	// }
-	// args.Latest, err = toNumber(obj[0].FromBlock)
+	// args.Latest, err = toNumber(obj.FromBlock)
 	// if err != nil {
 	// 	return NewDecodeParamError(fmt.Sprintf("ToBlock %v", err))
 
@@ -708,10 +366,10 @@ func (args *BlockFilterArgs) UnmarshalJSON(b []byte) (err error) {
 	var numBig *big.Int
 
 	// if blank then latest
-	if obj[0].FromBlock == nil {
+	if obj.FromBlock == nil {
 		num = -1
 	} else {
-		if err := blockHeight(obj[0].FromBlock, &num); err != nil {
+		if err := blockHeight(obj.FromBlock, &num); err != nil {
 			return err
 		}
 	}
@@ -723,195 +381,100 @@ func (args *BlockFilterArgs) UnmarshalJSON(b []byte) (err error) {
 	}
 
 	// if blank than latest
-	if obj[0].ToBlock == nil {
+	if obj.ToBlock == nil {
 		num = -1
 	} else {
-		if err := blockHeight(obj[0].ToBlock, &num); err != nil {
+		if err := blockHeight(obj.ToBlock, &num); err != nil {
 			return err
 		}
 	}
 	args.Latest = num
 
-	if obj[0].Limit == nil {
+	if obj.Limit == nil {
 		numBig = big.NewInt(defaultLogLimit)
 	} else {
-		if numBig, err = numString(obj[0].Limit); err != nil {
+		if numBig, err = numString(obj.Limit); err != nil {
 			return err
 		}
 	}
 	args.Max = int(numBig.Int64())
 
-	if obj[0].Offset == nil {
+	if obj.Offset == nil {
 		numBig = big.NewInt(defaultLogOffset)
 	} else {
-		if numBig, err = numString(obj[0].Offset); err != nil {
+		if numBig, err = numString(obj.Offset); err != nil {
 			return err
 		}
 	}
 	args.Skip = int(numBig.Int64())
 
-	if obj[0].Address != nil {
-		marg, ok := obj[0].Address.([]interface{})
-		if ok {
-			v := make([]string, len(marg))
-			for i, arg := range marg {
-				argstr, ok := arg.(string)
-				if !ok {
-					return NewInvalidTypeError(fmt.Sprintf("address[%d]", i), "is not a string")
-				}
-				v[i] = argstr
-			}
-			args.Address = v
-		} else {
-			argstr, ok := obj[0].Address.(string)
-			if ok {
-				v := make([]string, 1)
-				v[0] = argstr
-				args.Address = v
-			} else {
-				return NewInvalidTypeError("address", "is not a string or array")
-			}
-		}
+	if args.Address, err = filterAddress(obj.Address); err != nil {
+		return err
 	}
-
-	if obj[0].Topics != nil {
-		other, ok := obj[0].Topics.([]interface{})
-		if ok {
-			topicdbl := make([][]string, len(other))
-			for i, iv := range other {
-				if argstr, ok := iv.(string); ok {
-					// Found a string, push into first element of array
-					topicsgl := make([]string, 1)
-					topicsgl[0] = argstr
-					topicdbl[i] = topicsgl
-				} else if argarray, ok := iv.([]interface{}); ok {
-					// Found an array of other
-					topicdbl[i] = make([]string, len(argarray))
-					for j, jv := range argarray {
-						if v, ok := jv.(string); ok {
-							topicdbl[i][j] = v
-						} else if jv == nil {
-							topicdbl[i][j] = ""
-						} else {
-							return NewInvalidTypeError(fmt.Sprintf("topic[%d][%d]", i, j), "is not a string")
-						}
-					}
-				} else if iv == nil {
-					topicdbl[i] = []string{""}
-				} else {
-					return NewInvalidTypeError(fmt.Sprintf("topic[%d]", i), "not a string or array")
-				}
-			}
-			args.Topics = topicdbl
-			return nil
-		} else {
-			return NewInvalidTypeError("topic", "is not a string or array")
-		}
+	if args.Topics, err = filterTopics(obj.Topics); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-type DbArgs struct {
-	Database string
-	Key      string
-	Value    []byte
-}
-
-func (args *DbArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	var objstr string
-	var ok bool
-
-	if objstr, ok = obj[0].(string); !ok {
-		return NewInvalidTypeError("database", "not a string")
-	}
-	args.Database = objstr
-
-	if objstr, ok = obj[1].(string); !ok {
-		return NewInvalidTypeError("key", "not a string")
+// filterAddress parses the address criteria of a log filter, a single
+// address or an array of them.
+func filterAddress(v interface{}) ([]string, error) {
+	if v == nil {
+		return nil, nil
 	}
-	args.Key = objstr
-
-	if len(obj) > 2 {
-		objstr, ok = obj[2].(string)
-		if !ok {
-			return NewInvalidTypeError("value", "not a string")
+	if marg, ok := v.([]interface{}); ok {
+		addrs := make([]string, len(marg))
+		for i, arg := range marg {
+			argstr, ok := arg.(string)
+			if !ok {
+				return nil, NewInvalidTypeError(fmt.Sprintf("address[%d]", i), "is not a string")
+			}
+			addrs[i] = argstr
 		}
-
-		args.Value = []byte(objstr)
+		return addrs, nil
 	}
-
-	return nil
-}
-
-func (a *DbArgs) requirements() error {
-	if len(a.Database) == 0 {
-		return NewValidationError("Database", "cannot be blank")
+	if argstr, ok := v.(string); ok {
+		return []string{argstr}, nil
 	}
-	if len(a.Key) == 0 {
-		return NewValidationError("Key", "cannot be blank")
-	}
-	return nil
-}
-
-type DbHexArgs struct {
-	Database string
-	Key      string
-	Value    []byte
+	return nil, NewInvalidTypeError("address", "is not a string or array")
 }
 
-func (args *DbHexArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
+// filterTopics parses the topic criteria of a log filter. Each position is
+// a topic, an array of alternative topics or null for any topic.
+func filterTopics(v interface{}) ([][]string, error) {
+	if v == nil {
+		return nil, nil
 	}
-
-	if len(obj) < 2 {
-		return NewInsufficientParamsError(len(obj), 2)
-	}
-
-	var objstr string
-	var ok bool
-
-	if objstr, ok = obj[0].(string); !ok {
-		return NewInvalidTypeError("database", "not a string")
-	}
-	args.Database = objstr
-
-	if objstr, ok = obj[1].(string); !ok {
-		return NewInvalidTypeError("key", "not a string")
-	}
-	args.Key = objstr
-
-	if len(obj) > 2 {
-		objstr, ok = obj[2].(string)
-		if !ok {
-			return NewInvalidTypeError("value", "not a string")
+	other, ok := v.([]interface{})
+	if !ok {
+		return nil, NewInvalidTypeError("topic", "is not a string or array")
+	}
+	topicdbl := make([][]string, len(other))
+	for i, iv := range other {
+		if argstr, ok := iv.(string); ok {
+			// Found a string, push into first element of array
+			topicdbl[i] = []string{argstr}
+		} else if argarray, ok := iv.([]interface{}); ok {
+			// Found an array of other
+			topicdbl[i] = make([]string, len(argarray))
+			for j, jv := range argarray {
+				if v, ok := jv.(string); ok {
+					topicdbl[i][j] = v
+				} else if jv == nil {
+					topicdbl[i][j] = ""
+				} else {
+					return nil, NewInvalidTypeError(fmt.Sprintf("topic[%d][%d]", i, j), "is not a string")
+				}
+			}
+		} else if iv == nil {
+			topicdbl[i] = []string{""}
+		} else {
+			return nil, NewInvalidTypeError(fmt.Sprintf("topic[%d]", i), "not a string or array")
 		}
-
-		args.Value = common.FromHex(objstr)
 	}
-
-	return nil
-}
-
-func (a *DbHexArgs) requirements() error {
-	if len(a.Database) == 0 {
-		return NewValidationError("Database", "cannot be blank")
-	}
-	if len(a.Key) == 0 {
-		return NewValidationError("Key", "cannot be blank")
-	}
-	return nil
+	return topicdbl, nil
 }
 
 type WhisperMessageArgs struct {
@@ -924,7 +487,7 @@ type WhisperMessageArgs struct {
 }
 
 func (args *WhisperMessageArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []struct {
+	var obj struct {
 		Payload  string
 		To       string
 		From     string
@@ -937,21 +500,18 @@ func (args *WhisperMessageArgs) UnmarshalJSON(b []byte) (err error) {
 		return NewDecodeParamError(err.Error())
 	}
 
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-	args.Payload = obj[0].Payload
-	args.To = obj[0].To
-	args.From = obj[0].From
-	args.Topics = obj[0].Topics
+	args.Payload = obj.Payload
+	args.To = obj.To
+	args.From = obj.From
+	args.Topics = obj.Topics
 
 	var num *big.Int
-	if num, err = numString(obj[0].Priority); err != nil {
+	if num, err = numString(obj.Priority); err != nil {
 		return err
 	}
 	args.Priority = uint32(num.Int64())
 
-	if num, err = numString(obj[0].Ttl); err != nil {
+	if num, err = numString(obj.Ttl); err != nil {
 		return err
 	}
 	args.Ttl = uint32(num.Int64())
@@ -959,57 +519,6 @@ func (args *WhisperMessageArgs) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
-type CompileArgs struct {
-	Source string
-}
-
-func (args *CompileArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-	argstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("arg0", "is not a string")
-	}
-	args.Source = argstr
-
-	return nil
-}
-
-type FilterStringArgs struct {
-	Word string
-}
-
-func (args *FilterStringArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	var argstr string
-	argstr, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("filter", "not a string")
-	}
-	switch argstr {
-	case "latest", "pending":
-		break
-	default:
-		return NewValidationError("Word", "Must be `latest` or `pending`")
-	}
-	args.Word = argstr
-	return nil
-}
-
 type FilterIdArgs struct {
 	Id int
 }
@@ -1033,11 +542,21 @@ func (args *FilterIdArgs) UnmarshalJSON(b []byte) (err error) {
 	return nil
 }
 
-type WhisperIdentityArgs struct {
-	Identity string
+// Subscription kinds of eth_subscribe.
+const (
+	NewHeadsSubscription               = "newHeads"
+	LogsSubscription                   = "logs"
+	NewPendingTransactionsSubscription = "newPendingTransactions"
+	SyncingSubscription                = "syncing"
+)
+
+type SubscribeArgs struct {
+	Kind    string
+	Address []string
+	Topics  [][]string
 }
 
-func (args *WhisperIdentityArgs) UnmarshalJSON(b []byte) (err error) {
+func (args *SubscribeArgs) UnmarshalJSON(b []byte) (err error) {
 	var obj []interface{}
 	if err := json.Unmarshal(b, &obj); err != nil {
 		return NewDecodeParamError(err.Error())
@@ -1047,18 +566,41 @@ func (args *WhisperIdentityArgs) UnmarshalJSON(b []byte) (err error) {
 		return NewInsufficientParamsError(len(obj), 1)
 	}
 
-	argstr, ok := obj[0].(string)
+	kind, ok := obj[0].(string)
 	if !ok {
-		return NewInvalidTypeError("arg0", "not a string")
+		return NewInvalidTypeError("subscription", "not a string")
+	}
+	args.Kind = kind
+
+	if len(obj) > 1 && obj[1] != nil {
+		criteria, ok := obj[1].(map[string]interface{})
+		if !ok {
+			return NewInvalidTypeError("criteria", "not an object")
+		}
+		if args.Address, err = filterAddress(criteria["address"]); err != nil {
+			return err
+		}
+		if args.Topics, err = filterTopics(criteria["topics"]); err != nil {
+			return err
+		}
 	}
-	// if !common.IsHex(argstr) {
-	// 	return NewValidationError("arg0", "not a hexstring")
-	// }
-	args.Identity = argstr
 
 	return nil
 }
 
+func (args *SubscribeArgs) requirements() error {
+	switch args.Kind {
+	case NewHeadsSubscription, NewPendingTransactionsSubscription, SyncingSubscription:
+		if len(args.Address) > 0 || len(args.Topics) > 0 {
+			return NewValidationError("criteria", "only supported for logs")
+		}
+	case LogsSubscription:
+	default:
+		return NewValidationError("subscription", fmt.Sprintf("unknown kind %q", args.Kind))
+	}
+	return nil
+}
+
 type WhisperFilterArgs struct {
 	To     string
 	From   string
@@ -1069,7 +611,7 @@ type WhisperFilterArgs struct {
 // JSON message blob into a WhisperFilterArgs structure.
 func (args *WhisperFilterArgs) UnmarshalJSON(b []byte) (err error) {
 	// Unmarshal the JSON message and sanity check
-	var obj []struct {
+	var obj struct {
 		To     interface{} `json:"to"`
 		From   interface{} `json:"from"`
 		Topics interface{} `json:"topics"`
@@ -1077,32 +619,29 @@ func (args *WhisperFilterArgs) UnmarshalJSON(b []byte) (err error) {
 	if err := json.Unmarshal(b, &obj); err != nil {
 		return NewDecodeParamError(err.Error())
 	}
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
 	// Retrieve the simple data contents of the filter arguments
-	if obj[0].To == nil {
+	if obj.To == nil {
 		args.To = ""
 	} else {
-		argstr, ok := obj[0].To.(string)
+		argstr, ok := obj.To.(string)
 		if !ok {
 			return NewInvalidTypeError("to", "is not a string")
 		}
 		args.To = argstr
 	}
-	if obj[0].From == nil {
+	if obj.From == nil {
 		args.From = ""
 	} else {
-		argstr, ok := obj[0].From.(string)
+		argstr, ok := obj.From.(string)
 		if !ok {
 			return NewInvalidTypeError("from", "is not a string")
 		}
 		args.From = argstr
 	}
 	// Construct the nested topic array
-	if obj[0].Topics != nil {
+	if obj.Topics != nil {
 		// Make sure we have an actual topic array
-		list, ok := obj[0].Topics.([]interface{})
+		list, ok := obj.Topics.([]interface{})
 		if !ok {
 			return NewInvalidTypeError("topics", "is not an array")
 		}
@@ -1138,64 +677,3 @@ func (args *WhisperFilterArgs) UnmarshalJSON(b []byte) (err error) {
 	}
 	return nil
 }
-
-type SubmitWorkArgs struct {
-	Nonce  uint64
-	Header string
-	Digest string
-}
-
-func (args *SubmitWorkArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err = json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 3 {
-		return NewInsufficientParamsError(len(obj), 3)
-	}
-
-	var objstr string
-	var ok bool
-	if objstr, ok = obj[0].(string); !ok {
-		return NewInvalidTypeError("nonce", "not a string")
-	}
-
-	args.Nonce = common.String2Big(objstr).Uint64()
-	if objstr, ok = obj[1].(string); !ok {
-		return NewInvalidTypeError("header", "not a string")
-	}
-
-	args.Header = objstr
-
-	if objstr, ok = obj[2].(string); !ok {
-		return NewInvalidTypeError("digest", "not a string")
-	}
-
-	args.Digest = objstr
-
-	return nil
-}
-
-type SourceArgs struct {
-	Source string
-}
-
-func (args *SourceArgs) UnmarshalJSON(b []byte) (err error) {
-	var obj []interface{}
-	if err := json.Unmarshal(b, &obj); err != nil {
-		return NewDecodeParamError(err.Error())
-	}
-
-	if len(obj) < 1 {
-		return NewInsufficientParamsError(len(obj), 1)
-	}
-
-	arg0, ok := obj[0].(string)
-	if !ok {
-		return NewInvalidTypeError("source code", "not a string")
-	}
-	args.Source = arg0
-
-	return nil
-}
@@ -63,6 +63,72 @@ func TestBlockheightPending(t *testing.T) {
 	}
 }
 
+func TestBlockNumber(t *testing.T) {
+	tests := map[string]BlockNumber{
+		`"0x1f"`:     31,
+		`31`:         31,
+		`"earliest"`: 0,
+		`"latest"`:   -1,
+		`"pending"`:  -2,
+	}
+	for input, expected := range tests {
+		var number BlockNumber
+		if err := json.Unmarshal([]byte(input), &number); err != nil {
+			t.Errorf("%s: %v", input, err)
+		} else if number != expected {
+			t.Errorf("%s: got %d, want %d", input, number, expected)
+		}
+	}
+
+	for _, input := range []string{`"foo"`, `false`, `{}`, `null`} {
+		var number BlockNumber
+		if str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &number)); len(str) > 0 {
+			t.Errorf("%s: %s", input, str)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[string]Number{
+		`"0xa"`: 10,
+		`"10"`:  10,
+		`10`:    10,
+	}
+	for input, expected := range tests {
+		var number Number
+		if err := json.Unmarshal([]byte(input), &number); err != nil {
+			t.Errorf("%s: %v", input, err)
+		} else if number != expected {
+			t.Errorf("%s: got %d, want %d", input, number, expected)
+		}
+	}
+
+	var number Number
+	if str := ExpectInvalidTypeError(json.Unmarshal([]byte(`true`), &number)); len(str) > 0 {
+		t.Error(str)
+	}
+}
+
+func TestBlockRef(t *testing.T) {
+	hash := "0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"
+
+	var ref BlockRef
+	if err := json.Unmarshal([]byte(`"`+hash+`"`), &ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Hash != hash {
+		t.Errorf("Hash should be %v but is %v", hash, ref.Hash)
+	}
+
+	ref = BlockRef{}
+	if err := json.Unmarshal([]byte(`"0x10"`), &ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Hash != "" || ref.Number != 16 {
+		t.Errorf("expected block 16, got %+v", ref)
+	}
+}
+
 func ExpectValidationError(err error) string {
 	var str string
 	switch err.(type) {
@@ -115,313 +181,13 @@ func ExpectDecodeParamError(err error) string {
 	return str
 }
 
-func TestSha3(t *testing.T) {
-	input := `["0x68656c6c6f20776f726c64"]`
-	expected := "0x68656c6c6f20776f726c64"
-
-	args := new(Sha3Args)
-	json.Unmarshal([]byte(input), &args)
-
-	if args.Data != expected {
-		t.Error("got %s expected %s", input, expected)
-	}
-}
-
-func TestSha3ArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(Sha3Args)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestSha3ArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(Sha3Args)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestSha3ArgsDataInvalid(t *testing.T) {
-	input := `[4]`
-
-	args := new(Sha3Args)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBalanceArgs(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "0x1f"]`
-	expected := new(GetBalanceArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = 31
-
-	args := new(GetBalanceArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.Address != expected.Address {
-		t.Errorf("Address should be %v but is %v", expected.Address, args.Address)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetBalanceArgsBlocknumMissing(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1"]`
-	expected := new(GetBalanceArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -1
-
-	args := new(GetBalanceArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.Address != expected.Address {
-		t.Errorf("Address should be %v but is %v", expected.Address, args.Address)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetBalanceArgsLatest(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "latest"]`
-	expected := new(GetBalanceArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -1
-
-	args := new(GetBalanceArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.Address != expected.Address {
-		t.Errorf("Address should be %v but is %v", expected.Address, args.Address)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetBalanceArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(GetBalanceArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBalanceArgsInvalid(t *testing.T) {
-	input := `6`
-
-	args := new(GetBalanceArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBalanceArgsBlockInvalid(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", false]`
-
-	args := new(GetBalanceArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBalanceArgsAddressInvalid(t *testing.T) {
-	input := `[-9, "latest"]`
-
-	args := new(GetBalanceArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByHashArgs(t *testing.T) {
-	input := `["0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331", true]`
-	expected := new(GetBlockByHashArgs)
-	expected.BlockHash = "0xe670ec64341771606e55d6b4ca35a1a6b75ee3d5145a99d05921026d1527331"
-	expected.IncludeTxs = true
-
-	args := new(GetBlockByHashArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.BlockHash != expected.BlockHash {
-		t.Errorf("BlockHash should be %v but is %v", expected.BlockHash, args.BlockHash)
-	}
-
-	if args.IncludeTxs != expected.IncludeTxs {
-		t.Errorf("IncludeTxs should be %v but is %v", expected.IncludeTxs, args.IncludeTxs)
-	}
-}
-
-func TestGetBlockByHashArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(GetBlockByHashArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByHashArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(GetBlockByHashArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByHashArgsHashInt(t *testing.T) {
-	input := `[8]`
-
-	args := new(GetBlockByHashArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByHashArgsHashBool(t *testing.T) {
-	input := `[false, true]`
-
-	args := new(GetBlockByHashArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByNumberArgsBlockNum(t *testing.T) {
-	input := `[436, false]`
-	expected := new(GetBlockByNumberArgs)
-	expected.BlockNumber = 436
-	expected.IncludeTxs = false
-
-	args := new(GetBlockByNumberArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-
-	if args.IncludeTxs != expected.IncludeTxs {
-		t.Errorf("IncludeTxs should be %v but is %v", expected.IncludeTxs, args.IncludeTxs)
-	}
-}
-
-func TestGetBlockByNumberArgsBlockHex(t *testing.T) {
-	input := `["0x1b4", false]`
-	expected := new(GetBlockByNumberArgs)
-	expected.BlockNumber = 436
-	expected.IncludeTxs = false
-
-	args := new(GetBlockByNumberArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-
-	if args.IncludeTxs != expected.IncludeTxs {
-		t.Errorf("IncludeTxs should be %v but is %v", expected.IncludeTxs, args.IncludeTxs)
-	}
-}
-func TestGetBlockByNumberArgsWords(t *testing.T) {
-	input := `["earliest", true]`
-	expected := new(GetBlockByNumberArgs)
-	expected.BlockNumber = 0
-	expected.IncludeTxs = true
-
-	args := new(GetBlockByNumberArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if args.BlockNumber != expected.BlockNumber {
-		t.Errorf("BlockNumber should be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-
-	if args.IncludeTxs != expected.IncludeTxs {
-		t.Errorf("IncludeTxs should be %v but is %v", expected.IncludeTxs, args.IncludeTxs)
-	}
-}
-
-func TestGetBlockByNumberEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(GetBlockByNumberArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByNumberShort(t *testing.T) {
-	input := `["0xbbb"]`
-
-	args := new(GetBlockByNumberArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetBlockByNumberBool(t *testing.T) {
-	input := `[true, true]`
-
-	args := new(GetBlockByNumberArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestGetBlockByNumberBlockObject(t *testing.T) {
-	input := `{}`
-
-	args := new(GetBlockByNumberArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
 func TestNewTxArgs(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  "0x10"]`
+  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}`
 	expected := new(NewTxArgs)
 	expected.From = "0xb60e8dd61c5d32be8058bb8eb970870f07233155"
 	expected.To = "0xd46e8dd67c5d32be8058bb8eb970870f072445675"
@@ -429,7 +195,6 @@ func TestNewTxArgs(t *testing.T) {
 	expected.GasPrice = big.NewInt(10000000000000)
 	expected.Value = big.NewInt(10000000000000)
 	expected.Data = "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	expected.BlockNumber = big.NewInt(16).Int64()
 
 	args := new(NewTxArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -459,25 +224,19 @@ func TestNewTxArgs(t *testing.T) {
 	if expected.Data != args.Data {
 		t.Errorf("Data shoud be %#v but is %#v", expected.Data, args.Data)
 	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
 }
 
 func TestNewTxArgsInt(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": 100,
   "gasPrice": 50,
   "value": 8765456789,
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  5]`
+  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}`
 	expected := new(NewTxArgs)
 	expected.Gas = big.NewInt(100)
 	expected.GasPrice = big.NewInt(50)
 	expected.Value = big.NewInt(8765456789)
-	expected.BlockNumber = int64(5)
 
 	args := new(NewTxArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -495,36 +254,16 @@ func TestNewTxArgsInt(t *testing.T) {
 	if bytes.Compare(expected.Value.Bytes(), args.Value.Bytes()) != 0 {
 		t.Errorf("Value shoud be %v but is %v", expected.Value, args.Value)
 	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestNewTxArgsBlockBool(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
-  "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
-  "gas": "0x76c0",
-  "gasPrice": "0x9184e72a000",
-  "value": "0x9184e72a000",
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  false]`
-
-	args := new(NewTxArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
 }
 
 func TestNewTxArgsGasInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": false,
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(NewTxArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -534,13 +273,13 @@ func TestNewTxArgsGasInvalid(t *testing.T) {
 }
 
 func TestNewTxArgsGaspriceInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": false,
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(NewTxArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -550,13 +289,13 @@ func TestNewTxArgsGaspriceInvalid(t *testing.T) {
 }
 
 func TestNewTxArgsValueInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "value": false,
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	}]`
+	}`
 
 	args := new(NewTxArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -566,12 +305,12 @@ func TestNewTxArgsValueInvalid(t *testing.T) {
 }
 
 func TestNewTxArgsGasMissing(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 	expected := new(NewTxArgs)
 	expected.Gas = big.NewInt(0)
 
@@ -586,13 +325,13 @@ func TestNewTxArgsGasMissing(t *testing.T) {
 }
 
 func TestNewTxArgsBlockGaspriceMissing(t *testing.T) {
-	input := `[{
+	input := `{
 	"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 	expected := new(NewTxArgs)
 	expected.GasPrice = big.NewInt(0)
 
@@ -608,13 +347,13 @@ func TestNewTxArgsBlockGaspriceMissing(t *testing.T) {
 }
 
 func TestNewTxArgsValueMissing(t *testing.T) {
-	input := `[{
+	input := `{
 	"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	}]`
+	}`
 	expected := new(NewTxArgs)
 	expected.Value = big.NewInt(0)
 
@@ -629,18 +368,8 @@ func TestNewTxArgsValueMissing(t *testing.T) {
 
 }
 
-func TestNewTxArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(NewTxArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
 func TestNewTxArgsInvalid(t *testing.T) {
-	input := `{}`
+	input := `[]`
 
 	args := new(NewTxArgs)
 	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
@@ -649,7 +378,7 @@ func TestNewTxArgsInvalid(t *testing.T) {
 	}
 }
 func TestNewTxArgsNotStrings(t *testing.T) {
-	input := `[{"from":6}]`
+	input := `{"from":6}`
 
 	args := new(NewTxArgs)
 	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
@@ -659,7 +388,7 @@ func TestNewTxArgsNotStrings(t *testing.T) {
 }
 
 func TestNewTxArgsFromEmpty(t *testing.T) {
-	input := `[{"to": "0xb60e8dd61c5d32be8058bb8eb970870f07233155"}]`
+	input := `{"to": "0xb60e8dd61c5d32be8058bb8eb970870f07233155"}`
 
 	args := new(NewTxArgs)
 	str := ExpectValidationError(json.Unmarshal([]byte(input), &args))
@@ -669,13 +398,12 @@ func TestNewTxArgsFromEmpty(t *testing.T) {
 }
 
 func TestCallArgs(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  "0x10"]`
+  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}`
 	expected := new(CallArgs)
 	expected.From = "0xb60e8dd61c5d32be8058bb8eb970870f07233155"
 	expected.To = "0xd46e8dd67c5d32be8058bb8eb970870f072445675"
@@ -683,7 +411,6 @@ func TestCallArgs(t *testing.T) {
 	expected.GasPrice = big.NewInt(10000000000000)
 	expected.Value = big.NewInt(10000000000000)
 	expected.Data = "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	expected.BlockNumber = big.NewInt(16).Int64()
 
 	args := new(CallArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -709,25 +436,19 @@ func TestCallArgs(t *testing.T) {
 	if expected.Data != args.Data {
 		t.Errorf("Data shoud be %#v but is %#v", expected.Data, args.Data)
 	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
 }
 
 func TestCallArgsInt(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": 100,
   "gasPrice": 50,
   "value": 8765456789,
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  5]`
+  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}`
 	expected := new(CallArgs)
 	expected.Gas = big.NewInt(100)
 	expected.GasPrice = big.NewInt(50)
 	expected.Value = big.NewInt(8765456789)
-	expected.BlockNumber = int64(5)
 
 	args := new(CallArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -745,36 +466,16 @@ func TestCallArgsInt(t *testing.T) {
 	if bytes.Compare(expected.Value.Bytes(), args.Value.Bytes()) != 0 {
 		t.Errorf("Value shoud be %v but is %v", expected.Value, args.Value)
 	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %v but is %v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestCallArgsBlockBool(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
-  "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
-  "gas": "0x76c0",
-  "gasPrice": "0x9184e72a000",
-  "value": "0x9184e72a000",
-  "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"},
-  false]`
-
-	args := new(CallArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
 }
 
 func TestCallArgsGasInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": false,
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(CallArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -784,13 +485,13 @@ func TestCallArgsGasInvalid(t *testing.T) {
 }
 
 func TestCallArgsGaspriceInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": false,
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(CallArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -800,13 +501,13 @@ func TestCallArgsGaspriceInvalid(t *testing.T) {
 }
 
 func TestCallArgsValueInvalid(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "value": false,
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	}]`
+	}`
 
 	args := new(CallArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -816,12 +517,12 @@ func TestCallArgsValueInvalid(t *testing.T) {
 }
 
 func TestCallArgsGasMissing(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gasPrice": "0x9184e72a000",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(CallArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -838,13 +539,13 @@ func TestCallArgsGasMissing(t *testing.T) {
 }
 
 func TestCallArgsBlockGaspriceMissing(t *testing.T) {
-	input := `[{
+	input := `{
 	"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "value": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-  }]`
+  }`
 
 	args := new(CallArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -860,13 +561,13 @@ func TestCallArgsBlockGaspriceMissing(t *testing.T) {
 }
 
 func TestCallArgsValueMissing(t *testing.T) {
-	input := `[{
+	input := `{
 	"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155",
   "to": "0xd46e8dd67c5d32be8058bb8eb970870f072445675",
   "gas": "0x76c0",
   "gasPrice": "0x9184e72a000",
   "data": "0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"
-	}]`
+	}`
 
 	args := new(CallArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -881,27 +582,17 @@ func TestCallArgsValueMissing(t *testing.T) {
 	}
 }
 
-func TestCallArgsEmpty(t *testing.T) {
+func TestCallArgsInvalid(t *testing.T) {
 	input := `[]`
 
 	args := new(CallArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
+	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
 	if len(str) > 0 {
 		t.Error(str)
 	}
 }
-
-func TestCallArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(CallArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestCallArgsNotStrings(t *testing.T) {
-	input := `[{"from":6}]`
+func TestCallArgsNotStrings(t *testing.T) {
+	input := `{"from":6}`
 
 	args := new(CallArgs)
 	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
@@ -911,7 +602,7 @@ func TestCallArgsNotStrings(t *testing.T) {
 }
 
 func TestCallArgsToEmpty(t *testing.T) {
-	input := `[{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155"}]`
+	input := `{"from": "0xb60e8dd61c5d32be8058bb8eb970870f07233155"}`
 	args := new(CallArgs)
 	str := ExpectValidationError(json.Unmarshal([]byte(input), &args))
 	if len(str) > 0 {
@@ -919,348 +610,8 @@ func TestCallArgsToEmpty(t *testing.T) {
 	}
 }
 
-func TestGetStorageArgs(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "latest"]`
-	expected := new(GetStorageArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -1
-
-	args := new(GetStorageArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetStorageArgsMissingBlocknum(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1"]`
-	expected := new(GetStorageArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -1
-
-	args := new(GetStorageArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetStorageInvalidArgs(t *testing.T) {
-	input := `{}`
-
-	args := new(GetStorageArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageInvalidBlockheight(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", {}]`
-
-	args := new(GetStorageArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageEmptyArgs(t *testing.T) {
-	input := `[]`
-
-	args := new(GetStorageArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAddressInt(t *testing.T) {
-	input := `[32456785432456, "latest"]`
-
-	args := new(GetStorageArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAtArgs(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "0x0", "0x2"]`
-	expected := new(GetStorageAtArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.Key = "0x0"
-	expected.BlockNumber = 2
-
-	args := new(GetStorageAtArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.Key != args.Key {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetStorageAtArgsMissingBlocknum(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "0x0"]`
-	expected := new(GetStorageAtArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.Key = "0x0"
-	expected.BlockNumber = -1
-
-	args := new(GetStorageAtArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.Key != args.Key {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetStorageAtEmptyArgs(t *testing.T) {
-	input := `[]`
-
-	args := new(GetStorageAtArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAtArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(GetStorageAtArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAtArgsAddressNotString(t *testing.T) {
-	input := `[true, "0x0", "0x2"]`
-
-	args := new(GetStorageAtArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAtArgsKeyNotString(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", true, "0x2"]`
-
-	args := new(GetStorageAtArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetStorageAtArgsValueNotString(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "0x1", true]`
-
-	args := new(GetStorageAtArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetTxCountArgs(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", "pending"]`
-	expected := new(GetTxCountArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -2
-
-	args := new(GetTxCountArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetTxCountEmptyArgs(t *testing.T) {
-	input := `[]`
-
-	args := new(GetTxCountArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetTxCountEmptyArgsInvalid(t *testing.T) {
-	input := `false`
-
-	args := new(GetTxCountArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetTxCountAddressNotString(t *testing.T) {
-	input := `[false, "pending"]`
-
-	args := new(GetTxCountArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetTxCountBlockheightMissing(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1"]`
-	expected := new(GetTxCountArgs)
-	expected.Address = "0x407d73d8a49eeb85d32cf465507dd71d507100c1"
-	expected.BlockNumber = -1
-
-	args := new(GetTxCountArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetTxCountBlockheightInvalid(t *testing.T) {
-	input := `["0x407d73d8a49eeb85d32cf465507dd71d507100c1", {}]`
-
-	args := new(GetTxCountArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetDataArgs(t *testing.T) {
-	input := `["0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8", "latest"]`
-	expected := new(GetDataArgs)
-	expected.Address = "0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8"
-	expected.BlockNumber = -1
-
-	args := new(GetDataArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetDataArgsBlocknumMissing(t *testing.T) {
-	input := `["0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8"]`
-	expected := new(GetDataArgs)
-	expected.Address = "0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8"
-	expected.BlockNumber = -1
-
-	args := new(GetDataArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Address != args.Address {
-		t.Errorf("Address shoud be %#v but is %#v", expected.Address, args.Address)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestGetDataArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(GetDataArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetDataArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(GetDataArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetDataArgsAddressNotString(t *testing.T) {
-	input := `[12, "latest"]`
-
-	args := new(GetDataArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestGetDataArgsBlocknumberNotString(t *testing.T) {
-	input := `["0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8", false]`
-
-	args := new(GetDataArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
 func TestBlockFilterArgs(t *testing.T) {
-	input := `[{
+	input := `{
   "fromBlock": "0x1",
   "toBlock": "0x2",
   "limit": "0x3",
@@ -1271,7 +622,7 @@ func TestBlockFilterArgs(t *testing.T) {
   	["0xAA", "0xBB"],
   	["0xCC", "0xDD"]
   ]
-  }]`
+  }`
 
 	expected := new(BlockFilterArgs)
 	expected.Earliest = 1
@@ -1325,10 +676,10 @@ func TestBlockFilterArgs(t *testing.T) {
 }
 
 func TestBlockFilterArgsDefaults(t *testing.T) {
-	input := `[{
+	input := `{
   "address": ["0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8"],
   "topics": ["0xAA","0xBB"]
-  }]`
+  }`
 	expected := new(BlockFilterArgs)
 	expected.Earliest = -1
 	expected.Latest = -1
@@ -1372,10 +723,10 @@ func TestBlockFilterArgsDefaults(t *testing.T) {
 }
 
 func TestBlockFilterArgsWords(t *testing.T) {
-	input := `[{
+	input := `{
   "fromBlock": "latest",
   "toBlock": "pending"
-  }]`
+  }`
 	expected := new(BlockFilterArgs)
 	expected.Earliest = -1
 	expected.Latest = -2
@@ -1395,7 +746,7 @@ func TestBlockFilterArgsWords(t *testing.T) {
 }
 
 func TestBlockFilterArgsInvalid(t *testing.T) {
-	input := `{}`
+	input := `[]`
 
 	args := new(BlockFilterArgs)
 	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
@@ -1405,10 +756,10 @@ func TestBlockFilterArgsInvalid(t *testing.T) {
 }
 
 func TestBlockFilterArgsFromBool(t *testing.T) {
-	input := `[{
+	input := `{
   "fromBlock": true,
   "toBlock": "pending"
-  }]`
+  }`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1418,10 +769,10 @@ func TestBlockFilterArgsFromBool(t *testing.T) {
 }
 
 func TestBlockFilterArgsToBool(t *testing.T) {
-	input := `[{
+	input := `{
   "fromBlock": "pending",
   "toBlock": true
-  }]`
+  }`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1430,18 +781,8 @@ func TestBlockFilterArgsToBool(t *testing.T) {
 	}
 }
 
-func TestBlockFilterArgsEmptyArgs(t *testing.T) {
-	input := `[]`
-
-	args := new(BlockFilterArgs)
-	err := json.Unmarshal([]byte(input), &args)
-	if err == nil {
-		t.Error("Expected error but didn't get one")
-	}
-}
-
 func TestBlockFilterArgsLimitInvalid(t *testing.T) {
-	input := `[{"limit": false}]`
+	input := `{"limit": false}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1451,7 +792,7 @@ func TestBlockFilterArgsLimitInvalid(t *testing.T) {
 }
 
 func TestBlockFilterArgsOffsetInvalid(t *testing.T) {
-	input := `[{"offset": true}]`
+	input := `{"offset": true}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1461,9 +802,9 @@ func TestBlockFilterArgsOffsetInvalid(t *testing.T) {
 }
 
 func TestBlockFilterArgsAddressInt(t *testing.T) {
-	input := `[{
+	input := `{
   "address": 1,
-  "topics": "0x12341234"}]`
+  "topics": "0x12341234"}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1473,9 +814,9 @@ func TestBlockFilterArgsAddressInt(t *testing.T) {
 }
 
 func TestBlockFilterArgsAddressSliceInt(t *testing.T) {
-	input := `[{
+	input := `{
   "address": [1],
-  "topics": "0x12341234"}]`
+  "topics": "0x12341234"}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1485,9 +826,9 @@ func TestBlockFilterArgsAddressSliceInt(t *testing.T) {
 }
 
 func TestBlockFilterArgsTopicInt(t *testing.T) {
-	input := `[{
+	input := `{
   "address": ["0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8"],
-  "topics": 1}]`
+  "topics": 1}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1497,9 +838,9 @@ func TestBlockFilterArgsTopicInt(t *testing.T) {
 }
 
 func TestBlockFilterArgsTopicSliceInt(t *testing.T) {
-	input := `[{
+	input := `{
   "address": "0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8",
-  "topics": [1]}]`
+  "topics": [1]}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1509,9 +850,9 @@ func TestBlockFilterArgsTopicSliceInt(t *testing.T) {
 }
 
 func TestBlockFilterArgsTopicSliceInt2(t *testing.T) {
-	input := `[{
+	input := `{
   "address": "0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8",
-  "topics": ["0xAA", [1]]}]`
+  "topics": ["0xAA", [1]]}`
 
 	args := new(BlockFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
@@ -1521,10 +862,10 @@ func TestBlockFilterArgsTopicSliceInt2(t *testing.T) {
 }
 
 func TestBlockFilterArgsTopicComplex(t *testing.T) {
-	input := `[{
+	input := `{
 	"address": "0xd5677cf67b5aa051bb40496e68ad359eb97cfbf8",
   "topics": ["0xAA", ["0xBB", "0xCC"]]
-  }]`
+  }`
 
 	args := new(BlockFilterArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
@@ -1546,249 +887,35 @@ func TestBlockFilterArgsTopicComplex(t *testing.T) {
 	}
 }
 
-func TestDbArgs(t *testing.T) {
-	input := `["testDB","myKey","0xbeef"]`
-	expected := new(DbArgs)
-	expected.Database = "testDB"
-	expected.Key = "myKey"
-	expected.Value = []byte("0xbeef")
+func TestWhisperMessageArgs(t *testing.T) {
+	input := `{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
+  "topics": ["0x68656c6c6f20776f726c64"],
+  "payload":"0x68656c6c6f20776f726c64",
+  "ttl": "0x64",
+  "priority": "0x64"}`
+	expected := new(WhisperMessageArgs)
+	expected.From = "0xc931d93e97ab07fe42d923478ba2465f2"
+	expected.To = ""
+	expected.Payload = "0x68656c6c6f20776f726c64"
+	expected.Priority = 100
+	expected.Ttl = 100
+	// expected.Topics = []string{"0x68656c6c6f20776f726c64"}
 
-	args := new(DbArgs)
+	args := new(WhisperMessageArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
 		t.Error(err)
 	}
 
-	if err := args.requirements(); err != nil {
-		t.Error(err)
+	if expected.From != args.From {
+		t.Errorf("From shoud be %#v but is %#v", expected.From, args.From)
 	}
 
-	if expected.Database != args.Database {
-		t.Errorf("Database shoud be %#v but is %#v", expected.Database, args.Database)
+	if expected.To != args.To {
+		t.Errorf("To shoud be %#v but is %#v", expected.To, args.To)
 	}
 
-	if expected.Key != args.Key {
-		t.Errorf("Key shoud be %#v but is %#v", expected.Key, args.Key)
-	}
-
-	if bytes.Compare(expected.Value, args.Value) != 0 {
-		t.Errorf("Value shoud be %#v but is %#v", expected.Value, args.Value)
-	}
-}
-
-func TestDbArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(DbArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(DbArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsDatabaseType(t *testing.T) {
-	input := `[true, "keyval", "valval"]`
-
-	args := new(DbArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsKeyType(t *testing.T) {
-	input := `["dbval", 3, "valval"]`
-
-	args := new(DbArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsValType(t *testing.T) {
-	input := `["dbval", "keyval", {}]`
-
-	args := new(DbArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsDatabaseEmpty(t *testing.T) {
-	input := `["", "keyval", "valval"]`
-
-	args := new(DbArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err.Error())
-	}
-
-	str := ExpectValidationError(args.requirements())
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbArgsKeyEmpty(t *testing.T) {
-	input := `["dbval", "", "valval"]`
-
-	args := new(DbArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err.Error())
-	}
-
-	str := ExpectValidationError(args.requirements())
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgs(t *testing.T) {
-	input := `["testDB","myKey","0xbeef"]`
-	expected := new(DbHexArgs)
-	expected.Database = "testDB"
-	expected.Key = "myKey"
-	expected.Value = []byte{0xbe, 0xef}
-
-	args := new(DbHexArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if err := args.requirements(); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Database != args.Database {
-		t.Errorf("Database shoud be %#v but is %#v", expected.Database, args.Database)
-	}
-
-	if expected.Key != args.Key {
-		t.Errorf("Key shoud be %#v but is %#v", expected.Key, args.Key)
-	}
-
-	if bytes.Compare(expected.Value, args.Value) != 0 {
-		t.Errorf("Value shoud be %#v but is %#v", expected.Value, args.Value)
-	}
-}
-
-func TestDbHexArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(DbHexArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(DbHexArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsDatabaseType(t *testing.T) {
-	input := `[true, "keyval", "valval"]`
-
-	args := new(DbHexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsKeyType(t *testing.T) {
-	input := `["dbval", 3, "valval"]`
-
-	args := new(DbHexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsValType(t *testing.T) {
-	input := `["dbval", "keyval", {}]`
-
-	args := new(DbHexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsDatabaseEmpty(t *testing.T) {
-	input := `["", "keyval", "valval"]`
-
-	args := new(DbHexArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err.Error())
-	}
-
-	str := ExpectValidationError(args.requirements())
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestDbHexArgsKeyEmpty(t *testing.T) {
-	input := `["dbval", "", "valval"]`
-
-	args := new(DbHexArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err.Error())
-	}
-
-	str := ExpectValidationError(args.requirements())
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestWhisperMessageArgs(t *testing.T) {
-	input := `[{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
-  "topics": ["0x68656c6c6f20776f726c64"],
-  "payload":"0x68656c6c6f20776f726c64",
-  "ttl": "0x64",
-  "priority": "0x64"}]`
-	expected := new(WhisperMessageArgs)
-	expected.From = "0xc931d93e97ab07fe42d923478ba2465f2"
-	expected.To = ""
-	expected.Payload = "0x68656c6c6f20776f726c64"
-	expected.Priority = 100
-	expected.Ttl = 100
-	// expected.Topics = []string{"0x68656c6c6f20776f726c64"}
-
-	args := new(WhisperMessageArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.From != args.From {
-		t.Errorf("From shoud be %#v but is %#v", expected.From, args.From)
-	}
-
-	if expected.To != args.To {
-		t.Errorf("To shoud be %#v but is %#v", expected.To, args.To)
-	}
-
-	if expected.Payload != args.Payload {
-		t.Errorf("Value shoud be %#v but is %#v", expected.Payload, args.Payload)
+	if expected.Payload != args.Payload {
+		t.Errorf("Value shoud be %#v but is %#v", expected.Payload, args.Payload)
 	}
 
 	if expected.Ttl != args.Ttl {
@@ -1805,11 +932,11 @@ func TestWhisperMessageArgs(t *testing.T) {
 }
 
 func TestWhisperMessageArgsInt(t *testing.T) {
-	input := `[{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
+	input := `{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
   "topics": ["0x68656c6c6f20776f726c64"],
   "payload":"0x68656c6c6f20776f726c64",
   "ttl": 12,
-  "priority": 16}]`
+  "priority": 16}`
 	expected := new(WhisperMessageArgs)
 	expected.From = "0xc931d93e97ab07fe42d923478ba2465f2"
 	expected.To = ""
@@ -1849,31 +976,21 @@ func TestWhisperMessageArgsInt(t *testing.T) {
 }
 
 func TestWhisperMessageArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(WhisperMessageArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestWhisperMessageArgsEmpty(t *testing.T) {
 	input := `[]`
 
 	args := new(WhisperMessageArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), args))
+	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
 	if len(str) > 0 {
 		t.Error(str)
 	}
 }
 
 func TestWhisperMessageArgsTtlBool(t *testing.T) {
-	input := `[{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
+	input := `{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
   "topics": ["0x68656c6c6f20776f726c64"],
   "payload":"0x68656c6c6f20776f726c64",
   "ttl": true,
-  "priority": "0x64"}]`
+  "priority": "0x64"}`
 	args := new(WhisperMessageArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
 	if len(str) > 0 {
@@ -1882,11 +999,11 @@ func TestWhisperMessageArgsTtlBool(t *testing.T) {
 }
 
 func TestWhisperMessageArgsPriorityBool(t *testing.T) {
-	input := `[{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
+	input := `{"from":"0xc931d93e97ab07fe42d923478ba2465f2",
   "topics": ["0x68656c6c6f20776f726c64"],
   "payload":"0x68656c6c6f20776f726c64",
   "ttl": "0x12",
-  "priority": true}]`
+  "priority": true}`
 	args := new(WhisperMessageArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
 	if len(str) > 0 {
@@ -1940,7 +1057,7 @@ func TestFilterIdArgsBool(t *testing.T) {
 }
 
 func TestWhisperFilterArgs(t *testing.T) {
-	input := `[{"topics": ["0x68656c6c6f20776f726c64"], "to": "0x34ag445g3455b34"}]`
+	input := `{"topics": ["0x68656c6c6f20776f726c64"], "to": "0x34ag445g3455b34"}`
 	expected := new(WhisperFilterArgs)
 	expected.To = "0x34ag445g3455b34"
 	expected.Topics = [][]string{[]string{"0x68656c6c6f20776f726c64"}}
@@ -1960,27 +1077,17 @@ func TestWhisperFilterArgs(t *testing.T) {
 }
 
 func TestWhisperFilterArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(WhisperFilterArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestWhisperFilterArgsEmpty(t *testing.T) {
 	input := `[]`
 
 	args := new(WhisperFilterArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), args))
+	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
 	if len(str) > 0 {
 		t.Error(str)
 	}
 }
 
 func TestWhisperFilterArgsToInt(t *testing.T) {
-	input := `[{"to": 2}]`
+	input := `{"to": 2}`
 
 	args := new(WhisperFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
@@ -1990,7 +1097,7 @@ func TestWhisperFilterArgsToInt(t *testing.T) {
 }
 
 func TestWhisperFilterArgsToBool(t *testing.T) {
-	input := `[{"topics": ["0x68656c6c6f20776f726c64"], "to": false}]`
+	input := `{"topics": ["0x68656c6c6f20776f726c64"], "to": false}`
 
 	args := new(WhisperFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
@@ -2000,7 +1107,7 @@ func TestWhisperFilterArgsToBool(t *testing.T) {
 }
 
 func TestWhisperFilterArgsToMissing(t *testing.T) {
-	input := `[{"topics": ["0x68656c6c6f20776f726c64"]}]`
+	input := `{"topics": ["0x68656c6c6f20776f726c64"]}`
 	expected := new(WhisperFilterArgs)
 	expected.To = ""
 
@@ -2015,7 +1122,7 @@ func TestWhisperFilterArgsToMissing(t *testing.T) {
 }
 
 func TestWhisperFilterArgsTopicInt(t *testing.T) {
-	input := `[{"topics": [6], "to": "0x34ag445g3455b34"}]`
+	input := `{"topics": [6], "to": "0x34ag445g3455b34"}`
 
 	args := new(WhisperFilterArgs)
 	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
@@ -2024,482 +1131,57 @@ func TestWhisperFilterArgsTopicInt(t *testing.T) {
 	}
 }
 
-func TestCompileArgs(t *testing.T) {
-	input := `["contract test { function multiply(uint a) returns(uint d) {   return a * 7;   } }"]`
-	expected := new(CompileArgs)
-	expected.Source = `contract test { function multiply(uint a) returns(uint d) {   return a * 7;   } }`
-
-	args := new(CompileArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Source != args.Source {
-		t.Errorf("Source shoud be %#v but is %#v", expected.Source, args.Source)
-	}
-}
-
-func TestCompileArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(CompileArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestCompileArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(CompileArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestCompileArgsBool(t *testing.T) {
-	input := `[false]`
-
-	args := new(CompileArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestFilterStringArgs(t *testing.T) {
-	input := `["pending"]`
-	expected := new(FilterStringArgs)
-	expected.Word = "pending"
-
-	args := new(FilterStringArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Word != args.Word {
-		t.Errorf("Word shoud be %#v but is %#v", expected.Word, args.Word)
-	}
-}
-
-func TestFilterStringEmptyArgs(t *testing.T) {
-	input := `[]`
-
-	args := new(FilterStringArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestFilterStringInvalidArgs(t *testing.T) {
-	input := `{}`
-
-	args := new(FilterStringArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestFilterStringWordInt(t *testing.T) {
-	input := `[7]`
-
-	args := new(FilterStringArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestFilterStringWordWrong(t *testing.T) {
-	input := `["foo"]`
-
-	args := new(FilterStringArgs)
-	str := ExpectValidationError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestWhisperIdentityArgs(t *testing.T) {
-	input := `["0xc931d93e97ab07fe42d923478ba2465f283"]`
-	expected := new(WhisperIdentityArgs)
-	expected.Identity = "0xc931d93e97ab07fe42d923478ba2465f283"
-
-	args := new(WhisperIdentityArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Identity != args.Identity {
-		t.Errorf("Identity shoud be %#v but is %#v", expected.Identity, args.Identity)
-	}
-}
-
-func TestWhisperIdentityArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(WhisperIdentityArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestWhisperIdentityArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(WhisperIdentityArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestWhisperIdentityArgsInt(t *testing.T) {
-	input := `[4]`
-
-	args := new(WhisperIdentityArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Errorf(str)
-	}
-}
-
-func TestBlockNumArgs(t *testing.T) {
-	input := `["0x29a"]`
-	expected := new(BlockNumIndexArgs)
-	expected.BlockNumber = 666
-
-	args := new(BlockNumArg)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestBlockNumArgsWord(t *testing.T) {
-	input := `["pending"]`
-	expected := new(BlockNumIndexArgs)
-	expected.BlockNumber = -2
-
-	args := new(BlockNumArg)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-}
-
-func TestBlockNumArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(BlockNumArg)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestBlockNumArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(BlockNumArg)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestBlockNumArgsBool(t *testing.T) {
-	input := `[true]`
-
-	args := new(BlockNumArg)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
+func TestBlockHeightFromJsonInvalid(t *testing.T) {
+	var num int64
+	var msg json.RawMessage = []byte(`}{`)
+	str := ExpectDecodeParamError(blockHeightFromJson(msg, &num))
 	if len(str) > 0 {
 		t.Error(str)
 	}
 }
 
-func TestBlockNumIndexArgs(t *testing.T) {
-	input := `["0x29a", "0x0"]`
-	expected := new(BlockNumIndexArgs)
-	expected.BlockNumber = 666
-	expected.Index = 0
-
-	args := new(BlockNumIndexArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-
-	if expected.Index != args.Index {
-		t.Errorf("Index shoud be %#v but is %#v", expected.Index, args.Index)
-	}
-}
-
-func TestBlockNumIndexArgsWord(t *testing.T) {
-	input := `["latest", 67]`
-	expected := new(BlockNumIndexArgs)
-	expected.BlockNumber = -1
-	expected.Index = 67
+func TestSubscribeArgs(t *testing.T) {
+	input := `["logs", {"address": "0xd5f1812548be429cbdc6376b29611fc49e06f135", "topics": [null, ["0x01", "0x02"]]}]`
 
-	args := new(BlockNumIndexArgs)
+	args := new(SubscribeArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.BlockNumber != args.BlockNumber {
-		t.Errorf("BlockNumber shoud be %#v but is %#v", expected.BlockNumber, args.BlockNumber)
-	}
-
-	if expected.Index != args.Index {
-		t.Errorf("Index shoud be %#v but is %#v", expected.Index, args.Index)
-	}
-}
-
-func TestBlockNumIndexArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(BlockNumIndexArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestBlockNumIndexArgsInvalid(t *testing.T) {
-	input := `"foo"`
-
-	args := new(BlockNumIndexArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
+		t.Fatal(err)
 	}
-}
-
-func TestBlockNumIndexArgsBlocknumInvalid(t *testing.T) {
-	input := `[{}, "0x1"]`
-
-	args := new(BlockNumIndexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestBlockNumIndexArgsIndexInvalid(t *testing.T) {
-	input := `["0x29a", true]`
-
-	args := new(BlockNumIndexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestHashIndexArgs(t *testing.T) {
-	input := `["0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b", "0x1"]`
-	expected := new(HashIndexArgs)
-	expected.Hash = "0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"
-	expected.Index = 1
-
-	args := new(HashIndexArgs)
-	if err := json.Unmarshal([]byte(input), &args); err != nil {
+	if err := args.requirements(); err != nil {
 		t.Error(err)
 	}
 
-	if expected.Hash != args.Hash {
-		t.Errorf("Hash shoud be %#v but is %#v", expected.Hash, args.Hash)
-	}
-
-	if expected.Index != args.Index {
-		t.Errorf("Index shoud be %#v but is %#v", expected.Index, args.Index)
-	}
-}
-
-func TestHashIndexArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(HashIndexArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestHashIndexArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(HashIndexArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestHashIndexArgsInvalidHash(t *testing.T) {
-	input := `[7, "0x1"]`
-
-	args := new(HashIndexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
+	if args.Kind != LogsSubscription || len(args.Address) != 1 || args.Address[0] != "0xd5f1812548be429cbdc6376b29611fc49e06f135" {
+		t.Errorf("unexpected args %#v", args)
 	}
-}
-
-func TestHashIndexArgsInvalidIndex(t *testing.T) {
-	input := `["0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b", false]`
-
-	args := new(HashIndexArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
+	if len(args.Topics) != 2 || args.Topics[0][0] != "" || len(args.Topics[1]) != 2 {
+		t.Errorf("unexpected topics %#v", args.Topics)
 	}
 }
 
-func TestHashArgs(t *testing.T) {
-	input := `["0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"]`
-	expected := new(HashIndexArgs)
-	expected.Hash = "0xc6ef2fc5426d6ad6fd9e2a26abeab0aa2411b7ab17f30a99d3cb96aed1d1055b"
+func TestSubscribeArgsUnknownKind(t *testing.T) {
+	input := `["blocks"]`
 
-	args := new(HashArgs)
+	args := new(SubscribeArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Hash != args.Hash {
-		t.Errorf("Hash shoud be %#v but is %#v", expected.Hash, args.Hash)
-	}
-}
-
-func TestHashArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(HashArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
+		t.Fatal(err)
 	}
-}
 
-func TestHashArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(HashArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), &args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestHashArgsInvalidHash(t *testing.T) {
-	input := `[7]`
-
-	args := new(HashArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), &args))
+	str := ExpectValidationError(args.requirements())
 	if len(str) > 0 {
 		t.Error(str)
 	}
 }
 
-func TestSubmitWorkArgs(t *testing.T) {
-	input := `["0x0000000000000001", "0x1234567890abcdef1234567890abcdef", "0xD1GE5700000000000000000000000000"]`
-	expected := new(SubmitWorkArgs)
-	expected.Nonce = 1
-	expected.Header = "0x1234567890abcdef1234567890abcdef"
-	expected.Digest = "0xD1GE5700000000000000000000000000"
+func TestSubscribeArgsCriteria(t *testing.T) {
+	input := `["newHeads", {"address": "0xd5f1812548be429cbdc6376b29611fc49e06f135"}]`
 
-	args := new(SubmitWorkArgs)
+	args := new(SubscribeArgs)
 	if err := json.Unmarshal([]byte(input), &args); err != nil {
-		t.Error(err)
-	}
-
-	if expected.Nonce != args.Nonce {
-		t.Errorf("Nonce shoud be %d but is %d", expected.Nonce, args.Nonce)
-	}
-
-	if expected.Header != args.Header {
-		t.Errorf("Header shoud be %#v but is %#v", expected.Header, args.Header)
-	}
-
-	if expected.Digest != args.Digest {
-		t.Errorf("Digest shoud be %#v but is %#v", expected.Digest, args.Digest)
-	}
-}
-
-func TestSubmitWorkArgsInvalid(t *testing.T) {
-	input := `{}`
-
-	args := new(SubmitWorkArgs)
-	str := ExpectDecodeParamError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
+		t.Fatal(err)
 	}
-}
-
-func TestSubmitWorkArgsEmpty(t *testing.T) {
-	input := `[]`
 
-	args := new(SubmitWorkArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestSubmitWorkArgsNonceInt(t *testing.T) {
-	input := `[1, "0x1234567890abcdef1234567890abcdef", "0xD1GE5700000000000000000000000000"]`
-
-	args := new(SubmitWorkArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestSubmitWorkArgsHeaderInt(t *testing.T) {
-	input := `["0x0000000000000001", 1, "0xD1GE5700000000000000000000000000"]`
-
-	args := new(SubmitWorkArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-func TestSubmitWorkArgsDigestInt(t *testing.T) {
-	input := `["0x0000000000000001", "0x1234567890abcdef1234567890abcdef", 1]`
-
-	args := new(SubmitWorkArgs)
-	str := ExpectInvalidTypeError(json.Unmarshal([]byte(input), args))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestBlockHeightFromJsonInvalid(t *testing.T) {
-	var num int64
-	var msg json.RawMessage = []byte(`}{`)
-	str := ExpectDecodeParamError(blockHeightFromJson(msg, &num))
-	if len(str) > 0 {
-		t.Error(str)
-	}
-}
-
-func TestSourceArgsEmpty(t *testing.T) {
-	input := `[]`
-
-	args := new(SourceArgs)
-	str := ExpectInsufficientParamsError(json.Unmarshal([]byte(input), &args))
+	str := ExpectValidationError(args.requirements())
 	if len(str) > 0 {
 		t.Error(str)
 	}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Client sends JSON-RPC messages to a remote node.
+type Client interface {
+	// Send delivers a request or batch of requests and returns the response.
+	Send(msg []byte) ([]byte, error)
+	Close() error
+}
+
+// NewClient connects to endpoint, an http:// or https:// URL or the path of
+// an IPC socket, optionally prefixed with ipc:.
+func NewClient(endpoint string) (Client, error) {
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return &httpClient{url: endpoint}, nil
+	}
+	conn, err := net.Dial("unix", strings.TrimPrefix(endpoint, "ipc:"))
+	if err != nil {
+		return nil, err
+	}
+	return &ipcClient{conn: conn, dec: json.NewDecoder(conn)}, nil
+}
+
+type ipcClient struct {
+	mu   sync.Mutex
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+func (self *ipcClient) Send(msg []byte) ([]byte, error) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if _, err := self.conn.Write(msg); err != nil {
+		return nil, err
+	}
+	var res json.RawMessage
+	if err := self.dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (self *ipcClient) Close() error {
+	return self.conn.Close()
+}
+
+type httpClient struct {
+	url string
+}
+
+func (self *httpClient) Send(msg []byte) ([]byte, error) {
+	res, err := http.Post(self.url, "application/json", bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", self.url, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (self *httpClient) Close() error {
+	return nil
+}
+
+// Call sends a single request for method and decodes its result into
+// result, which may be nil.
+func Call(client Client, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	msg, err := json.Marshal(&RpcRequest{Id: 1, Jsonrpc: jsonrpcver, Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+	data, err := client.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RpcErrorObject `json:"error"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s (code %d)", res.Error.Message, res.Error.Code)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Result, result)
+}
@@ -110,12 +110,26 @@ func JSONRPC(pipe *xeth.XEth) http.Handler {
 	})
 }
 
-func RpcResponse(api *EthereumApi, request *RpcRequest) *interface{} {
+// RpcHandler computes the replies of JSON-RPC requests.
+type RpcHandler interface {
+	GetRequestReply(req *RpcRequest, reply *interface{}) error
+}
+
+func RpcResponse(api RpcHandler, request *RpcRequest) *interface{} {
 	var reply, response interface{}
-	reserr := api.GetRequestReply(request, &reply)
+	if reserr := api.GetRequestReply(request, &reply); reserr != nil {
+		return rpcErrorResponse(request, reserr)
+	}
+	response = &RpcSuccessResponse{Jsonrpc: jsonrpcver, Id: request.Id, Result: reply}
+
+	glog.V(logger.Detail).Infof("Generated response: %T %s", response, response)
+	return &response
+}
+
+// rpcErrorResponse maps reserr to the error response of request.
+func rpcErrorResponse(request *RpcRequest, reserr error) *interface{} {
+	var response interface{}
 	switch reserr.(type) {
-	case nil:
-		response = &RpcSuccessResponse{Jsonrpc: jsonrpcver, Id: request.Id, Result: reply}
 	case *NotImplementedError, *NotAvailableError:
 		jsonerr := &RpcErrorObject{-32601, reserr.Error()}
 		response = &RpcErrorResponse{Jsonrpc: jsonrpcver, Id: request.Id, Error: jsonerr}
@@ -0,0 +1,178 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/xeth"
+)
+
+var ipclistener *ipcListener
+
+type ipcListener struct {
+	net.Listener
+	endpoint string
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// StartIPC serves the JSON-RPC API including the admin and miner methods on
+// a unix socket which only the owner of the process can connect to.
+func StartIPC(ethereum *eth.Ethereum, config IpcConfig) error {
+	if ipclistener != nil {
+		if config.Endpoint != ipclistener.endpoint {
+			return fmt.Errorf("IPC service already running on %s", ipclistener.endpoint)
+		}
+		return nil // IPC service already running on given endpoint
+	}
+
+	l, err := listenIPC(config.Endpoint)
+	if err != nil {
+		glog.V(logger.Error).Infof("Can't listen on %s: %v", config.Endpoint, err)
+		return err
+	}
+	ipclistener = &ipcListener{Listener: l, endpoint: config.Endpoint, conns: make(map[net.Conn]struct{})}
+
+	go ipclistener.serve(NewAdminApi(ethereum, xeth.New(ethereum, nil)))
+
+	return nil
+}
+
+func StopIPC() error {
+	if ipclistener != nil {
+		ipclistener.stop()
+		ipclistener = nil
+	}
+
+	return nil
+}
+
+// listenIPC creates the socket at endpoint, replacing a socket left behind by
+// a process which didn't shut down cleanly. The socket is created in a private
+// directory and only moved to endpoint once its permissions are restricted, so
+// nobody but the owner can ever connect to it.
+func listenIPC(endpoint string) (net.Listener, error) {
+	if fi, err := os.Stat(endpoint); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", endpoint)
+		}
+		if conn, err := net.Dial("unix", endpoint); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", endpoint)
+		}
+		os.Remove(endpoint)
+	}
+
+	dir, err := ioutil.TempDir(filepath.Dir(endpoint), ".ipc")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0600); err == nil {
+		err = os.Rename(path, endpoint)
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &ipcSocket{l, endpoint}, nil
+}
+
+// ipcSocket removes the socket file on Close, the listener itself only knows
+// the path the socket was created at.
+type ipcSocket struct {
+	net.Listener
+	endpoint string
+}
+
+func (self *ipcSocket) Close() error {
+	err := self.Listener.Close()
+	os.Remove(self.endpoint)
+	return err
+}
+
+func (self *ipcListener) serve(api RpcHandler) {
+	for {
+		conn, err := self.Accept()
+		if err != nil {
+			glog.V(logger.Detail).Infof("IPC listener stopped: %v\n", err)
+			return
+		}
+		self.mu.Lock()
+		self.conns[conn] = struct{}{}
+		self.mu.Unlock()
+
+		go func() {
+			ServeConn(api, conn)
+
+			self.mu.Lock()
+			delete(self.conns, conn)
+			self.mu.Unlock()
+		}()
+	}
+}
+
+func (self *ipcListener) stop() {
+	self.Close()
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	for conn := range self.conns {
+		conn.Close()
+	}
+}
+
+// ServeConn answers the requests read from conn, single or batched JSON-RPC
+// requests following each other on the stream, until it is closed.
+func ServeConn(api RpcHandler, conn io.ReadWriteCloser) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var msg json.RawMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err != io.EOF {
+				glog.V(logger.Debug).Infof("IPC read: %v\n", err)
+			}
+			return
+		}
+
+		var response interface{}
+		var reqSingle RpcRequest
+		var reqBatch []RpcRequest
+		if err := json.Unmarshal(msg, &reqSingle); err == nil {
+			response = RpcResponse(api, &reqSingle)
+		} else if err := json.Unmarshal(msg, &reqBatch); err == nil {
+			resBatch := make([]*interface{}, len(reqBatch))
+			for i := range reqBatch {
+				resBatch[i] = RpcResponse(api, &reqBatch[i])
+			}
+			response = resBatch
+		} else {
+			jsonerr := &RpcErrorObject{-32600, "Could not decode request"}
+			response = &RpcErrorResponse{Jsonrpc: jsonrpcver, Id: nil, Error: jsonerr}
+		}
+
+		if err := enc.Encode(response); err != nil {
+			glog.V(logger.Debug).Infof("IPC write: %v\n", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sha3Hello = "0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad"
+
+func TestIPC(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "rpc-ipc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	endpoint := filepath.Join(tmp, "test.ipc")
+
+	// a socket left behind is replaced
+	stale, err := net.Listen("unix", endpoint+".old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(endpoint+".old", endpoint); err != nil {
+		t.Fatal(err)
+	}
+	stale.Close()
+
+	l, err := listenIPC(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipc := &ipcListener{Listener: l, endpoint: endpoint, conns: make(map[net.Conn]struct{})}
+	go ipc.serve(NewEthereumApi(nil))
+	defer ipc.stop()
+
+	fi, err := os.Stat(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions mismatch: have %v, want %v", perm, os.FileMode(0600))
+	}
+	if files, _ := ioutil.ReadDir(tmp); len(files) != 1 {
+		t.Errorf("expected only the socket in %s, found %d files", tmp, len(files))
+	}
+	if _, err := listenIPC(endpoint); err == nil {
+		t.Errorf("listening on a socket in use succeeded")
+	}
+
+	client, err := NewClient("ipc:" + endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// requests following each other on the connection
+	for i := 0; i < 2; i++ {
+		var result string
+		if err := Call(client, "web3_sha3", &result, "0x68656c6c6f20776f726c64"); err != nil {
+			t.Fatal(err)
+		}
+		if result != sha3Hello {
+			t.Errorf("result mismatch: have %s, want %s", result, sha3Hello)
+		}
+	}
+	if err := Call(client, "eth_unknown", nil); err == nil {
+		t.Errorf("expected error for unknown method")
+	}
+
+	res, err := client.Send([]byte(`[{"jsonrpc":"2.0","method":"web3_sha3","params":["0x68656c6c6f20776f726c64"],"id":1},{"jsonrpc":"2.0","method":"web3_sha3","params":[],"id":2}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"id":1,"jsonrpc":"2.0","result":"` + sha3Hello + `"},{"id":2,"jsonrpc":"2.0","error":{"code":-32602,"message":"insufficient params, want 1 have 0"}}]`
+	if string(res) != want {
+		t.Errorf("batch response mismatch:\nhave %s\nwant %s", res, want)
+	}
+
+	ipc.stop()
+	if _, err := os.Stat(endpoint); !os.IsNotExist(err) {
+		t.Errorf("socket not removed on stop: %v", err)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(JSONRPC(nil))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	if err := Call(client, "web3_sha3", &result, "0x68656c6c6f20776f726c64"); err != nil {
+		t.Fatal(err)
+	}
+	if result != sha3Hello {
+		t.Errorf("result mismatch: have %s, want %s", result, sha3Hello)
+	}
+}
@@ -11,10 +11,16 @@ type Jeth struct {
 	ethApi *EthereumApi
 	toVal  func(interface{}) otto.Value
 	re     *jsre.JSRE
+	client Client // requests are forwarded to a remote node if set
 }
 
 func NewJeth(ethApi *EthereumApi, toVal func(interface{}) otto.Value, re *jsre.JSRE) *Jeth {
-	return &Jeth{ethApi, toVal, re}
+	return &Jeth{ethApi: ethApi, toVal: toVal, re: re}
+}
+
+// NewRemoteJeth returns a web3 provider sending the requests to client.
+func NewRemoteJeth(client Client, re *jsre.JSRE) *Jeth {
+	return &Jeth{re: re, client: client}
 }
 
 func (self *Jeth) err(call otto.FunctionCall, code int, msg string, id interface{}) (response otto.Value) {
@@ -35,6 +41,9 @@ func (self *Jeth) Send(call otto.FunctionCall) (response otto.Value) {
 	}
 
 	jsonreq, err := json.Marshal(reqif)
+	if self.client != nil {
+		return self.sendRemote(call, jsonreq)
+	}
 	var reqs []RpcRequest
 	batch := true
 	err = json.Unmarshal(jsonreq, &reqs)
@@ -81,3 +90,26 @@ func (self *Jeth) Send(call otto.FunctionCall) (response otto.Value) {
 
 	return
 }
+
+func (self *Jeth) sendRemote(call otto.FunctionCall, jsonreq []byte) (response otto.Value) {
+	res, err := self.client.Send(jsonreq)
+	if err != nil {
+		return self.err(call, -32603, err.Error(), nil)
+	}
+	call.Otto.Set("ret_result", string(res))
+	response, err = call.Otto.Run("ret_response = JSON.parse(ret_result);")
+	if err != nil {
+		return self.err(call, -32700, err.Error(), nil)
+	}
+
+	if call.Argument(1).IsObject() {
+		call.Otto.Set("callback", call.Argument(1))
+		call.Otto.Run(`
+	    if (Object.prototype.toString.call(callback) == '[object Function]') {
+			callback(null, ret_response);
+		}
+		`)
+	}
+
+	return
+}
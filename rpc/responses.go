@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/xeth"
 )
 
 type BlockRes struct {
@@ -267,6 +269,68 @@ func NewUncleRes(h *types.Header) *UncleRes {
 // 	WorkProved string `json:"workProved"`
 // }
 
+type BadBlockRes struct {
+	Hash   *hexdata `json:"hash"`
+	Number *hexnum  `json:"number"`
+	Rlp    *hexdata `json:"rlp"`
+	Error  string   `json:"error"`
+	Peer   string   `json:"peer"`
+	Time   *hexnum  `json:"time"`
+}
+
+func NewBadBlocksRes(blocks []*core.BadBlock) []BadBlockRes {
+	res := make([]BadBlockRes, len(blocks))
+	for i, block := range blocks {
+		res[i] = BadBlockRes{
+			Hash:   newHexData(block.Hash),
+			Number: newHexNum(block.Number),
+			Rlp:    newHexData(block.RLP),
+			Error:  block.Error,
+			Peer:   block.Peer,
+			Time:   newHexNum(block.Time.Unix()),
+		}
+	}
+	return res
+}
+
+type AddressTxsRes struct {
+	Total        *hexnum           `json:"total"`
+	Transactions []*TransactionRes `json:"transactions"`
+}
+
+// NewAddressTxsRes describes a page of the transactions of an address, each
+// transaction being included in the block with the same index.
+func NewAddressTxsRes(txs types.Transactions, blocks []*types.Block, total uint64) *AddressTxsRes {
+	res := &AddressTxsRes{Total: newHexNum(total), Transactions: make([]*TransactionRes, len(txs))}
+	for i, tx := range txs {
+		v := NewTransactionRes(tx)
+		v.BlockHash = newHexData(blocks[i].Hash())
+		v.BlockNumber = newHexNum(blocks[i].Number())
+		for j, btx := range blocks[i].Transactions() {
+			if btx.Hash() == tx.Hash() {
+				v.TxIndex = newHexNum(j)
+				break
+			}
+		}
+		res.Transactions[i] = v
+	}
+	return res
+}
+
+type SyncStatusRes struct {
+	CurrentBlock  *hexnum `json:"currentBlock"`
+	PendingHashes *hexnum `json:"pendingHashes"`
+	QueuedBlocks  *hexnum `json:"queuedBlocks"`
+}
+
+func NewSyncStatusRes(status *xeth.SyncStatus) *SyncStatusRes {
+	return &SyncStatusRes{
+		CurrentBlock:  newHexNum(status.CurrentBlock),
+		PendingHashes: newHexNum(status.PendingHashes),
+		QueuedBlocks:  newHexNum(status.QueuedBlocks),
+	}
+}
+
 type LogRes struct {
 	Address          *hexdata   `json:"address"`
 	Topics           []*hexdata `json:"topics"`
@@ -276,6 +340,7 @@ type LogRes struct {
 	BlockHash        *hexdata   `json:"blockHash"`
 	TransactionHash  *hexdata   `json:"transactionHash"`
 	TransactionIndex *hexnum    `json:"transactionIndex"`
+	Removed          bool       `json:"removed"`
 }
 
 func NewLogRes(log *state.Log) LogRes {
@@ -291,6 +356,7 @@ func NewLogRes(log *state.Log) LogRes {
 	l.TransactionHash = newHexData(log.TxHash)
 	l.TransactionIndex = newHexNum(log.TxIndex)
 	l.BlockHash = newHexData(log.BlockHash)
+	l.Removed = log.Removed()
 
 	return l
 }
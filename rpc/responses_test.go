@@ -202,6 +202,7 @@ func TestNewLogRes(t *testing.T) {
 		// "blockHash":        reHash,
 		// "transactionHash":  reHash,
 		"transactionIndex": reNum,
+		"removed":          "false",
 	}
 
 	v := NewLogRes(log)
@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+)
+
+// Server dispatches JSON-RPC requests to the exported methods of the services
+// registered with it. The method fooBar of the service registered under the
+// namespace ns is served as ns_fooBar.
+//
+// Parameters are decoded from the positional params array into the argument
+// types of the method. Trailing pointer arguments are optional and nil when
+// omitted. Arguments having a requirements method are validated by it before
+// the call. Methods return a result, an error, or a result and an error.
+type Server struct {
+	services map[string]*service
+}
+
+type service struct {
+	name     string
+	receiver reflect.Value
+	methods  map[string]*method
+}
+
+type method struct {
+	fn       reflect.Value
+	args     []reflect.Type
+	required int // number of leading arguments which must be given
+	result   bool
+	err      bool
+}
+
+// requirer is implemented by parameters which validate themselves after
+// decoding.
+type requirer interface {
+	requirements() error
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func NewServer() *Server {
+	server := &Server{services: make(map[string]*service)}
+	server.RegisterName("rpc", &rpcService{server})
+	return server
+}
+
+// RegisterName exposes the suitable exported methods of receiver under the
+// given namespace, replacing any service previously registered under it.
+func (self *Server) RegisterName(name string, receiver interface{}) error {
+	if name == "" || strings.Contains(name, "_") {
+		return fmt.Errorf("invalid service name %q", name)
+	}
+	rcvr := reflect.ValueOf(receiver)
+	if !rcvr.IsValid() {
+		return fmt.Errorf("no receiver for service %q", name)
+	}
+
+	svc := &service{name: name, receiver: rcvr, methods: make(map[string]*method)}
+	typ := rcvr.Type()
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if m.PkgPath != "" {
+			continue // unexported
+		}
+		if callback := newMethod(rcvr.Method(i)); callback != nil {
+			svc.methods[lowerFirst(m.Name)] = callback
+		}
+	}
+	if len(svc.methods) == 0 {
+		return fmt.Errorf("service %q has no suitable methods", name)
+	}
+	self.services[name] = svc
+	return nil
+}
+
+// newMethod returns the callback for fn, nil if its results aren't supported.
+func newMethod(fn reflect.Value) *method {
+	typ := fn.Type()
+	m := &method{fn: fn, args: make([]reflect.Type, typ.NumIn())}
+	for i := range m.args {
+		m.args[i] = typ.In(i)
+		if m.args[i].Kind() != reflect.Ptr {
+			m.required = i + 1
+		}
+	}
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		m.err = typ.Out(0) == errorType
+		m.result = !m.err
+	case 2:
+		if typ.Out(1) != errorType {
+			return nil
+		}
+		m.result, m.err = true, true
+	default:
+		return nil
+	}
+	return m
+}
+
+// Modules returns the registered namespaces and their versions.
+func (self *Server) Modules() map[string]string {
+	modules := make(map[string]string, len(self.services))
+	for name := range self.services {
+		modules[name] = "1.0"
+	}
+	return modules
+}
+
+// Methods returns the sorted names of all methods served.
+func (self *Server) Methods() []string {
+	var methods []string
+	for _, svc := range self.services {
+		for name := range svc.methods {
+			methods = append(methods, svc.name+"_"+name)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (self *Server) GetRequestReply(req *RpcRequest, reply *interface{}) error {
+	glog.V(logger.Debug).Infof("%s %s", req.Method, req.Params)
+
+	callback := self.method(req.Method)
+	if callback == nil {
+		return NewNotImplementedError(req.Method)
+	}
+	args, err := callback.decode(req.Params)
+	if err != nil {
+		return err
+	}
+	if err := callback.call(req.Method, args, reply); err != nil {
+		return err
+	}
+
+	glog.V(logger.Detail).Infof("Reply: %T %s\n", reply, reply)
+	return nil
+}
+
+func (self *Server) method(name string) *method {
+	parts := strings.SplitN(name, "_", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	svc := self.services[parts[0]]
+	if svc == nil {
+		return nil
+	}
+	return svc.methods[parts[1]]
+}
+
+// decode parses the positional parameters of a request into the arguments of
+// the method. Parameters beyond those the method takes are ignored.
+func (self *method) decode(params json.RawMessage) ([]reflect.Value, error) {
+	var raw []json.RawMessage
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &raw); err != nil {
+			return nil, NewDecodeParamError(err.Error())
+		}
+	}
+	if len(raw) < self.required {
+		return nil, NewInsufficientParamsError(len(raw), self.required)
+	}
+
+	args := make([]reflect.Value, len(self.args))
+	for i, typ := range self.args {
+		arg := reflect.New(typ)
+		if i < len(raw) {
+			if err := json.Unmarshal(raw[i], arg.Interface()); err != nil {
+				return nil, paramError(i, err)
+			}
+		}
+		args[i] = arg.Elem()
+
+		// optional arguments are only validated if given
+		v := arg
+		if typ.Kind() == reflect.Ptr {
+			if v = arg.Elem(); v.IsNil() {
+				continue
+			}
+		}
+		if r, ok := v.Interface().(requirer); ok {
+			if err := r.requirements(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return args, nil
+}
+
+// paramError attributes an error of the JSON decoder to parameter i. The
+// errors of parameter types are returned unchanged.
+func paramError(i int, err error) error {
+	switch err.(type) {
+	case *DecodeParamError, *InsufficientParamsError, *ValidationError, *InvalidTypeError:
+		return err
+	case *json.UnmarshalTypeError:
+		return NewInvalidTypeError(fmt.Sprintf("params[%d]", i), err.Error())
+	}
+	return NewDecodeParamError(fmt.Sprintf("params[%d]: %v", i, err))
+}
+
+// call invokes the method, turning a panic of it into an error.
+func (self *method) call(name string, args []reflect.Value, reply *interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			glog.V(logger.Error).Infof("%s crashed: %v\n", name, r)
+			err = fmt.Errorf("%s failed: %v", name, r)
+		}
+	}()
+
+	out := self.fn.Call(args)
+	if self.err && !out[len(out)-1].IsNil() {
+		return out[len(out)-1].Interface().(error)
+	}
+	if self.result {
+		*reply = out[0].Interface()
+	} else {
+		*reply = nil
+	}
+	return nil
+}
+
+func lowerFirst(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[n:]
+}
+
+// rpcService serves the introspection methods of the server.
+type rpcService struct {
+	server *Server
+}
+
+// Modules returns the namespaces served and their versions.
+func (self *rpcService) Modules() map[string]string {
+	return self.server.Modules()
+}
@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testService struct{}
+
+type echoResult struct {
+	Str   string
+	Num   int64
+	Block int64
+}
+
+type positiveArgs struct {
+	Value int
+}
+
+func (args *positiveArgs) requirements() error {
+	if args.Value <= 0 {
+		return NewValidationError("value", "must be positive")
+	}
+	return nil
+}
+
+func (self *testService) Echo(str string, num Number, block *BlockNumber) echoResult {
+	return echoResult{str, int64(num), blockNumber(block)}
+}
+
+func (self *testService) Positive(args positiveArgs) int {
+	return args.Value
+}
+
+func (self *testService) OptionalPositive(args *positiveArgs) bool {
+	return args == nil
+}
+
+func (self *testService) Fail() error {
+	return errors.New("failed")
+}
+
+func (self *testService) Crash() (int, error) {
+	panic("crashed")
+}
+
+func (self *testService) NoResult() {}
+
+func (self *testService) TooManyResults() (int, int, error) {
+	return 0, 0, nil
+}
+
+func (self *testService) NoError() (int, int) {
+	return 0, 0
+}
+
+func (self *testService) unexported() {}
+
+func newTestServer(t *testing.T) *Server {
+	server := NewServer()
+	if err := server.RegisterName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func testCall(server *Server, method, params string) (interface{}, error) {
+	req := &RpcRequest{Id: 1, Jsonrpc: jsonrpcver, Method: method, Params: json.RawMessage(params)}
+	var reply interface{}
+	err := server.GetRequestReply(req, &reply)
+	return reply, err
+}
+
+func TestServerRegisterName(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("", new(testService)); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := server.RegisterName("te_st", new(testService)); err == nil {
+		t.Error("expected error for name containing _")
+	}
+	if err := server.RegisterName("test", struct{}{}); err == nil {
+		t.Error("expected error for receiver without methods")
+	}
+	if err := server.RegisterName("test", nil); err == nil {
+		t.Error("expected error for nil receiver")
+	}
+}
+
+func TestServerMethods(t *testing.T) {
+	expected := []string{
+		"rpc_modules",
+		"test_crash",
+		"test_echo",
+		"test_fail",
+		"test_noResult",
+		"test_optionalPositive",
+		"test_positive",
+	}
+	if methods := newTestServer(t).Methods(); !reflect.DeepEqual(methods, expected) {
+		t.Errorf("methods mismatch:\ngot  %v\nwant %v", methods, expected)
+	}
+}
+
+func TestServerModules(t *testing.T) {
+	reply, err := testCall(newTestServer(t), "rpc_modules", `[]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"rpc": "1.0", "test": "1.0"}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Errorf("modules mismatch: got %v, want %v", reply, expected)
+	}
+}
+
+func TestServerCall(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		method, params string
+		expected       interface{}
+	}{
+		{"test_echo", `["foo", 4, "0x10"]`, echoResult{"foo", 4, 16}},
+		{"test_echo", `["foo", "0x4", "pending"]`, echoResult{"foo", 4, -2}},
+		{"test_echo", `["foo", "12"]`, echoResult{"foo", 12, -1}},
+		{"test_echo", `["foo", 12, null]`, echoResult{"foo", 12, -1}},
+		{"test_echo", `["foo", 1, 2, 3]`, echoResult{"foo", 1, 2}}, // extra params are ignored
+		{"test_positive", `[{"Value": 3}]`, 3},
+		{"test_optionalPositive", `[]`, true},
+		{"test_optionalPositive", `[null]`, true},
+		{"test_noResult", `[]`, nil},
+		{"test_noResult", ``, nil},
+	}
+	for _, test := range tests {
+		reply, err := testCall(server, test.method, test.params)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %v", test.method, test.params, err)
+			continue
+		}
+		if !reflect.DeepEqual(reply, test.expected) {
+			t.Errorf("%s %s: got %#v, want %#v", test.method, test.params, reply, test.expected)
+		}
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		method, params string
+		code           int
+	}{
+		{"test", `[]`, -32601},
+		{"test_unknown", `[]`, -32601},
+		{"unknown_echo", `[]`, -32601},
+		{"test_unexported", `[]`, -32601},
+		{"test_tooManyResults", `[]`, -32601},
+		{"test_noError", `[]`, -32601},
+		{"test_echo", `{"str": "foo"}`, -32602},
+		{"test_echo", `["foo"]`, -32602},
+		{"test_echo", `[1, 1]`, -32602},
+		{"test_echo", `["foo", true]`, -32602},
+		{"test_echo", `["foo", 1, "foo"]`, -32602},
+		{"test_positive", `[{"Value": 0}]`, -32602},
+		{"test_optionalPositive", `[{"Value": -1}]`, -32602},
+		{"test_fail", `[]`, -32603},
+		{"test_crash", `[]`, -32603},
+	}
+	for _, test := range tests {
+		req := &RpcRequest{Id: 1, Jsonrpc: jsonrpcver, Method: test.method, Params: json.RawMessage(test.params)}
+		res, ok := (*RpcResponse(server, req)).(*RpcErrorResponse)
+		if !ok {
+			t.Errorf("%s %s: expected error response", test.method, test.params)
+			continue
+		}
+		if res.Error.Code != test.code {
+			t.Errorf("%s %s: got code %d (%s), want %d", test.method, test.params, res.Error.Code, res.Error.Message, test.code)
+		}
+	}
+}
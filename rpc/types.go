@@ -163,6 +163,16 @@ type RpcConfig struct {
 	CorsDomain    string
 }
 
+type IpcConfig struct {
+	Endpoint string // path of the unix socket
+}
+
+type WSConfig struct {
+	ListenAddress string
+	ListenPort    uint
+	Origins       string // comma separated origins allowed to connect, * for any
+}
+
 type InvalidTypeError struct {
 	method string
 	msg    string
@@ -275,6 +285,19 @@ type RpcErrorResponse struct {
 	Error   *RpcErrorObject `json:"error"`
 }
 
+// RpcSubscriptionNotification pushes a result of a subscription made with
+// eth_subscribe.
+type RpcSubscriptionNotification struct {
+	Jsonrpc string                `json:"jsonrpc"`
+	Method  string                `json:"method"`
+	Params  RpcSubscriptionResult `json:"params"`
+}
+
+type RpcSubscriptionResult struct {
+	Subscription *hexnum     `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
 type RpcErrorObject struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -0,0 +1,344 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/logger"
+	"github.com/ethereum/go-ethereum/logger/glog"
+	"github.com/ethereum/go-ethereum/xeth"
+	"golang.org/x/net/websocket"
+)
+
+// wsQueueLength is the number of messages queued for a websocket client.
+// Clients falling further behind are disconnected.
+const wsQueueLength = 256
+
+var (
+	wslistener *stoppableTCPListener
+	wsserver   *wsServer
+)
+
+// StartWS serves the JSON-RPC API and push subscriptions over websockets.
+func StartWS(pipe *xeth.XEth, config WSConfig) error {
+	if wslistener != nil {
+		if fmt.Sprintf("%s:%d", config.ListenAddress, config.ListenPort) != wslistener.Addr().String() {
+			return fmt.Errorf("WebSocket RPC service already running on %s ", wslistener.Addr().String())
+		}
+		return nil // WebSocket RPC service already running on given host/port
+	}
+
+	l, err := newStoppableTCPListener(fmt.Sprintf("%s:%d", config.ListenAddress, config.ListenPort))
+	if err != nil {
+		glog.V(logger.Error).Infof("Can't listen on %s:%d: %v", config.ListenAddress, config.ListenPort, err)
+		return err
+	}
+	wslistener = l
+	wsserver = newWSServer(pipe, config.Origins)
+
+	go http.Serve(l, newStoppableHandler(wsserver, l.stop))
+
+	return nil
+}
+
+func StopWS() error {
+	if wslistener != nil {
+		wslistener.Stop()
+		wsserver.close()
+		wslistener, wsserver = nil, nil
+	}
+
+	return nil
+}
+
+// WebSocketRPC returns a handler that serves the Ethereum JSON-RPC API over
+// websockets, allowing browser requests from the comma separated origins.
+func WebSocketRPC(pipe *xeth.XEth, origins string) http.Handler {
+	return newWSServer(pipe, origins)
+}
+
+type wsServer struct {
+	api     *EthereumApi
+	origins []string
+
+	mu       sync.Mutex
+	sessions map[*wsSession]struct{}
+}
+
+func newWSServer(pipe *xeth.XEth, origins string) *wsServer {
+	server := &wsServer{
+		api:      NewEthereumApi(pipe),
+		sessions: make(map[*wsSession]struct{}),
+	}
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			server.origins = append(server.origins, origin)
+		}
+	}
+	return server
+}
+
+// allowed reports whether a handshake from origin is accepted. Requests
+// without origin don't come from browsers and are always allowed.
+func (self *wsServer) allowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range self.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *wsServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if origin := req.Header.Get("Origin"); !self.allowed(origin) {
+		glog.V(logger.Debug).Infof("websocket origin %s not allowed\n", origin)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	// the origin is checked above, the handshake accepts any
+	server := websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   self.serveSession,
+	}
+	server.ServeHTTP(w, req)
+}
+
+func (self *wsServer) serveSession(conn *websocket.Conn) {
+	session := newWSSession(self.api, conn)
+	self.mu.Lock()
+	self.sessions[session] = struct{}{}
+	self.mu.Unlock()
+
+	session.serve()
+
+	self.mu.Lock()
+	delete(self.sessions, session)
+	self.mu.Unlock()
+}
+
+func (self *wsServer) close() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for session := range self.sessions {
+		session.close()
+	}
+}
+
+// wsSession is a websocket client connection and its subscriptions.
+type wsSession struct {
+	api  *EthereumApi
+	conn *websocket.Conn
+
+	out  chan interface{} // messages to write
+	quit chan struct{}
+	once sync.Once
+
+	mu   sync.Mutex
+	subs map[int]bool // subscription ids, true once the client knows them
+}
+
+func newWSSession(api *EthereumApi, conn *websocket.Conn) *wsSession {
+	return &wsSession{
+		api:  api,
+		conn: conn,
+		out:  make(chan interface{}, wsQueueLength),
+		quit: make(chan struct{}),
+		subs: make(map[int]bool),
+	}
+}
+
+// serve handles the requests of the client until it disconnects.
+func (self *wsSession) serve() {
+	go self.writeLoop()
+	defer self.close()
+
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(self.conn, &msg); err != nil {
+			glog.V(logger.Detail).Infof("websocket read: %v\n", err)
+			return
+		}
+
+		// subscriptions only notify once their id is sent
+		var subs []int
+		var reqSingle RpcRequest
+		if err := json.Unmarshal(msg, &reqSingle); err == nil {
+			self.send(self.response(&reqSingle, &subs))
+			self.activate(subs)
+			continue
+		}
+		var reqBatch []RpcRequest
+		if err := json.Unmarshal(msg, &reqBatch); err == nil {
+			resBatch := make([]*interface{}, len(reqBatch))
+			for i := range reqBatch {
+				resBatch[i] = self.response(&reqBatch[i], &subs)
+			}
+			self.send(resBatch)
+			self.activate(subs)
+			continue
+		}
+		jsonerr := &RpcErrorObject{-32600, "Could not decode request"}
+		self.send(&RpcErrorResponse{Jsonrpc: jsonrpcver, Id: nil, Error: jsonerr})
+	}
+}
+
+func (self *wsSession) writeLoop() {
+	for {
+		select {
+		case msg := <-self.out:
+			payload, err := json.Marshal(msg)
+			if err != nil {
+				glog.V(logger.Error).Infoln("Error marshalling JSON", err)
+				continue
+			}
+			glog.V(logger.Detail).Infof("Sending payload: %s", payload)
+			if err := websocket.Message.Send(self.conn, string(payload)); err != nil {
+				self.close()
+				return
+			}
+		case <-self.quit:
+			return
+		}
+	}
+}
+
+// send queues a response, waiting for room in the queue.
+func (self *wsSession) send(msg interface{}) {
+	select {
+	case self.out <- msg:
+	case <-self.quit:
+	}
+}
+
+// notify queues a subscription notification. A client which doesn't keep up
+// with its notifications is disconnected.
+func (self *wsSession) notify(id int, result interface{}) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if !self.subs[id] {
+		return
+	}
+	msg := &RpcSubscriptionNotification{
+		Jsonrpc: jsonrpcver,
+		Method:  "eth_subscription",
+		Params:  RpcSubscriptionResult{Subscription: newHexNum(big.NewInt(int64(id)).Bytes()), Result: result},
+	}
+	select {
+	case self.out <- msg:
+	case <-self.quit:
+	default:
+		glog.V(logger.Info).Infoln("websocket client too slow, disconnecting")
+		go self.close()
+	}
+}
+
+func (self *wsSession) response(req *RpcRequest, subs *[]int) *interface{} {
+	var response interface{}
+	switch req.Method {
+	case "eth_subscribe":
+		args := new(SubscribeArgs)
+		if err := json.Unmarshal(req.Params, &args); err != nil {
+			return rpcErrorResponse(req, err)
+		}
+		if err := args.requirements(); err != nil {
+			return rpcErrorResponse(req, err)
+		}
+		id := self.subscribe(args)
+		self.mu.Lock()
+		select {
+		case <-self.quit:
+			self.api.xeth().Unsubscribe(id)
+		default:
+			self.subs[id] = false
+		}
+		self.mu.Unlock()
+		*subs = append(*subs, id)
+
+		response = &RpcSuccessResponse{Jsonrpc: jsonrpcver, Id: req.Id, Result: newHexNum(big.NewInt(int64(id)).Bytes())}
+		return &response
+
+	case "eth_unsubscribe":
+		args := new(FilterIdArgs)
+		if err := json.Unmarshal(req.Params, &args); err != nil {
+			return rpcErrorResponse(req, err)
+		}
+		self.mu.Lock()
+		_, ok := self.subs[args.Id]
+		delete(self.subs, args.Id)
+		self.mu.Unlock()
+		if ok {
+			self.api.xeth().Unsubscribe(args.Id)
+		}
+		response = &RpcSuccessResponse{Jsonrpc: jsonrpcver, Id: req.Id, Result: ok}
+		return &response
+	}
+	return RpcResponse(self.api, req)
+}
+
+// activate starts delivering the notifications of the given subscriptions.
+func (self *wsSession) activate(subs []int) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	for _, id := range subs {
+		if _, ok := self.subs[id]; ok {
+			self.subs[id] = true
+		}
+	}
+}
+
+func (self *wsSession) subscribe(args *SubscribeArgs) int {
+	pipe := self.api.xeth()
+	switch args.Kind {
+	case NewHeadsSubscription:
+		return pipe.SubscribeNewHeads(func(id int, block *types.Block) {
+			self.notify(id, NewBlockRes(block, false))
+		})
+	case LogsSubscription:
+		return pipe.SubscribeLogs(args.Address, args.Topics, func(id int, logs state.Logs) {
+			for _, log := range logs {
+				self.notify(id, NewLogRes(log))
+			}
+		})
+	case NewPendingTransactionsSubscription:
+		return pipe.SubscribePendingTransactions(func(id int, hash common.Hash) {
+			self.notify(id, newHexData(hash))
+		})
+	case SyncingSubscription:
+		return pipe.SubscribeSyncing(func(id int, status *xeth.SyncStatus) {
+			if status == nil {
+				self.notify(id, false)
+			} else {
+				self.notify(id, NewSyncStatusRes(status))
+			}
+		})
+	}
+	return 0
+}
+
+// close disconnects the client and stops its subscriptions.
+func (self *wsSession) close() {
+	self.once.Do(func() {
+		close(self.quit)
+		self.conn.Close()
+
+		self.mu.Lock()
+		for id := range self.subs {
+			self.api.xeth().Unsubscribe(id)
+		}
+		self.subs = make(map[int]bool)
+		self.mu.Unlock()
+	})
+}
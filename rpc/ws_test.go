@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// wsHandshake sends a websocket handshake with the given origin and returns
+// the response status.
+func wsHandshake(url, origin string) (int, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+
+	conn, err := net.Dial("tcp", req.URL.Host)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err := req.Write(conn); err != nil {
+		return 0, err
+	}
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return 0, err
+	}
+	return res.StatusCode, nil
+}
+
+func TestWSOrigins(t *testing.T) {
+	server := httptest.NewServer(WebSocketRPC(nil, "http://a.example, http://b.example"))
+	defer server.Close()
+
+	tests := []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{"http://b.example", http.StatusSwitchingProtocols},
+		{"http://c.example", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		status, err := wsHandshake(server.URL, test.origin)
+		if err != nil {
+			t.Fatalf("origin %q: %v", test.origin, err)
+		}
+		if status != test.status {
+			t.Errorf("origin %q: status mismatch: have %d, want %d", test.origin, status, test.status)
+		}
+	}
+}
+
+func TestWSRequests(t *testing.T) {
+	server := httptest.NewServer(WebSocketRPC(nil, "http://localhost"))
+	defer server.Close()
+
+	conn, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1), "", "http://localhost")
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		request string
+		result  string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"web3_sha3","params":["0x68656c6c6f20776f726c64"],"id":1}`,
+			`{"id":1,"jsonrpc":"2.0","result":"0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad"}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"eth_unsubscribe","params":["0x5"],"id":2}`,
+			`{"id":2,"jsonrpc":"2.0","result":false}`,
+		},
+		{
+			`[{"jsonrpc":"2.0","method":"eth_subscribe","params":["blocks"],"id":3}]`,
+			`[{"id":3,"jsonrpc":"2.0","error":{"code":-32602,"message":"subscription not valid, unknown kind \"blocks\""}}]`,
+		},
+	}
+	for _, test := range tests {
+		if err := websocket.Message.Send(conn, test.request); err != nil {
+			t.Fatal(err)
+		}
+		var msg []byte
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			t.Fatal(err)
+		}
+		var have, want interface{}
+		json.Unmarshal(msg, &have)
+		json.Unmarshal([]byte(test.result), &want)
+		haveJSON, _ := json.Marshal(have)
+		wantJSON, _ := json.Marshal(want)
+		if !bytes.Equal(haveJSON, wantJSON) {
+			t.Errorf("%s:\nhave %s\nwant %s", test.request, msg, test.result)
+		}
+	}
+}
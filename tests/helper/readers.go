@@ -9,7 +9,7 @@ import (
 	"testing"
 )
 
-func readJSON(t *testing.T, reader io.Reader, value interface{}) {
+func readJSON(t testing.TB, reader io.Reader, value interface{}) {
 	data, err := ioutil.ReadAll(reader)
 	err = json.Unmarshal(data, &value)
 	if err != nil {
@@ -17,7 +17,7 @@ func readJSON(t *testing.T, reader io.Reader, value interface{}) {
 	}
 }
 
-func CreateHttpTests(t *testing.T, uri string, value interface{}) {
+func CreateHttpTests(t testing.TB, uri string, value interface{}) {
 	resp, err := http.Get(uri)
 	if err != nil {
 		t.Error(err)
@@ -29,7 +29,7 @@ func CreateHttpTests(t *testing.T, uri string, value interface{}) {
 	readJSON(t, resp.Body, value)
 }
 
-func CreateFileTests(t *testing.T, fn string, value interface{}) {
+func CreateFileTests(t testing.TB, fn string, value interface{}) {
 	file, err := os.Open(fn)
 	if err != nil {
 		t.Error(err)
@@ -30,6 +30,8 @@ type Env struct {
 	logs state.Logs
 
 	vmTest bool
+
+	precompiled *vm.PrecompiledRegistry
 }
 
 func NewEnv(state *state.StateDB) *Env {
@@ -63,6 +65,10 @@ func (self *Env) Difficulty() *big.Int     { return self.difficulty }
 func (self *Env) State() *state.StateDB    { return self.state }
 func (self *Env) GasLimit() *big.Int       { return self.gasLimit }
 func (self *Env) VmType() vm.Type          { return vm.StdVmTy }
+func (self *Env) Profiler() *vm.Profiler   { return nil }
+func (self *Env) Precompiled() *vm.PrecompiledRegistry {
+	return self.precompiled
+}
 func (self *Env) GetHash(n uint64) common.Hash {
 	return common.BytesToHash(crypto.Sha3([]byte(big.NewInt(int64(n)).String())))
 }
@@ -142,12 +148,11 @@ func RunVm(state *state.StateDB, env, exec map[string]string) ([]byte, state.Log
 		price = common.Big(exec["gasPrice"])
 		value = common.Big(exec["value"])
 	)
-	// Reset the pre-compiled contracts for VM tests.
-	vm.Precompiled = make(map[string]*vm.PrecompiledAccount)
-
 	caller := state.GetOrNewStateObject(from)
 
 	vmenv := NewEnvFromMap(state, env, exec)
+	// VM tests run without pre-compiled contracts.
+	vmenv.precompiled = vm.NewPrecompiledRegistry()
 	vmenv.vmTest = true
 	vmenv.skipTransfer = true
 	vmenv.initial = true
@@ -171,10 +176,7 @@ func RunState(statedb *state.StateDB, env, tx map[string]string) ([]byte, state.
 		t := common.HexToAddress(tx["to"])
 		to = &t
 	}
-	// Set pre compiled contracts
-	vm.Precompiled = vm.PrecompiledContracts()
-
-	snapshot := statedb.Copy()
+	snapshot := statedb.Snapshot()
 	coinbase := statedb.GetOrNewStateObject(caddr)
 	coinbase.SetGasPool(common.Big(env["currentGasLimit"]))
 
@@ -183,7 +185,7 @@ func RunState(statedb *state.StateDB, env, tx map[string]string) ([]byte, state.
 	vmenv.origin = common.BytesToAddress(keyPair.Address())
 	ret, _, err := core.ApplyMessage(vmenv, message, coinbase)
 	if core.IsNonceErr(err) || core.IsInvalidTxErr(err) {
-		statedb.Set(snapshot)
+		statedb.RevertToSnapshot(snapshot)
 	}
 	statedb.Update()
 
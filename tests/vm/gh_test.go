@@ -244,8 +244,7 @@ func TestLogTest(t *testing.T) {
 }
 
 func TestPerformance(t *testing.T) {
-	t.Skip()
-	const fn = "../files/VMTests/vmPerformance.json"
+	const fn = "../files/VMTests/vmPerformanceTest.json"
 	RunVmTest(fn, t)
 }
 
@@ -351,3 +350,59 @@ func TestSolidity(t *testing.T) {
 	const fn = "../files/StateTests/stSolidityTest.json"
 	RunVmTest(fn, t)
 }
+
+func benchmarkVmTest(b *testing.B, fn, name string) {
+	tests := make(map[string]VmTest)
+	helper.CreateFileTests(b, fn, &tests)
+	test, ok := tests[name]
+	if !ok {
+		b.Fatalf("test %s not found in %s", name, fn)
+	}
+	env := map[string]string{
+		"currentCoinbase":   test.Env.CurrentCoinbase,
+		"currentDifficulty": test.Env.CurrentDifficulty,
+		"currentGasLimit":   test.Env.CurrentGasLimit,
+		"currentNumber":     test.Env.CurrentNumber,
+		"previousHash":      test.Env.PreviousHash,
+	}
+	if n, ok := test.Env.CurrentTimestamp.(float64); ok {
+		env["currentTimestamp"] = strconv.Itoa(int(n))
+	} else {
+		env["currentTimestamp"] = test.Env.CurrentTimestamp.(string)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db, _ := ethdb.NewMemDatabase()
+		statedb := state.New(common.Hash{}, db)
+		for addr, account := range test.Pre {
+			statedb.SetStateObject(StateObjectFromAccount(db, addr, account))
+		}
+		if len(test.Exec) > 0 {
+			helper.RunVm(statedb, env, test.Exec)
+		} else {
+			helper.RunState(statedb, env, test.Transaction)
+		}
+	}
+}
+
+const performanceTests = "../files/VMTests/vmPerformanceTest.json"
+
+func BenchmarkAckermann31(b *testing.B) { benchmarkVmTest(b, performanceTests, "ackermann31") }
+func BenchmarkAckermann32(b *testing.B) { benchmarkVmTest(b, performanceTests, "ackermann32") }
+func BenchmarkAckermann33(b *testing.B) { benchmarkVmTest(b, performanceTests, "ackermann33") }
+func BenchmarkFibonacci10(b *testing.B) { benchmarkVmTest(b, performanceTests, "fibonacci10") }
+func BenchmarkFibonacci16(b *testing.B) { benchmarkVmTest(b, performanceTests, "fibonacci16") }
+func BenchmarkManyFunctions100(b *testing.B) {
+	benchmarkVmTest(b, performanceTests, "manyFunctions100")
+}
+
+const quadraticComplexityTests = "../files/StateTests/stQuadraticComplexityTest.json"
+
+func BenchmarkCall50000(b *testing.B) { benchmarkVmTest(b, quadraticComplexityTests, "Call50000") }
+func BenchmarkCallcode50000(b *testing.B) {
+	benchmarkVmTest(b, quadraticComplexityTests, "Callcode50000")
+}
+func BenchmarkCall50000bytesContract50(b *testing.B) {
+	benchmarkVmTest(b, quadraticComplexityTests, "Call50000bytesContract50_1")
+}
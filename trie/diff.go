@@ -0,0 +1,91 @@
+package trie
+
+import "bytes"
+
+// DiffIterator walks two tries simultaneously and yields the keys whose
+// values differ between them. Subtrees which are referenced by the same hash
+// in both tries are skipped without being resolved.
+type DiffIterator struct {
+	a, b     *NodeIterator
+	aok, bok bool
+	Key      []byte
+	From, To []byte // Value in the first and second trie, nil if absent
+}
+
+func NewDiffIterator(a, b *Trie) *DiffIterator {
+	it := &DiffIterator{a: NewNodeIterator(a), b: NewNodeIterator(b)}
+	if !bytes.Equal(a.Hash(), b.Hash()) {
+		it.aok = it.a.next(true)
+		it.bok = it.b.next(true)
+	}
+
+	return it
+}
+
+func (self *DiffIterator) Next() bool {
+	for self.aok || self.bok {
+		var cmp int
+		switch {
+		case !self.aok:
+			cmp = 1
+		case !self.bok:
+			cmp = -1
+		default:
+			cmp = bytes.Compare(self.a.current().path, self.b.current().path)
+		}
+
+		switch {
+		case cmp < 0:
+			// Only present in the first trie
+			found := self.a.Leaf()
+			if found {
+				self.set(self.a.current(), nil)
+			}
+			self.aok = self.a.next(true)
+			if found {
+				return true
+			}
+		case cmp > 0:
+			// Only present in the second trie
+			found := self.b.Leaf()
+			if found {
+				self.set(nil, self.b.current())
+			}
+			self.bok = self.b.next(true)
+			if found {
+				return true
+			}
+		default:
+			an, bn := self.a.current(), self.b.current()
+			if an.hash != nil && bytes.Equal(an.hash, bn.hash) {
+				self.aok = self.a.next(false)
+				self.bok = self.b.next(false)
+				continue
+			}
+
+			found := self.a.Leaf() && self.b.Leaf() && !bytes.Equal(an.node.(*ValueNode).Val(), bn.node.(*ValueNode).Val())
+			if found {
+				self.set(an, bn)
+			}
+			self.aok = self.a.next(true)
+			self.bok = self.b.next(true)
+			if found {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (self *DiffIterator) set(a, b *nodeIteratorState) {
+	self.From, self.To = nil, nil
+	if a != nil {
+		self.Key = []byte(DecodeCompact(a.path))
+		self.From = a.node.(*ValueNode).Val()
+	}
+	if b != nil {
+		self.Key = []byte(DecodeCompact(b.path))
+		self.To = b.node.(*ValueNode).Val()
+	}
+}
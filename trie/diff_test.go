@@ -0,0 +1,49 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiffIterator(t *testing.T) {
+	db := make(Db)
+	a, b := New(nil, db), New(nil, db)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key%03d", i)
+		a.UpdateString(key, fmt.Sprintf("value%d", i))
+		b.UpdateString(key, fmt.Sprintf("value%d", i))
+	}
+	b.UpdateString("key010", "changed")
+	b.UpdateString("key150", "changed")
+	b.DeleteString("key020")
+	b.UpdateString("key999", "added")
+	a.Commit()
+	b.Commit()
+
+	// Reload from the database so that shared subtrees are hash references.
+	a, b = New(a.Root(), db), New(b.Root(), db)
+
+	exp := map[string][2]string{
+		"key010": {"value10", "changed"},
+		"key150": {"value150", "changed"},
+		"key020": {"value20", ""},
+		"key999": {"", "added"},
+	}
+	found := make(map[string][2]string)
+	it := NewDiffIterator(a, b)
+	for it.Next() {
+		found[string(it.Key)] = [2]string{string(it.From), string(it.To)}
+	}
+	if len(found) != len(exp) {
+		t.Errorf("expected %d differences, got %d: %v", len(exp), len(found), found)
+	}
+	for k, v := range exp {
+		if found[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, found[k])
+		}
+	}
+
+	if it := NewDiffIterator(a, a.Copy()); it.Next() {
+		t.Errorf("expected no differences between equal tries, got key %q", it.Key)
+	}
+}
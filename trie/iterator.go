@@ -1,124 +1,251 @@
 package trie
 
-import (
-	"bytes"
-)
+import "bytes"
 
+// Iterator iterates the key/value pairs of a trie in key order. Keys which
+// are a prefix of other keys are visited after the keys they prefix.
 type Iterator struct {
 	trie *Trie
+	it   *NodeIterator
+	end  []byte
+
+	// pending is set if the node iterator is positioned at a leaf which has
+	// not been returned yet.
+	pending bool
 
 	Key   []byte
 	Value []byte
 }
 
 func NewIterator(trie *Trie) *Iterator {
-	return &Iterator{trie: trie, Key: nil}
+	return &Iterator{trie: trie, it: NewNodeIterator(trie)}
 }
 
-func (self *Iterator) Next() bool {
+// NewRangeIterator returns an iterator over the keys in [start, end). A nil
+// end iterates up to the last key of the trie.
+func NewRangeIterator(trie *Trie, start, end []byte) *Iterator {
+	it := NewIterator(trie)
+	it.end = end
+	it.Seek(start)
+
+	return it
+}
+
+// Seek moves the iterator such that the next call to Next returns the first
+// key which is greater than or equal to the given key.
+func (self *Iterator) Seek(key []byte) {
 	self.trie.mu.Lock()
 	defer self.trie.mu.Unlock()
 
-	isIterStart := false
-	if self.Key == nil {
-		isIterStart = true
-		self.Key = make([]byte, 32)
+	self.it = NewNodeIterator(self.trie)
+	self.pending = false
+	self.Key, self.Value = nil, nil
+
+	target := RemTerm(CompactHexDecode(string(key)))
+	for ok := self.it.next(true); ok; {
+		path := self.it.current().path
+		prefix := target
+		if len(prefix) > len(path) {
+			prefix = prefix[:len(path)]
+		}
+
+		switch cmp := bytes.Compare(path, prefix); {
+		case cmp < 0:
+			// All keys below this node are smaller than the target
+			ok = self.it.next(false)
+		case cmp == 0 && len(path) < len(target):
+			ok = self.it.next(true)
+		default:
+			// All keys below this node are greater or equal
+			if self.it.Leaf() {
+				self.pending = true
+			} else {
+				self.pending = self.it.next(true) && self.advance()
+			}
+			return
+		}
 	}
+}
 
-	key := RemTerm(CompactHexDecode(string(self.Key)))
-	k := self.next(self.trie.root, key, isIterStart)
+func (self *Iterator) Next() bool {
+	self.trie.mu.Lock()
+	defer self.trie.mu.Unlock()
 
-	self.Key = []byte(DecodeCompact(k))
+	if !self.pending {
+		if !self.it.next(true) || !self.advance() {
+			self.Key, self.Value = nil, nil
+			return false
+		}
+	}
+	self.pending = false
 
-	return len(k) > 0
+	key := self.it.LeafKey()
+	if self.end != nil && bytes.Compare(key, self.end) >= 0 {
+		self.Key, self.Value = nil, nil
+		return false
+	}
+	self.Key, self.Value = key, self.it.LeafValue()
+
+	return true
 }
 
-func (self *Iterator) next(node Node, key []byte, isIterStart bool) []byte {
-	if node == nil {
+// Position returns the key which will be returned by the next call to Next,
+// or nil if the iterator is exhausted. It can be passed to Seek or
+// NewRangeIterator to resume iteration later.
+func (self *Iterator) Position() []byte {
+	self.trie.mu.Lock()
+	defer self.trie.mu.Unlock()
+
+	if !self.pending {
+		if !self.it.next(true) || !self.advance() {
+			return nil
+		}
+		self.pending = true
+	}
+
+	key := self.it.LeafKey()
+	if self.end != nil && bytes.Compare(key, self.end) >= 0 {
 		return nil
 	}
+	return key
+}
 
-	switch node := node.(type) {
-	case *FullNode:
-		if len(key) > 0 {
-			k := self.next(node.branch(key[0]), key[1:], isIterStart)
-			if k != nil {
-				return append([]byte{key[0]}, k...)
-			}
+// advance moves the node iterator forward until it is positioned at a leaf,
+// starting with the current node.
+func (self *Iterator) advance() bool {
+	for len(self.it.stack) > 0 {
+		if self.it.Leaf() {
+			return true
 		}
-
-		var r byte
-		if len(key) > 0 {
-			r = key[0] + 1
+		if !self.it.next(true) {
+			return false
 		}
+	}
 
-		for i := r; i < 16; i++ {
-			k := self.key(node.branch(byte(i)))
-			if k != nil {
-				return append([]byte{i}, k...)
-			}
-		}
+	return false
+}
 
-	case *ShortNode:
-		k := RemTerm(node.Key())
-		if vnode, ok := node.Value().(*ValueNode); ok {
-			switch bytes.Compare([]byte(k), key) {
-			case 0:
-				if isIterStart {
-					self.Value = vnode.Val()
-					return k
-				}
-			case 1:
-				self.Value = vnode.Val()
-				return k
-			}
-		} else {
-			cnode := node.Value()
-
-			var ret []byte
-			skey := key[len(k):]
-			if BeginsWith(key, k) {
-				ret = self.next(cnode, skey, isIterStart)
-			} else if bytes.Compare(k, key[:len(k)]) > 0 {
-				return self.key(node)
-			}
+type nodeIteratorState struct {
+	hash  []byte // Hash of the node, nil if the node is embedded in its parent
+	node  Node
+	path  []byte // Hex encoded path to the node, leaves are terminated by 16
+	child int    // Index of the next child to visit
+}
 
-			if ret != nil {
-				return append(k, ret...)
-			}
-		}
-	}
+// NodeIterator walks all nodes of a trie in pre-order, both the ones stored
+// by hash and the ones embedded in their parent. Nodes are resolved without
+// being cached in their parents so that hash references are retained.
+type NodeIterator struct {
+	trie    *Trie
+	stack   []*nodeIteratorState
+	started bool
+
+	Hash []byte // Hash of the current node, nil if it is embedded in its parent
+	Node Node   // Current node
+	Path []byte // Hex encoded path of the current node
+}
 
-	return nil
+func NewNodeIterator(trie *Trie) *NodeIterator {
+	return &NodeIterator{trie: trie}
 }
 
-func (self *Iterator) key(node Node) []byte {
-	switch node := node.(type) {
-	case *ShortNode:
-		// Leaf node
-		if vnode, ok := node.Value().(*ValueNode); ok {
-			k := RemTerm(node.Key())
-			self.Value = vnode.Val()
+// Next moves the iterator to the next node, descending into the children of
+// the current one.
+func (self *NodeIterator) Next() bool {
+	return self.next(true)
+}
 
-			return k
-		} else {
-			k := RemTerm(node.Key())
-			return append(k, self.key(node.Value())...)
-		}
-	case *FullNode:
-		if node.Value() != nil {
-			self.Value = node.Value().(*ValueNode).Val()
+// Skip moves the iterator to the next node which is not a descendant of the
+// current one.
+func (self *NodeIterator) Skip() bool {
+	return self.next(false)
+}
+
+// Leaf reports whether the iterator is positioned at a value node.
+func (self *NodeIterator) Leaf() bool {
+	_, ok := self.Node.(*ValueNode)
+	return ok
+}
+
+// LeafKey returns the key of the current value node.
+func (self *NodeIterator) LeafKey() []byte {
+	return []byte(DecodeCompact(self.Path))
+}
+
+// LeafValue returns the value of the current value node.
+func (self *NodeIterator) LeafValue() []byte {
+	return self.Node.(*ValueNode).Val()
+}
+
+func (self *NodeIterator) current() *nodeIteratorState {
+	if len(self.stack) == 0 {
+		return nil
+	}
+	return self.stack[len(self.stack)-1]
+}
+
+func (self *NodeIterator) next(descend bool) bool {
+	ok := self.step(descend)
+	if ok {
+		state := self.current()
+		self.Hash, self.Node, self.Path = state.hash, state.node, state.path
+	} else {
+		self.Hash, self.Node, self.Path = nil, nil, nil
+	}
+
+	return ok
+}
 
-			return []byte{16}
+func (self *NodeIterator) step(descend bool) bool {
+	if !self.started {
+		self.started = true
+		if self.trie.root == nil {
+			return false
 		}
+		self.stack = append(self.stack, &nodeIteratorState{hash: self.trie.Hash(), node: self.trie.root})
+		return true
+	}
+	if !descend && len(self.stack) > 0 {
+		self.stack = self.stack[:len(self.stack)-1]
+	}
 
-		for i := 0; i < 16; i++ {
-			k := self.key(node.branch(byte(i)))
-			if k != nil {
-				return append([]byte{byte(i)}, k...)
+	for len(self.stack) > 0 {
+		parent := self.current()
+		switch node := parent.node.(type) {
+		case *FullNode:
+			for parent.child < len(node.nodes) {
+				i := parent.child
+				parent.child++
+				if node.nodes[i] != nil {
+					self.push(node.nodes[i], append(copyPath(parent.path), byte(i)))
+					return true
+				}
+			}
+		case *ShortNode:
+			if parent.child == 0 {
+				parent.child++
+				self.push(node.value, append(copyPath(parent.path), node.Key()...))
+				return true
 			}
 		}
+		self.stack = self.stack[:len(self.stack)-1]
+	}
+
+	return false
+}
+
+func (self *NodeIterator) push(node Node, path []byte) {
+	state := &nodeIteratorState{node: node, path: path}
+	if hash, ok := node.(*HashNode); ok {
+		state.hash = hash.key
+		state.node = self.trie.trans(node)
 	}
+	self.stack = append(self.stack, state)
+}
 
-	return nil
+// copyPath returns a copy of the path which can safely be appended to.
+func copyPath(path []byte) []byte {
+	cpy := make([]byte, len(path), len(path)+64)
+	copy(cpy, path)
+	return cpy
 }
@@ -1,6 +1,9 @@
 package trie
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestIterator(t *testing.T) {
 	trie := NewEmpty()
@@ -31,3 +34,69 @@ func TestIterator(t *testing.T) {
 		}
 	}
 }
+
+func TestIteratorSeek(t *testing.T) {
+	trie := NewEmpty()
+	for i := 0; i < 100; i++ {
+		trie.UpdateString(fmt.Sprintf("key%02d", i), fmt.Sprintf("value%d", i))
+	}
+	trie.Commit()
+
+	it := NewRangeIterator(trie, []byte("key10"), []byte("key20"))
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key))
+	}
+	if len(keys) != 10 || keys[0] != "key10" || keys[9] != "key19" {
+		t.Errorf("range mismatch: %v", keys)
+	}
+
+	// Seeking to a missing key starts at the following one
+	it = NewIterator(trie)
+	it.Seek([]byte("key5x"))
+	if !it.Next() || string(it.Key) != "key60" {
+		t.Errorf("expected key60 after seek, got %q", it.Key)
+	}
+
+	// Position reports the resume point without consuming it
+	if pos := it.Position(); string(pos) != "key61" {
+		t.Errorf("expected position key61, got %q", pos)
+	}
+	if !it.Next() || string(it.Key) != "key61" {
+		t.Errorf("expected key61, got %q", it.Key)
+	}
+
+	it.Seek([]byte("zzz"))
+	if it.Next() || it.Position() != nil {
+		t.Error("expected exhausted iterator after seeking past the last key")
+	}
+}
+
+func TestNodeIterator(t *testing.T) {
+	db := make(Db)
+	trie := New(nil, db)
+	for i := 0; i < 100; i++ {
+		trie.UpdateString(fmt.Sprintf("key%02d", i), fmt.Sprintf("value%d", i))
+	}
+	trie.Commit()
+	trie = New(trie.Root(), db)
+
+	hashes := make(map[string]bool)
+	leaves := 0
+	for it := NewNodeIterator(trie); it.Next(); {
+		if it.Hash != nil {
+			hashes[string(it.Hash)] = true
+		}
+		if it.Leaf() {
+			leaves++
+		}
+	}
+	if leaves != 100 {
+		t.Errorf("expected 100 leaves, got %d", leaves)
+	}
+	for key := range db {
+		if !hashes[key] {
+			t.Errorf("node %x not visited", key)
+		}
+	}
+}
@@ -1,20 +1,74 @@
 package trie
 
-import "github.com/ethereum/go-ethereum/crypto"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
 
 var keyPrefix = []byte("secure-key-")
 
+// preimageBackend is implemented by backends which record secure trie key
+// preimages in their preimage table.
+type preimageBackend interface {
+	Backend
+	StorePreimages() bool
+}
+
+// PreimageDatabase wraps a database and enables recording of secure trie key
+// preimages for every secure trie backed by it.
+type PreimageDatabase struct {
+	common.Database
+}
+
+func NewPreimageDatabase(db common.Database) *PreimageDatabase {
+	return &PreimageDatabase{db}
+}
+
+func (self *PreimageDatabase) StorePreimages() bool { return true }
+
+// PreimageTable is a view on a backend which maps the SHA3 hash of a secure
+// trie key back to the original key.
+type PreimageTable struct {
+	backend Backend
+}
+
+func NewPreimageTable(backend Backend) *PreimageTable {
+	return &PreimageTable{backend}
+}
+
+func (self *PreimageTable) Put(shaKey, key []byte) {
+	self.backend.Put(append(keyPrefix, shaKey...), key)
+}
+
+func (self *PreimageTable) Get(shaKey []byte) []byte {
+	key, _ := self.backend.Get(append(keyPrefix, shaKey...))
+	return key
+}
+
 type SecureTrie struct {
 	*Trie
+
+	keys      map[string][]byte // preimages of keys updated since the last commit
+	committed map[string][]byte // committed preimages not recorded by the backend
+	preimages *PreimageTable
+	store     bool // whether preimages are flushed to the table on commit
 }
 
 func NewSecure(root []byte, backend Backend) *SecureTrie {
-	return &SecureTrie{New(root, backend)}
+	trie := &SecureTrie{Trie: New(root, backend), keys: make(map[string][]byte), committed: make(map[string][]byte)}
+	if backend != nil {
+		trie.preimages = NewPreimageTable(backend)
+	}
+	if backend, ok := backend.(preimageBackend); ok {
+		trie.store = backend.StorePreimages()
+	}
+
+	return trie
 }
 
 func (self *SecureTrie) Update(key, value []byte) Node {
 	shaKey := crypto.Sha3(key)
-	self.Trie.cache.Put(append(keyPrefix, shaKey...), key)
+	self.keys[string(shaKey)] = key
 
 	return self.Trie.Update(shaKey, value)
 }
@@ -36,10 +90,54 @@ func (self *SecureTrie) DeleteString(key string) Node {
 	return self.Delete([]byte(key))
 }
 
+// Commit commits the trie and flushes the preimages of the keys updated since
+// the last commit to the preimage table. If the backend doesn't record
+// preimages they are kept in memory for the lifetime of the trie instead.
+func (self *SecureTrie) Commit() {
+	self.Trie.Commit()
+
+	for shaKey, key := range self.keys {
+		if self.store {
+			self.preimages.Put([]byte(shaKey), key)
+		} else {
+			self.committed[shaKey] = key
+		}
+	}
+	self.keys = make(map[string][]byte)
+}
+
+// Reset reverts the trie to its previous revision and drops the preimages
+// which haven't been committed yet.
+func (self *SecureTrie) Reset() {
+	self.Trie.Reset()
+	self.keys = make(map[string][]byte)
+}
+
 func (self *SecureTrie) Copy() *SecureTrie {
-	return &SecureTrie{self.Trie.Copy()}
+	cpy := &SecureTrie{Trie: self.Trie.Copy(), keys: make(map[string][]byte), committed: make(map[string][]byte), preimages: self.preimages, store: self.store}
+	for k, v := range self.keys {
+		cpy.keys[k] = v
+	}
+	for k, v := range self.committed {
+		cpy.committed[k] = v
+	}
+
+	return cpy
 }
 
+// GetKey returns the preimage of a hashed key. Keys written by this trie are
+// looked up in memory first, after which the preimage table of the database
+// is consulted.
 func (self *SecureTrie) GetKey(shaKey []byte) []byte {
-	return self.Trie.cache.Get(append(keyPrefix, shaKey...))
+	if key, ok := self.keys[string(shaKey)]; ok {
+		return key
+	}
+	if key, ok := self.committed[string(shaKey)]; ok {
+		return key
+	}
+	if self.preimages != nil {
+		return self.preimages.Get(shaKey)
+	}
+
+	return nil
 }
@@ -0,0 +1,75 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSecureGetKey(t *testing.T) {
+	trie := NewEmptySecure()
+	trie.UpdateString("foo", "bar")
+
+	key := []byte("foo")
+	if !bytes.Equal(trie.GetKey(crypto.Sha3(key)), key) {
+		t.Errorf("expected preimage %x, got %x", key, trie.GetKey(crypto.Sha3(key)))
+	}
+	if trie.Copy().GetKey(crypto.Sha3(key)) == nil {
+		t.Error("expected preimage to survive copy")
+	}
+}
+
+func TestSecurePreimageTable(t *testing.T) {
+	db := preimageDb{make(Db)}
+	trie := NewSecure(nil, db)
+	trie.UpdateString("foo", "bar")
+	trie.Commit()
+
+	// A fresh trie on the same database has no in-memory keys and
+	// must resolve the preimage through the database table.
+	trie = NewSecure(trie.Root(), db)
+	key := []byte("foo")
+	if !bytes.Equal(trie.GetKey(crypto.Sha3(key)), key) {
+		t.Errorf("expected preimage %x, got %x", key, trie.GetKey(crypto.Sha3(key)))
+	}
+}
+
+func TestSecurePreimagesDisabled(t *testing.T) {
+	db := make(Db)
+	trie := NewSecure(nil, db)
+	trie.UpdateString("foo", "bar")
+	trie.Commit()
+
+	// The preimage isn't stored but remains known to the trie itself.
+	key := []byte("foo")
+	if !bytes.Equal(trie.GetKey(crypto.Sha3(key)), key) {
+		t.Errorf("expected preimage %x after commit, got %x", key, trie.GetKey(crypto.Sha3(key)))
+	}
+	if !bytes.Equal(trie.Copy().GetKey(crypto.Sha3(key)), key) {
+		t.Error("expected committed preimage to survive copy")
+	}
+	if key := NewSecure(trie.Root(), db).GetKey(crypto.Sha3([]byte("foo"))); key != nil {
+		t.Errorf("expected no stored preimage, got %x", key)
+	}
+}
+
+func TestSecurePreimageRevert(t *testing.T) {
+	db := preimageDb{make(Db)}
+	trie := NewSecure(nil, db)
+	trie.UpdateString("foo", "bar")
+	trie.Commit()
+
+	trie.UpdateString("baz", "qux")
+	trie.Hash()
+	trie.Reset()
+	trie.Commit()
+
+	if key := NewSecure(trie.Root(), db).GetKey(crypto.Sha3([]byte("baz"))); key != nil {
+		t.Errorf("expected reverted preimage to be dropped, got %x", key)
+	}
+}
+
+type preimageDb struct{ Db }
+
+func (preimageDb) StorePreimages() bool { return true }
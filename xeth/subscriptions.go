@@ -0,0 +1,156 @@
+package xeth
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+var syncPollTime = time.Second
+
+// SyncStatus describes a running chain synchronisation.
+type SyncStatus struct {
+	CurrentBlock  uint64 // number of the current head
+	PendingHashes int    // hashes waiting for their blocks to be fetched
+	QueuedBlocks  int    // fetched blocks waiting to be imported
+}
+
+// subscription pushes events to a callback until it is unsubscribed. Event
+// based subscriptions end when the mux subscription is closed, polling ones
+// when quit is closed.
+type subscription struct {
+	events event.Subscription
+	quit   chan struct{}
+}
+
+func (self *subscription) stop() {
+	if self.events != nil {
+		self.events.Unsubscribe()
+	} else {
+		close(self.quit)
+	}
+}
+
+// SubscribeNewHeads calls fn with the subscription id and every new head
+// block of the chain.
+func (self *XEth) SubscribeNewHeads(fn func(int, *types.Block)) int {
+	return self.subscribeEvents(func(id int, ev interface{}) {
+		if ev, ok := ev.(core.ChainHeadEvent); ok {
+			fn(id, ev.Block)
+		}
+	}, core.ChainHeadEvent{})
+}
+
+// SubscribeLogs calls fn with the subscription id and the logs matching
+// address and topics, whether they were added to the chain or removed by a
+// reorg.
+func (self *XEth) SubscribeLogs(address []string, topics [][]string, fn func(int, state.Logs)) int {
+	filter := core.NewFilter(self.backend)
+	filter.SetAddress(cAddress(address))
+	filter.SetTopics(cTopics(topics))
+
+	return self.subscribeEvents(func(id int, ev interface{}) {
+		var logs state.Logs
+		switch ev := ev.(type) {
+		case state.Logs:
+			logs = ev
+		case core.RemovedLogsEvent:
+			logs = ev.Logs
+		}
+		if logs = filter.FilterLogs(logs); len(logs) > 0 {
+			fn(id, logs)
+		}
+	}, state.Logs(nil), core.RemovedLogsEvent{})
+}
+
+// SubscribePendingTransactions calls fn with the subscription id and the hash
+// of every transaction entering the pool.
+func (self *XEth) SubscribePendingTransactions(fn func(int, common.Hash)) int {
+	return self.subscribeEvents(func(id int, ev interface{}) {
+		if ev, ok := ev.(core.TxPreEvent); ok {
+			fn(id, ev.Tx.Hash())
+		}
+	}, core.TxPreEvent{})
+}
+
+// SubscribeSyncing calls fn with the subscription id whenever
+// synchronisation starts or stops, with the sync status or nil respectively.
+func (self *XEth) SubscribeSyncing(fn func(int, *SyncStatus)) int {
+	sub := &subscription{quit: make(chan struct{})}
+	id := self.addSubscription(sub)
+	go func() {
+		ticker := time.NewTicker(syncPollTime)
+		defer ticker.Stop()
+
+		var syncing bool
+		for {
+			select {
+			case <-ticker.C:
+				if status := self.SyncStatus(); (status != nil) != syncing {
+					syncing = status != nil
+					fn(id, status)
+				}
+			case <-sub.quit:
+				return
+			}
+		}
+	}()
+	return id
+}
+
+// SyncStatus returns the status of the running synchronisation, nil if the
+// node isn't synchronising.
+func (self *XEth) SyncStatus() *SyncStatus {
+	downloader := self.backend.Downloader()
+	if !downloader.Synchronising() {
+		return nil
+	}
+	pending, queued := downloader.Stats()
+	return &SyncStatus{
+		CurrentBlock:  self.backend.ChainManager().CurrentBlock().NumberU64(),
+		PendingHashes: pending,
+		QueuedBlocks:  queued,
+	}
+}
+
+// Unsubscribe stops the subscription with the given id. It returns false if
+// there is no such subscription.
+func (self *XEth) Unsubscribe(id int) bool {
+	self.subMu.Lock()
+	sub, ok := self.subs[id]
+	delete(self.subs, id)
+	self.subMu.Unlock()
+
+	if ok {
+		sub.stop()
+	}
+	return ok
+}
+
+// subscribeEvents registers the subscription before delivering any of its
+// events, so deliver always sees a valid id.
+func (self *XEth) subscribeEvents(deliver func(int, interface{}), types ...interface{}) int {
+	sub := &subscription{events: self.backend.EventMux().Subscribe(types...)}
+	id := self.addSubscription(sub)
+	go func() {
+		for ev := range sub.events.Chan() {
+			deliver(id, ev)
+		}
+	}()
+	return id
+}
+
+func (self *XEth) addSubscription(sub *subscription) int {
+	self.subMu.Lock()
+	defer self.subMu.Unlock()
+
+	id := self.subId
+	self.subs[id] = sub
+	self.subId++
+
+	return id
+}
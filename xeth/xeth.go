@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/cfg"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/event/filter"
@@ -63,6 +64,10 @@ type XEth struct {
 	messagesMu sync.RWMutex
 	messages   map[int]*whisperFilter
 
+	subMu sync.Mutex
+	subId int
+	subs  map[int]*subscription
+
 	// regmut   sync.Mutex
 	// register map[string][]*interface{} // TODO improve return type
 
@@ -85,6 +90,7 @@ func New(eth *eth.Ethereum, frontend Frontend) *XEth {
 		blockQueue:       make(map[int]*hashQueue),
 		transactionQueue: make(map[int]*hashQueue),
 		messages:         make(map[int]*whisperFilter),
+		subs:             make(map[int]*subscription),
 		agent:            miner.NewRemoteAgent(),
 	}
 	if eth.Whisper() != nil {
@@ -340,6 +346,111 @@ func (self *XEth) EthBlockByNumber(num int64) *types.Block {
 	return self.getBlockByHeight(num)
 }
 
+// StateDiff returns the accounts and storage slots which differ between the
+// states of the two given blocks.
+func (self *XEth) StateDiff(from, to *types.Block) state.WorldDiff {
+	db := self.backend.StateDb()
+	return state.New(from.Root(), db).RawDiff(state.New(to.Root(), db))
+}
+
+// StorageRangeAt returns a page of the storage of addr in the state of the
+// given block, see state.StateDB.StorageRange.
+func (self *XEth) StorageRangeAt(block *types.Block, addr string, start []byte, limit int) state.StorageRange {
+	statedb := state.New(block.Root(), self.backend.StateDb())
+	return statedb.StorageRange(common.HexToAddress(addr), start, limit)
+}
+
+// ProfileTransaction re-executes the mined transaction with the given hash and
+// profiles where it spent gas.
+func (self *XEth) ProfileTransaction(hash string) (*core.ProfileReport, error) {
+	tx, bhash, _, index := self.EthTransactionByHash(hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash)
+	}
+	block := self.backend.ChainManager().GetBlock(bhash)
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", bhash)
+	}
+	return self.backend.BlockProcessor().ProfileTransaction(block, int(index))
+}
+
+// ReplayBlock re-executes a block, given by hash or as hex encoded RLP, on
+// its parent state and reports where it diverges from the block's receipts.
+func (self *XEth) ReplayBlock(hashOrRlp string) (*core.ReplayReport, error) {
+	data := common.FromHex(hashOrRlp)
+
+	var block *types.Block
+	if len(data) == len(common.Hash{}) {
+		if block = self.backend.ChainManager().GetBlock(common.BytesToHash(data)); block == nil {
+			return nil, fmt.Errorf("block %x not found", data)
+		}
+	} else {
+		block = new(types.Block)
+		if err := rlp.DecodeBytes(data, block); err != nil {
+			return nil, fmt.Errorf("invalid block RLP: %v", err)
+		}
+	}
+	return self.backend.BlockProcessor().ReplayBlock(block)
+}
+
+// StorageStats returns the storage writes of the blocks processed since the
+// node started.
+func (self *XEth) StorageStats() core.StorageStats {
+	return self.backend.BlockProcessor().StorageStats()
+}
+
+// BadBlocks returns the blocks recently rejected by the chain manager.
+func (self *XEth) BadBlocks() []*core.BadBlock {
+	return self.backend.ChainManager().BadBlocks()
+}
+
+// TransactionsByAddress returns at most limit of the canonical transactions
+// sending from or to addr, most recent first after skipping offset of them,
+// and the number of all of them. It fails if the address index is disabled.
+func (self *XEth) TransactionsByAddress(addr string, offset, limit uint64) (types.Transactions, []*types.Block, uint64, error) {
+	index := self.backend.ChainManager().AddressIndex()
+	if index == nil {
+		return nil, nil, 0, fmt.Errorf("address index disabled (start with --addrindex)")
+	}
+	address := common.HexToAddress(addr)
+
+	var (
+		txs    types.Transactions
+		blocks []*types.Block
+	)
+	for _, entry := range index.Transactions(address, offset, limit) {
+		block := self.backend.ChainManager().GetBlockByNumber(entry.Number)
+		if block == nil || entry.Index >= uint64(len(block.Transactions())) {
+			continue
+		}
+		txs = append(txs, block.Transactions()[entry.Index])
+		blocks = append(blocks, block)
+	}
+	return txs, blocks, index.Count(address), nil
+}
+
+// ChainForks returns the tree of known blocks from depth blocks below the
+// current head upwards.
+func (self *XEth) ChainForks(depth uint64) []*core.ForkBlock {
+	return self.backend.ChainManager().ForkTree(depth)
+}
+
+// BlocksAt returns all known blocks at the given height, -1 denoting the
+// current one.
+func (self *XEth) BlocksAt(num int64) []*core.ForkBlock {
+	chain := self.backend.ChainManager()
+	if num < 0 {
+		num = int64(chain.CurrentBlock().NumberU64())
+	}
+	return chain.BlocksAt(uint64(num))
+}
+
+// CommonAncestor returns the most recent common ancestor of the given
+// blocks, nil if it can't be determined.
+func (self *XEth) CommonAncestor(a, b string) *types.Block {
+	return self.backend.ChainManager().CommonAncestor(common.HexToHash(a), common.HexToHash(b))
+}
+
 func (self *XEth) CurrentBlock() *types.Block {
 	return self.backend.ChainManager().CurrentBlock()
 }
@@ -472,6 +583,11 @@ func (self *XEth) CodeAtBytes(address string) []byte {
 	return self.State().SafeGet(address).Code()
 }
 
+// ContractCFG returns the control flow graph of the code at address.
+func (self *XEth) ContractCFG(address string) *cfg.Graph {
+	return cfg.New(self.State().state.GetCode(common.HexToAddress(address)))
+}
+
 func (self *XEth) IsContract(address string) bool {
 	return len(self.State().SafeGet(address).Code()) > 0
 }
@@ -775,6 +891,12 @@ func (self *XEth) PushTx(encodedTx string) (string, error) {
 
 func (self *XEth) Call(fromStr, toStr, valueStr, gasStr, gasPriceStr, dataStr string) (string, error) {
 	statedb := self.State().State() //self.eth.ChainManager().TransState()
+
+	// Calls must not leave any changes behind in the state, not even the
+	// sender account if it didn't exist
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
 	var from *state.StateObject
 	if len(fromStr) == 0 {
 		accounts, err := self.backend.AccountManager().Accounts()